@@ -18,8 +18,8 @@ const usage = `ide — terminal UI for managing tmux-based dev environments
 
 Usage:
   ide              Launch the main TUI
-  ide --search     Open the fuzzy-search popup (used by tmux prefix+s)
-  ide --windows    Open the current-session window switcher (used by tmux prefix+w)
+  ide --search     Open the fuzzy-search popup (tmux prefix+a, see ide tmux install)
+  ide --windows    Open the current-session window switcher (tmux prefix+w)
   ide --help       Show this message
   ide --version    Print the version
 
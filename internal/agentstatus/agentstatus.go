@@ -16,6 +16,11 @@ const (
 	StatusIdle          Status = "idle"
 	StatusCooking       Status = "cooking"
 	StatusAwaitingInput Status = "awaiting_input"
+
+	// StatusAwaitingApproval means the agent is blocked on a permission
+	// prompt. CPU activity cannot tell; output detection and the agent's own
+	// report can.
+	StatusAwaitingApproval Status = "awaiting_approval"
 )
 
 // ProcessInfo is a snapshot of process metrics used to drive status detection.
@@ -23,6 +28,8 @@ type ProcessInfo struct {
 	PID       int
 	CPU       float64
 	State     string // ps state code: R, S, D, T, Z, etc.
+	RSS       int64  // resident bytes of the pane's process tree
+	Threads   int    // threads in the tree; 0 when unknown
 	Timestamp time.Time
 }
 
@@ -32,15 +39,22 @@ type WindowInfo struct {
 	Current          ProcessInfo
 	Previous         ProcessInfo
 	Status           Status
-	LowActivityCount int     // consecutive samples below the cooking threshold
-	BaselineCPU      float64 // rolling baseline CPU when in awaiting_input
-	SampleCount      int     // samples accumulated for the baseline
-	Command          string  // most recently observed pane_current_command
+	LowActivityCount int       // consecutive samples below the cooking threshold
+	BaselineCPU      float64   // rolling baseline CPU when in awaiting_input
+	SampleCount      int       // samples accumulated for the baseline
+	Command          string    // most recently observed pane_current_command
+	Report           Report    // last self-report seen, shown while Active
+	Tool             string    // registry name of the agent, "" when unknown
+	Prompt           []string  // ApprovalPrompt while awaiting approval
+	PromptShown      bool      // Prompt matched an approval pattern, not only a self-report
+	ScreenHash       uint64    // ScreenHash of the output while cooking
+	ScreenSince      time.Time // when ScreenHash last changed, or cooking began
 }
 
-// KnownTools is the set of process names recognised as AI-agent CLIs.
-// Lookup is case-insensitive; values are lowercase and stripped of any path
-// or argument noise before comparison.
+// KnownTools is the built-in set of process names recognised as AI-agent
+// CLIs, the starting point of every Registry. Lookup is case-insensitive;
+// values are lowercase and stripped of any path or argument noise before
+// comparison.
 var KnownTools = map[string]struct{}{
 	"claude":       {}, // Anthropic Claude Code (npm @anthropic-ai/claude-code)
 	"opencode":     {}, // sst/opencode terminal AI agent
@@ -67,13 +81,9 @@ var KnownTools = map[string]struct{}{
 	"auggie":       {}, // Augment Code CLI
 }
 
-// IsAITool reports whether name is a known AI-agent CLI. Accepts paths and
-// argv with arguments — only the last path component up to the first space
-// is checked, case-insensitively.
-func IsAITool(name string) bool {
-	if name == "" {
-		return false
-	}
+// ToolName normalises a process name or command line to the command name
+// Lookup compares: lowercase, last path component, arguments dropped.
+func ToolName(name string) string {
 	name = strings.ToLower(strings.TrimSpace(name))
 	if i := strings.IndexAny(name, " \t"); i >= 0 {
 		name = name[:i]
@@ -81,8 +91,7 @@ func IsAITool(name string) bool {
 	if i := strings.LastIndex(name, "/"); i >= 0 {
 		name = name[i+1:]
 	}
-	_, ok := KnownTools[name]
-	return ok
+	return name
 }
 
 // Key formats the canonical "session:window" tracking key.
@@ -103,29 +112,35 @@ func Key(session, window string) string {
 //   - First 10 samples for a new window are baseline-only (stay Awaiting)
 //
 // Cooking threshold = max(baseline+5, baseline*1.3), with baseline floored at 1.0.
+// Detect uses DefaultThresholds; DetectWith takes a tool's own.
 func Detect(current ProcessInfo, currentStatus Status, lowActivityCount int, baselineCPU float64, sampleCount int) (Status, int, float64, int) {
+	return DetectWith(DefaultThresholds, current, currentStatus, lowActivityCount, baselineCPU, sampleCount)
+}
+
+// DetectWith is Detect with the 5, 1.3, 3 and 10 above taken from th.
+func DetectWith(th Thresholds, current ProcessInfo, currentStatus Status, lowActivityCount int, baselineCPU float64, sampleCount int) (Status, int, float64, int) {
 	effectiveBaseline := baselineCPU
 	if effectiveBaseline < 1.0 {
 		effectiveBaseline = 1.0
 	}
-	cookingThreshold := effectiveBaseline + 5.0
-	if effectiveBaseline*1.3 > cookingThreshold {
-		cookingThreshold = effectiveBaseline * 1.3
+	cookingThreshold := effectiveBaseline + th.MinDelta
+	if effectiveBaseline*th.Ratio > cookingThreshold {
+		cookingThreshold = effectiveBaseline * th.Ratio
 	}
 	isHighActivity := current.CPU > cookingThreshold
 
 	switch currentStatus {
-	case StatusCooking:
+	case StatusCooking, StatusStalled:
 		if isHighActivity {
 			return StatusCooking, 0, baselineCPU, sampleCount
 		}
 		newCount := lowActivityCount + 1
-		if newCount >= 3 {
+		if newCount >= th.LowSamples {
 			return StatusAwaitingInput, 0, baselineCPU, sampleCount
 		}
 		return StatusCooking, newCount, baselineCPU, sampleCount
 
-	case StatusAwaitingInput, StatusIdle:
+	case StatusAwaitingInput, StatusAwaitingApproval, StatusError, StatusIdle:
 		if isHighActivity {
 			return StatusCooking, 0, baselineCPU, sampleCount
 		}
@@ -145,7 +160,7 @@ func Detect(current ProcessInfo, currentStatus Status, lowActivityCount int, bas
 
 	default:
 		// New window: collect 10 baseline samples before allowing cooking.
-		if sampleCount < 10 {
+		if sampleCount < th.Warmup {
 			newBaseline := current.CPU
 			newSampleCount := sampleCount + 1
 			if sampleCount > 0 {
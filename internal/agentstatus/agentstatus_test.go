@@ -2,6 +2,9 @@ package agentstatus
 
 import "testing"
 
+// builtin is the registry of the tests that do not configure one.
+var builtin = BuiltinRegistry()
+
 func TestIsAITool(t *testing.T) {
 	tests := []struct {
 		name string
@@ -28,7 +31,7 @@ func TestIsAITool(t *testing.T) {
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got := IsAITool(tc.in)
+			got := builtin.IsAITool(tc.in)
 			if got != tc.want {
 				t.Errorf("IsAITool(%q) = %v, want %v", tc.in, got, tc.want)
 			}
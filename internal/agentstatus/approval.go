@@ -0,0 +1,60 @@
+package agentstatus
+
+import "strings"
+
+// ApprovalPrompt returns up to n lines of the approval prompt on screen,
+// ending with the first line that asks (the options below it add nothing).
+// Without a recognisable prompt, e.g. when the approval was self-reported,
+// it is the last n lines of the screen.
+func (r *Registry) ApprovalPrompt(tool, output string, n int) []string {
+	lines := strings.Split(outputTail(output, outputTailLines), "\n")
+	if n <= 0 || len(lines) == 1 && lines[0] == "" {
+		return nil
+	}
+	packs := []PatternPack{GenericPack}
+	if pack, ok := r.pack(tool); ok {
+		packs = []PatternPack{pack, GenericPack}
+	}
+	end := len(lines)
+find:
+	for i, line := range lines {
+		for _, pack := range packs {
+			for _, pat := range pack.Approval {
+				if pat.MatchString(line) {
+					end = i + 1
+					break find
+				}
+			}
+		}
+	}
+	start := max(end-n, 0)
+	out := make([]string, 0, end-start)
+	for _, line := range lines[start:end] {
+		out = append(out, strings.TrimSpace(line))
+	}
+	return out
+}
+
+// ShowsApproval reports whether output has one of tool's approval prompts
+// on screen. Only then may Replies be pressed: a self-reported approval, or
+// one answered since the last sample, leaves keys to land in whatever the
+// agent shows instead.
+func (r *Registry) ShowsApproval(tool, output string) bool {
+	status, ok := r.MatchOutput(tool, output)
+	return ok && status == StatusAwaitingApproval
+}
+
+// Replies returns the keys that approve and deny tool's approval prompt:
+// its pattern pack's, else GenericPack's.
+func (r *Registry) Replies(tool string) (approve, deny []string) {
+	approve, deny = GenericPack.Approve, GenericPack.Deny
+	if pack, ok := r.pack(tool); ok {
+		if pack.Approve != nil {
+			approve = pack.Approve
+		}
+		if pack.Deny != nil {
+			deny = pack.Deny
+		}
+	}
+	return approve, deny
+}
@@ -0,0 +1,118 @@
+package agentstatus
+
+import (
+	"strings"
+)
+
+// Sample is one observation of an agent window, fed to a Detector.
+type Sample struct {
+	Process ProcessInfo
+	Tool    string // registry name of the agent (see Registry.Resolve), "" when unknown
+	Output  string // visible pane text, ANSI-stripped; "" when not captured
+	Report  Report // the agent's last self-report; zero when there is none
+}
+
+// Detector infers an agent window's status from a sample. It may read and
+// update the tracking counters in w; the caller stores w back and sets
+// w.Status to the returned status. ok is false when the detector has no
+// opinion, so the next detector in a Chain is asked.
+type Detector interface {
+	Detect(s Sample, w *WindowInfo) (status Status, ok bool)
+}
+
+// Chain returns the first opinion of its detectors, in order. Every
+// detector still sees every sample, so one that keeps state (a CPUDetector's
+// baseline and warm-up) stays current while an earlier one decides.
+type Chain []Detector
+
+func (c Chain) Detect(s Sample, w *WindowInfo) (Status, bool) {
+	status, found := StatusIdle, false
+	for _, d := range c {
+		if st, ok := d.Detect(s, w); ok && !found {
+			status, found = st, true
+		}
+	}
+	return status, found
+}
+
+// CPUDetector is the CPU/baseline heuristic of Detect, with the sampled
+// tool's thresholds in Registry. It always has an opinion, so it belongs at
+// the end of a Chain.
+type CPUDetector struct {
+	Registry *Registry
+}
+
+func (d CPUDetector) Detect(s Sample, w *WindowInfo) (Status, bool) {
+	status, low, baseline, samples := DetectWith(d.Registry.thresholds(s.Tool), s.Process, w.Status, w.LowActivityCount, w.BaselineCPU, w.SampleCount)
+	w.LowActivityCount, w.BaselineCPU, w.SampleCount = low, baseline, samples
+	return status, true
+}
+
+// OutputDetector matches the tail of the pane output against the tool's
+// pattern pack (its registry Pack, falling back to GenericPack). It has no opinion when
+// nothing matches or no output was captured.
+type OutputDetector struct {
+	Registry *Registry
+}
+
+func (d OutputDetector) Detect(s Sample, w *WindowInfo) (Status, bool) {
+	return d.Registry.MatchOutput(s.Tool, s.Output)
+}
+
+// DefaultDetector trusts an active self-report, then reads the screen, and
+// falls back to CPU activity for agents (or moments) the patterns do not
+// cover. Cooking that leaves the screen untouched for too long is stalled.
+func DefaultDetector(r *Registry) Detector {
+	return StallDetector{Chain{ReportDetector{}, OutputDetector{r}, CPUDetector{r}}, r}
+}
+
+// outputTailLines is how much of the bottom of the screen MatchOutput
+// looks at: agents draw their status line and prompts there, and older
+// lines higher up may still show a prompt that was already answered.
+const outputTailLines = 15
+
+// MatchOutput reports the status the pane output shows for tool, if any.
+// Approval prompts win over working indicators, which win over an idle
+// input prompt.
+func (r *Registry) MatchOutput(tool, output string) (Status, bool) {
+	tail := outputTail(output, outputTailLines)
+	if tail == "" {
+		return StatusIdle, false
+	}
+	packs := []PatternPack{GenericPack}
+	if pack, ok := r.pack(tool); ok {
+		packs = []PatternPack{pack, GenericPack}
+	}
+	for _, check := range []struct {
+		status Status
+		list   func(PatternPack) []Pattern
+	}{
+		{StatusAwaitingApproval, func(p PatternPack) []Pattern { return p.Approval }},
+		{StatusCooking, func(p PatternPack) []Pattern { return p.Cooking }},
+		{StatusAwaitingInput, func(p PatternPack) []Pattern { return p.Input }},
+	} {
+		for _, pack := range packs {
+			for _, pat := range check.list(pack) {
+				if pat.MatchString(tail) {
+					return check.status, true
+				}
+			}
+		}
+	}
+	return StatusIdle, false
+}
+
+// outputTail returns the last n non-blank lines of output.
+func outputTail(output string, n int) string {
+	lines := strings.Split(strings.TrimRight(output, " \n\t"), "\n")
+	kept := make([]string, 0, n)
+	for i := len(lines) - 1; i >= 0 && len(kept) < n; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			kept = append(kept, lines[i])
+		}
+	}
+	for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+		kept[i], kept[j] = kept[j], kept[i]
+	}
+	return strings.Join(kept, "\n")
+}
@@ -0,0 +1,177 @@
+package agentstatus
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		tool   string
+		output string
+		want   Status
+		wantOK bool
+	}{
+		{"claude working", "claude", "> fix the tests\n\n✻ Cooking… (12s · ↑ 1.2k tokens · esc to interrupt)\n", StatusCooking, true},
+		{"claude approval", "claude", "Bash command\n  rm -rf build\nDo you want to proceed?\n❯ 1. Yes\n  2. No\n", StatusAwaitingApproval, true},
+		{"claude prompt", "claude", "╭────╮\n│ >  │\n╰────╯\n  ? for shortcuts\n", StatusAwaitingInput, true},
+		{"approval beats a spinner", "codex", "⠋ Working (3s • esc to interrupt)\nAllow command?\n", StatusAwaitingApproval, true},
+		{"generic spinner for unknown tool", "", "⠙ thinking\n", StatusCooking, true},
+		{"generic y/n for a tool without a pack", "llm", "Run it? [y/N]\n", StatusAwaitingApproval, true},
+		{"nothing recognisable", "claude", "$ ls\nfoo bar\n", StatusIdle, false},
+		{"no output", "claude", "", StatusIdle, false},
+		{"stale prompt scrolled out of the tail", "claude", "Do you want to proceed?\n" + strings.Repeat("line\n", outputTailLines), StatusIdle, false},
+	}
+	for _, tc := range tests {
+		got, ok := builtin.MatchOutput(tc.tool, tc.output)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("%s: MatchOutput = (%s, %v), want (%s, %v)", tc.name, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestDefaultDetectorFallsBackToCPU(t *testing.T) {
+	w := WindowInfo{Status: StatusAwaitingApproval, SampleCount: 20, BaselineCPU: 2}
+	status, ok := DefaultDetector(builtin).Detect(Sample{Tool: "claude", Process: ProcessInfo{CPU: 40}, Output: "$ \n"}, &w)
+	if !ok || status != StatusCooking {
+		t.Errorf("busy agent without a screen match = (%s, %v), want cooking", status, ok)
+	}
+	w = WindowInfo{Status: StatusCooking, SampleCount: 20, BaselineCPU: 2}
+	status, _ = DefaultDetector(builtin).Detect(Sample{Tool: "claude", Process: ProcessInfo{CPU: 40}, Output: "Do you want to proceed?"}, &w)
+	if status != StatusAwaitingApproval {
+		t.Errorf("approval prompt = %s, want it to win over CPU", status)
+	}
+}
+
+func TestChainFeedsEveryDetector(t *testing.T) {
+	var w WindowInfo
+	for i := 0; i < 3; i++ {
+		s := Sample{Tool: "claude", Process: ProcessInfo{CPU: 2}, Output: "> \n? for shortcuts\n"}
+		status, ok := Chain{OutputDetector{builtin}, CPUDetector{builtin}}.Detect(s, &w)
+		if !ok || status != StatusAwaitingInput {
+			t.Fatalf("sample %d = (%s, %v), want the screen's awaiting_input", i, status, ok)
+		}
+		w.Status = status
+	}
+	if w.SampleCount != 3 || w.BaselineCPU != 2 {
+		t.Errorf("CPU baseline = %.1f over %d samples, want 2.0 over 3 while the screen decided", w.BaselineCPU, w.SampleCount)
+	}
+}
+
+func TestStallDetector(t *testing.T) {
+	start := time.Now()
+	screen := func(spinner, body string) string {
+		return "> fix the flaky test\n" + body + "\n✻ " + spinner + "… (" + spinner + " · esc to interrupt)\n"
+	}
+	steps := []struct {
+		after  time.Duration
+		output string
+		want   Status
+	}{
+		{0, screen("Cooking", "Reading main.go"), StatusCooking},
+		{5 * time.Minute, screen("Brewing", "Reading main.go"), StatusCooking},
+		// Only the spinner line moved for ten minutes.
+		{10 * time.Minute, screen("Pondering", "Reading main.go"), StatusStalled},
+		{11 * time.Minute, screen("Cooking", "Reading main.go"), StatusStalled},
+		// New output: back to cooking, and the clock starts over.
+		{12 * time.Minute, screen("Cooking", "Editing main.go"), StatusCooking},
+		{21 * time.Minute, screen("Cooking", "Editing main.go"), StatusCooking},
+		{22 * time.Minute, screen("Cooking", "Editing main.go"), StatusStalled},
+		{23 * time.Minute, "> \n? for shortcuts\n", StatusAwaitingInput},
+	}
+	var w WindowInfo
+	for _, step := range steps {
+		s := Sample{Tool: "claude", Process: ProcessInfo{Timestamp: start.Add(step.after)}, Output: step.output}
+		status, _ := DefaultDetector(builtin).Detect(s, &w)
+		if status != step.want {
+			t.Errorf("after %s: status = %s, want %s", step.after, status, step.want)
+		}
+		w.Status = status
+	}
+}
+
+func TestPacksCoverKnownTools(t *testing.T) {
+	for tool := range Packs {
+		if _, ok := KnownTools[tool]; !ok {
+			t.Errorf("pattern pack for %q, which is not in KnownTools", tool)
+		}
+	}
+}
+
+func TestReportOverridesDetection(t *testing.T) {
+	now := time.Now()
+	report := Report{Status: StatusAwaitingInput, Message: "done", At: now.Add(-time.Minute), Expires: now.Add(time.Minute)}
+	busy := Sample{Tool: "claude", Process: ProcessInfo{CPU: 90, Timestamp: now}, Output: "esc to interrupt", Report: report}
+
+	w := WindowInfo{Status: StatusCooking, SampleCount: 20, BaselineCPU: 2}
+	if status, _ := DefaultDetector(builtin).Detect(busy, &w); status != StatusAwaitingInput {
+		t.Errorf("active report: status = %s, want the reported %s", status, StatusAwaitingInput)
+	}
+	busy.Process.Timestamp = now.Add(2 * time.Minute)
+	if status, _ := DefaultDetector(builtin).Detect(busy, &w); status != StatusCooking {
+		t.Errorf("expired report: status = %s, want detection to take over", status)
+	}
+}
+
+func TestParseReportStatus(t *testing.T) {
+	tests := map[string]Status{
+		"cooking": StatusCooking, "Awaiting": StatusAwaitingInput, "done": StatusAwaitingInput,
+		"approval": StatusAwaitingApproval, "error": StatusError,
+	}
+	for in, want := range tests {
+		if got, err := ParseReportStatus(in); err != nil || got != want {
+			t.Errorf("ParseReportStatus(%q) = %s, %v; want %s", in, got, err, want)
+		}
+	}
+	if _, err := ParseReportStatus("busy"); err == nil {
+		t.Error("ParseReportStatus accepted an unknown status")
+	}
+}
+
+func TestApprovalPrompt(t *testing.T) {
+	tests := []struct {
+		name   string
+		tool   string
+		output string
+		want   []string
+	}{
+		{
+			"claude stops at the question",
+			"claude",
+			"Bash command\n\n  rm -rf build\n  Remove the build directory\n\nDo you want to proceed?\n❯ 1. Yes\n  2. No, and tell Claude what to do differently (esc)\n",
+			[]string{"rm -rf build", "Remove the build directory", "Do you want to proceed?"},
+		},
+		{"generic y/n", "llm", "Delete 3 files? [y/n]\n", []string{"Delete 3 files? [y/n]"}},
+		{"no prompt on screen", "claude", "one\ntwo\nthree\nfour\n", []string{"two", "three", "four"}},
+		{"empty screen", "claude", "", nil},
+	}
+	for _, tc := range tests {
+		if got := builtin.ApprovalPrompt(tc.tool, tc.output, 3); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: ApprovalPrompt = %q, want %q", tc.name, got, tc.want)
+		}
+		if got, want := builtin.ShowsApproval(tc.tool, tc.output), tc.name != "no prompt on screen" && tc.want != nil; got != want {
+			t.Errorf("%s: ShowsApproval = %v, want %v", tc.name, got, want)
+		}
+	}
+}
+
+func TestReplies(t *testing.T) {
+	tests := []struct {
+		tool          string
+		approve, deny []string
+	}{
+		{"claude", []string{"Enter"}, []string{"Escape"}},
+		{"aider", []string{"y", "Enter"}, []string{"n", "Enter"}},
+		{"codex", []string{"y"}, []string{"Escape"}},
+		{"unknown", []string{"Enter"}, []string{"Escape"}},
+	}
+	for _, tc := range tests {
+		approve, deny := builtin.Replies(tc.tool)
+		if !slices.Equal(approve, tc.approve) || !slices.Equal(deny, tc.deny) {
+			t.Errorf("Replies(%s) = %q, %q; want %q, %q", tc.tool, approve, deny, tc.approve, tc.deny)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package agentstatus
+
+import (
+	"strings"
+	"time"
+)
+
+// InboxEntry is what an agent left on screen when it finished cooking and
+// started waiting for input: usually a summary of what it did.
+type InboxEntry struct {
+	Session string    `json:"session"`
+	Window  string    `json:"window"`
+	At      time.Time `json:"at"`
+	Lines   []string  `json:"lines"`
+}
+
+// Key is the entry's "session:window" tracking key.
+func (e InboxEntry) Key() string { return Key(e.Session, e.Window) }
+
+// InboxLines is how much of the screen an inbox entry keeps.
+const InboxLines = 20
+
+// chrome is what rules, box borders and bare prompts are drawn with; a line
+// of nothing else carries no content.
+const chrome = " \t│┃|─━═-_╭╮╰╯┌┐└┘├┤┬┴┼▌▐█░▒▓>❯›$%#·•*"
+
+// Summary returns the last n meaningful lines of output, oldest first:
+// blank lines, rules, box borders, bare prompts and the lines matching
+// tool's idle-prompt patterns (input hints, status bars) are dropped.
+func (r *Registry) Summary(tool, output string, n int) []string {
+	var input []Pattern
+	if pack, ok := r.pack(tool); ok {
+		input = pack.Input
+	}
+	lines := strings.Split(output, "\n")
+	out := make([]string, 0, n)
+lines:
+	for i := len(lines) - 1; i >= 0 && len(out) < n; i-- {
+		line := strings.TrimRight(lines[i], " \t")
+		if strings.Trim(line, chrome) == "" {
+			continue
+		}
+		for _, pat := range input {
+			if pat.MatchString(line) {
+				continue lines
+			}
+		}
+		out = append(out, line)
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
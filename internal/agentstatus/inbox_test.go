@@ -0,0 +1,38 @@
+package agentstatus
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	screen := "⏺ Fixed the flaky test.\n" +
+		"\n" +
+		"  - main_test.go: wait for the server before dialing\n" +
+		"  - server.go: close the listener on shutdown   \n" +
+		"\n" +
+		"╭──────────────────────────────╮\n" +
+		"│ >                            │\n" +
+		"╰──────────────────────────────╯\n" +
+		"  ? for shortcuts\n"
+	tests := []struct {
+		name string
+		n    int
+		want []string
+	}{
+		{"all", 20, []string{
+			"⏺ Fixed the flaky test.",
+			"  - main_test.go: wait for the server before dialing",
+			"  - server.go: close the listener on shutdown",
+		}},
+		{"last lines", 1, []string{"  - server.go: close the listener on shutdown"}},
+	}
+	for _, tc := range tests {
+		if got := builtin.Summary("claude", screen, tc.n); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: Summary = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+	if got := builtin.Summary("claude", "\n\n$ \n", 20); len(got) != 0 {
+		t.Errorf("Summary of an empty screen = %q", got)
+	}
+}
@@ -0,0 +1,144 @@
+package agentstatus
+
+import "regexp"
+
+// Pattern is one screen pattern of a PatternPack.
+type Pattern = *regexp.Regexp
+
+// PatternPack is what an agent's screen looks like in each state. Patterns
+// are matched against the bottom lines of the pane (see MatchOutput).
+type PatternPack struct {
+	Approval []Pattern // asking permission for a tool call, edit or command
+	Cooking  []Pattern // working: spinners, "esc to interrupt" hints
+	Input    []Pattern // idle at its prompt
+
+	// Approve and Deny are the tmux keys (see send-keys) that answer an
+	// approval prompt; nil keeps GenericPack's.
+	Approve []string
+	Deny    []string
+}
+
+func patterns(exprs ...string) []Pattern {
+	out := make([]Pattern, len(exprs))
+	for i, e := range exprs {
+		out[i] = regexp.MustCompile(e)
+	}
+	return out
+}
+
+// GenericPack holds the conventions most agent TUIs share. It is checked
+// after the tool's own pack, and alone for tools without one.
+var GenericPack = PatternPack{
+	Approval: patterns(
+		`(?i)do you want to (proceed|continue|allow|run|make)`,
+		`(?i)\[y/n\]|\(y/n\)|\(y\)es/\(n\)o`,
+	),
+	Cooking: patterns(
+		`(?i)esc(ape)? to (interrupt|cancel|stop)`,
+		`(?i)ctrl\+c to (interrupt|cancel|stop)`,
+		`[⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏]`, // braille spinner frames
+	),
+	// Most agents ask with a menu whose first, highlighted option allows
+	// the call once and which Esc dismisses.
+	Approve: []string{"Enter"},
+	Deny:    []string{"Escape"},
+}
+
+// Packs are the bundled pattern packs, keyed by tool name; a configured
+// tool can borrow one with "pack". Tools in KnownTools without an entry (one-shot CLIs such as llm or sgpt) rely on
+// GenericPack and the CPU heuristic.
+var Packs = map[string]PatternPack{
+	"claude": {
+		Approval: patterns(
+			`Do you want to (proceed|make this edit|create|allow)`,
+			`❯ 1\. Yes`,
+		),
+		Cooking: patterns(
+			`esc to interrupt`,
+			`[✻✶✳✢✽·] \w+…`, // "✻ Cooking… (12s · ↑ 1.2k tokens)"
+		),
+		Input: patterns(`\? for shortcuts`),
+	},
+	"codex": {
+		Approval: patterns(
+			`(?i)allow command\?`,
+			`(?i)would you like to (run|make|apply)`,
+		),
+		Cooking: patterns(`(?i)working \(\d+s • esc to interrupt\)`, `Esc to interrupt`),
+		Input:   patterns(`(?i)⏎ send`, `(?i)ctrl\+j newline`),
+		Approve: []string{"y"},
+	},
+	"gemini": {
+		Approval: patterns(`(?i)allow execution\?`, `(?i)apply this change\?`, `(?i)waiting for user confirmation`),
+		Cooking:  patterns(`\(esc to cancel`),
+		Input:    patterns(`(?i)type your message`),
+	},
+	"qwen": {
+		Approval: patterns(`(?i)allow execution\?`, `(?i)apply this change\?`, `(?i)waiting for user confirmation`),
+		Cooking:  patterns(`\(esc to cancel`),
+		Input:    patterns(`(?i)type your message`),
+	},
+	"opencode": {
+		Approval: patterns(`(?i)permission required`, `(?i)allow (once|always)`),
+		Cooking:  patterns(`(?i)esc interrupt`, `(?i)working\.\.\.`),
+		Input:    patterns(`(?i)ctrl\+p commands`),
+	},
+	"crush": {
+		Approval: patterns(`(?i)permission required`, `(?i)allow for session`),
+		Cooking:  patterns(`(?i)esc to cancel`, `(?i)thinking\.\.\.`),
+		Approve:  []string{"a"},
+		Deny:     []string{"d"},
+	},
+	"aider": {
+		Approval: patterns(`\(Y\)es/\(N\)o`),
+		Cooking:  patterns(`(?i)waiting for \S+`),
+		Input:    patterns(`(?m)^(\w+ )?> ?$`),
+		Approve:  []string{"y", "Enter"},
+		Deny:     []string{"n", "Enter"},
+	},
+	"copilot": {
+		Approval: patterns(`(?i)do you want to (run|allow|edit)`),
+		Cooking:  patterns(`(?i)esc to cancel`),
+		Input:    patterns(`(?i)enter @ to mention files`),
+	},
+	"cursor-agent": {
+		Approval: patterns(`(?i)run this command\?`, `(?i)\(y\) \(enter\)`),
+		Cooking:  patterns(`(?i)ctrl\+c to stop`, `(?i)generating`),
+		Input:    patterns(`(?i)/ commands · @ files`),
+		Approve:  []string{"y"},
+	},
+	"goose": {
+		Approval: patterns(`(?i)goose would like to call`, `(?i)allow\?`),
+		Cooking:  patterns(`(?i)ctrl\+c to interrupt`),
+		Input:    patterns(`(?m)^\( O\)> ?$`),
+	},
+	"amp": {
+		Approval: patterns(`(?i)allow (this|once|always)`, `(?i)approve\?`),
+		Cooking:  patterns(`(?i)esc to cancel`),
+	},
+	"droid": {
+		Approval: patterns(`(?i)allow this (action|command)`),
+		Cooking:  patterns(`(?i)esc to stop`),
+	},
+	"auggie": {
+		Approval: patterns(`(?i)allow this tool`),
+		Cooking:  patterns(`(?i)esc to (interrupt|cancel)`),
+	},
+	"cn": {
+		Approval: patterns(`(?i)allow (this|tool)`),
+		Cooking:  patterns(`(?i)esc to interrupt`),
+	},
+	"q": {
+		Approval: patterns(`(?i)allow this action\?`, `\[y/n/t\]`),
+		Cooking:  patterns(`(?i)thinking\.\.\.`),
+		Input:    patterns(`(?m)^> ?$`),
+		Approve:  []string{"y", "Enter"},
+		Deny:     []string{"n", "Enter"},
+	},
+	"jules": {
+		Approval: patterns(`(?i)approve (the )?plan`),
+	},
+	"tuai": {
+		Cooking: patterns(`(?i)esc to (interrupt|cancel)`),
+	},
+}
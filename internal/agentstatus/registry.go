@@ -0,0 +1,218 @@
+package agentstatus
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"ide/internal/config"
+)
+
+// Thresholds tune Detect's CPU heuristic for one tool.
+type Thresholds struct {
+	MinDelta   float64 // cooking needs CPU above baseline+MinDelta...
+	Ratio      float64 // ...and above baseline*Ratio
+	LowSamples int     // consecutive low samples that end cooking
+	Warmup     int     // baseline-only samples of a new window
+
+	StallAfter time.Duration // cooking with an unchanged screen this long is stalled
+}
+
+// DefaultThresholds suit interactive agents that idle near zero CPU and
+// burn a core while streaming.
+var DefaultThresholds = Thresholds{MinDelta: 5, Ratio: 1.3, LowSamples: 3, Warmup: 10, StallAfter: 10 * time.Minute}
+
+// override returns t with the non-zero fields of c.
+func (t Thresholds) override(c config.AgentThresholds) Thresholds {
+	if c.MinDelta != 0 {
+		t.MinDelta = c.MinDelta
+	}
+	if c.Ratio != 0 {
+		t.Ratio = c.Ratio
+	}
+	if c.LowSamples != 0 {
+		t.LowSamples = c.LowSamples
+	}
+	if c.Warmup != 0 {
+		t.Warmup = c.Warmup
+	}
+	if c.StallMinutes != 0 {
+		t.StallAfter = time.Duration(c.StallMinutes * float64(time.Minute))
+	}
+	return t
+}
+
+func (t Thresholds) validate() error {
+	if t.MinDelta < 0 || t.Ratio < 1 || t.LowSamples < 1 || t.Warmup < 0 || t.StallAfter <= 0 {
+		return fmt.Errorf("thresholds %s: want min_delta >= 0, ratio >= 1, low_samples >= 1, warmup >= 0, stall_minutes > 0", t)
+	}
+	return nil
+}
+
+func (t Thresholds) String() string {
+	return fmt.Sprintf("+%g ×%g low %d warmup %d stall %s", t.MinDelta, t.Ratio, t.LowSamples, t.Warmup, FormatDuration(t.StallAfter))
+}
+
+// Tool is one agent CLI the registry recognises.
+type Tool struct {
+	Name       string
+	Aliases    []string // other commands that run it, normalised
+	Pack       string   // key of Packs for output detection; "" for none
+	Thresholds Thresholds
+	Configured bool // added or changed by the config file
+}
+
+// Registry resolves process names and command lines to agent tools.
+type Registry struct {
+	tools   map[string]Tool
+	aliases map[string]string // normalised alias -> tool name
+	base    Thresholds        // for [ai]-tagged windows running unknown tools
+}
+
+// NewRegistry starts from KnownTools and applies cfg: removals first, then
+// added and changed tools.
+func NewRegistry(cfg config.Agents) (*Registry, error) {
+	base := DefaultThresholds.override(cfg.Thresholds)
+	if err := base.validate(); err != nil {
+		return nil, fmt.Errorf("agents: %w", err)
+	}
+	r := &Registry{tools: map[string]Tool{}, aliases: map[string]string{}, base: base}
+	for name := range KnownTools {
+		pack := ""
+		if _, ok := Packs[name]; ok {
+			pack = name
+		}
+		r.tools[name] = Tool{Name: name, Pack: pack, Thresholds: base}
+	}
+	for _, name := range cfg.Remove {
+		delete(r.tools, ToolName(name))
+	}
+	for _, c := range cfg.Tools {
+		name := ToolName(c.Name)
+		if name == "" || name != strings.ToLower(strings.TrimSpace(c.Name)) {
+			return nil, fmt.Errorf("agents: tool name %q must be a single command name", c.Name)
+		}
+		tool, ok := r.tools[name]
+		if !ok {
+			tool = Tool{Name: name, Thresholds: base}
+			if _, ok := Packs[name]; ok {
+				tool.Pack = name
+			}
+		}
+		tool.Configured = true
+		if c.Pack != "" {
+			if _, ok := Packs[c.Pack]; !ok {
+				return nil, fmt.Errorf("agents: tool %s: no pattern pack %q", name, c.Pack)
+			}
+			tool.Pack = c.Pack
+		}
+		tool.Thresholds = tool.Thresholds.override(c.Thresholds)
+		if err := tool.Thresholds.validate(); err != nil {
+			return nil, fmt.Errorf("agents: tool %s: %w", name, err)
+		}
+		for _, a := range c.Aliases {
+			if a = normalizeCommand(a); a != "" && !slices.Contains(tool.Aliases, a) {
+				tool.Aliases = append(tool.Aliases, a)
+			}
+		}
+		r.tools[name] = tool
+	}
+	for _, tool := range r.Tools() {
+		for _, a := range tool.Aliases {
+			if other, ok := r.aliases[a]; ok && other != tool.Name {
+				return nil, fmt.Errorf("agents: alias %q is claimed by both %s and %s", a, other, tool.Name)
+			}
+			r.aliases[a] = tool.Name
+		}
+	}
+	return r, nil
+}
+
+// Lookup resolves a process name or command line to its tool: the longest
+// alias that is a word prefix of the command wins, then the command name
+// itself. tmux reports a foreground process by name alone, so an alias
+// with arguments ("python -m aider") matches the command line a window
+// template runs, not the process it became.
+func (r *Registry) Lookup(command string) (Tool, bool) {
+	words := strings.Fields(normalizeCommand(command))
+	for n := len(words); n > 0; n-- {
+		if name, ok := r.aliases[strings.Join(words[:n], " ")]; ok {
+			return r.tools[name], true
+		}
+	}
+	if len(words) > 0 {
+		if tool, ok := r.tools[words[0]]; ok {
+			return tool, true
+		}
+	}
+	return Tool{}, false
+}
+
+// Tools lists the registry by name.
+func (r *Registry) Tools() []Tool {
+	out := make([]Tool, 0, len(r.tools))
+	for _, t := range r.tools {
+		out = append(out, t)
+	}
+	slices.SortFunc(out, func(a, b Tool) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
+
+// normalizeCommand lowercases a command line, strips the path from the
+// command and collapses whitespace: "/usr/bin/Python  -m aider" becomes
+// "python -m aider".
+func normalizeCommand(command string) string {
+	words := strings.Fields(strings.ToLower(command))
+	if len(words) == 0 {
+		return ""
+	}
+	words[0] = ToolName(words[0])
+	return strings.Join(words, " ")
+}
+
+// BuiltinRegistry is the registry without a config: the KnownTools with
+// the default thresholds.
+func BuiltinRegistry() *Registry {
+	r, _ := NewRegistry(config.Agents{})
+	return r
+}
+
+// IsAITool reports whether name is an AI-agent CLI of the registry.
+// Accepts paths and argv with arguments — the command name, or a
+// configured alias prefix of the command line, is checked
+// case-insensitively.
+func (r *Registry) IsAITool(name string) bool {
+	_, ok := r.Lookup(name)
+	return ok
+}
+
+// Resolve returns the registered tool name for a process name or command
+// line, or "" when it is not an agent.
+func (r *Registry) Resolve(command string) string {
+	tool, ok := r.Lookup(command)
+	if !ok {
+		return ""
+	}
+	return tool.Name
+}
+
+// thresholds returns the thresholds of the tool named name, the configured
+// defaults for unknown tools.
+func (r *Registry) thresholds(name string) Thresholds {
+	if tool, ok := r.tools[name]; ok {
+		return tool.Thresholds
+	}
+	return r.base
+}
+
+// pack returns the pattern pack output detection uses for the tool named
+// name.
+func (r *Registry) pack(name string) (PatternPack, bool) {
+	if tool, ok := r.tools[name]; ok {
+		pack, ok := Packs[tool.Pack]
+		return pack, ok
+	}
+	pack, ok := Packs[name]
+	return pack, ok
+}
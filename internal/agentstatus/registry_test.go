@@ -0,0 +1,94 @@
+package agentstatus
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"ide/internal/config"
+)
+
+func TestRegistryLookup(t *testing.T) {
+	r, err := NewRegistry(config.Agents{
+		Remove: []string{"llm"},
+		Tools: []config.AgentTool{
+			{Name: "aider", Aliases: []string{"/usr/bin/Aider-Chat", "Python  -m aider"}},
+			{Name: "mywrap", Aliases: []string{"mywrap-cli"}, Pack: "claude"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		command string
+		want    string // "" = not an agent
+	}{
+		{"claude", "claude"},
+		{"/usr/local/bin/Claude --resume", "claude"},
+		{"aider-chat --model x", "aider"},
+		{"python -m aider --model x", "aider"},
+		{"/usr/bin/python3 -m aider", ""},
+		{"python -m http.server", ""},
+		{"python", ""},
+		{"mywrap-cli --resume", "mywrap"},
+		{"node", ""},
+		{"mywrap", "mywrap"},
+		{"llm", ""},
+		{"", ""},
+	}
+	for _, tc := range tests {
+		tool, ok := r.Lookup(tc.command)
+		if got := tool.Name; got != tc.want || ok != (tc.want != "") {
+			t.Errorf("Lookup(%q) = %q, %v; want %q", tc.command, got, ok, tc.want)
+		}
+	}
+	if tool, _ := r.Lookup("mywrap"); tool.Pack != "claude" || !tool.Configured {
+		t.Errorf("mywrap = %+v, want the claude pack and Configured", tool)
+	}
+}
+
+func TestRegistryThresholds(t *testing.T) {
+	r, err := NewRegistry(config.Agents{
+		Thresholds: config.AgentThresholds{Ratio: 1.5},
+		Tools:      []config.AgentTool{{Name: "codex", Thresholds: config.AgentThresholds{MinDelta: 20, Warmup: 2, StallMinutes: 30}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tool, _ := r.Lookup("claude"); tool.Thresholds != (Thresholds{MinDelta: 5, Ratio: 1.5, LowSamples: 3, Warmup: 10, StallAfter: 10 * time.Minute}) {
+		t.Errorf("claude thresholds = %s, want the defaults with the configured ratio", tool.Thresholds)
+	}
+	if tool, _ := r.Lookup("codex"); tool.Thresholds != (Thresholds{MinDelta: 20, Ratio: 1.5, LowSamples: 3, Warmup: 2, StallAfter: 30 * time.Minute}) {
+		t.Errorf("codex thresholds = %s", tool.Thresholds)
+	}
+
+	// 15% CPU cooks with the default profile but not with codex's +20.
+	var w WindowInfo
+	w.Status, w.SampleCount, w.BaselineCPU = StatusAwaitingInput, 10, 1
+	s := Sample{Process: ProcessInfo{CPU: 15}, Tool: "codex"}
+	if status, _ := (CPUDetector{r}).Detect(s, &w); status != StatusAwaitingInput {
+		t.Errorf("codex at 15%% CPU = %s, want awaiting_input", status)
+	}
+	w = WindowInfo{Status: StatusAwaitingInput, SampleCount: 10, BaselineCPU: 1}
+	s.Tool = "claude"
+	if status, _ := (CPUDetector{r}).Detect(s, &w); status != StatusCooking {
+		t.Errorf("claude at 15%% CPU = %s, want cooking", status)
+	}
+}
+
+func TestRegistryRejectsBadConfig(t *testing.T) {
+	for _, tc := range []struct {
+		cfg  config.Agents
+		want string
+	}{
+		{config.Agents{Tools: []config.AgentTool{{Name: "python -m x"}}}, "single command name"},
+		{config.Agents{Tools: []config.AgentTool{{Name: "x", Pack: "nope"}}}, "no pattern pack"},
+		{config.Agents{Tools: []config.AgentTool{{Name: "a", Aliases: []string{"node"}}, {Name: "b", Aliases: []string{"node"}}}}, "claimed by both a and b"},
+		{config.Agents{Thresholds: config.AgentThresholds{Ratio: 0.5}}, "ratio >= 1"},
+	} {
+		_, err := NewRegistry(tc.cfg)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("NewRegistry(%+v) error = %v, want %q", tc.cfg, err, tc.want)
+		}
+	}
+}
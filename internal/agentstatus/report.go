@@ -0,0 +1,68 @@
+package agentstatus
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StatusError is only ever self-reported: the agent's turn ended in an
+// error.
+const StatusError Status = "error"
+
+// DefaultReportTTL is how long a self-reported status overrides detection
+// when the reporter does not say otherwise. It bounds how long a report
+// from an agent that has since crashed or moved on stays on screen.
+const DefaultReportTTL = 10 * time.Minute
+
+// Report is a status an agent reported about itself, e.g. from a Claude
+// Code hook running `ide agent report`. Reports are filed by window name,
+// so PanePID ties one to the pane it came from: a window of the same name in
+// a recreated session must not inherit it.
+type Report struct {
+	Status  Status    `json:"status"`
+	Message string    `json:"message,omitempty"`
+	At      time.Time `json:"at"`
+	Expires time.Time `json:"expires"`
+	PanePID int       `json:"pane_pid,omitempty"`
+}
+
+// Active reports whether r still overrides detection at now.
+func (r Report) Active(now time.Time) bool {
+	return r.Status != "" && now.Before(r.Expires)
+}
+
+// From reports whether r was filed from the pane whose process is panePID.
+// A report without a pane, or a pane that is not known, is taken as
+// matching; the TTL still bounds it.
+func (r Report) From(panePID int) bool {
+	return r.PanePID == 0 || panePID == 0 || r.PanePID == panePID
+}
+
+// ParseReportStatus maps the status names `ide agent report` accepts onto
+// statuses: "done" is an agent back at its prompt, like "awaiting".
+func ParseReportStatus(s string) (Status, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "cooking":
+		return StatusCooking, nil
+	case "awaiting", "done":
+		return StatusAwaitingInput, nil
+	case "approval":
+		return StatusAwaitingApproval, nil
+	case "error":
+		return StatusError, nil
+	}
+	return "", fmt.Errorf("unknown status %q (want cooking, awaiting, approval, done or error)", s)
+}
+
+// ReportDetector returns the agent's own report while it is active. It
+// comes first in DefaultDetector: the agent knows better than any
+// heuristic.
+type ReportDetector struct{}
+
+func (ReportDetector) Detect(s Sample, w *WindowInfo) (Status, bool) {
+	if s.Report.Active(s.Process.Timestamp) {
+		return s.Report.Status, true
+	}
+	return StatusIdle, false
+}
@@ -0,0 +1,73 @@
+package agentstatus
+
+import (
+	"hash/fnv"
+	"strings"
+	"time"
+)
+
+// StatusStalled is only ever derived by StallDetector: the agent has been
+// cooking for its tool's StallAfter without its screen changing, hung on a
+// network call or stuck in a loop.
+const StatusStalled Status = "stalled"
+
+// StallDetector wraps a detector and turns its cooking into stalled once
+// the screen has looked the same for the tool's StallAfter. To the wrapped
+// detector a stalled window is still cooking, so its counters carry on and
+// it recovers as soon as the agent does.
+type StallDetector struct {
+	Detector
+	Registry *Registry
+}
+
+func (d StallDetector) Detect(s Sample, w *WindowInfo) (Status, bool) {
+	if w.Status == StatusStalled {
+		w.Status = StatusCooking
+	}
+	status, ok := d.Detector.Detect(s, w)
+	if !ok || status != StatusCooking {
+		w.ScreenHash, w.ScreenSince = 0, time.Time{}
+		return status, ok
+	}
+	if s.Output == "" {
+		// Nothing captured is no evidence either way.
+		return status, ok
+	}
+	now := s.Process.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+	hash := d.Registry.ScreenHash(s.Tool, s.Output)
+	if w.ScreenSince.IsZero() || hash != w.ScreenHash {
+		w.ScreenHash, w.ScreenSince = hash, now
+		return status, ok
+	}
+	if now.Sub(w.ScreenSince) >= d.Registry.thresholds(s.Tool).StallAfter {
+		return StatusStalled, true
+	}
+	return status, ok
+}
+
+// ScreenHash fingerprints pane output for stall detection. Lines matching
+// the tool's cooking patterns are left out: spinners and elapsed-time
+// counters keep animating while the agent is hung.
+func (r *Registry) ScreenHash(tool, output string) uint64 {
+	packs := []PatternPack{GenericPack}
+	if pack, ok := r.pack(tool); ok {
+		packs = append(packs, pack)
+	}
+	h := fnv.New64a()
+lines:
+	for _, line := range strings.Split(output, "\n") {
+		for _, pack := range packs {
+			for _, pat := range pack.Cooking {
+				if pat.MatchString(line) {
+					continue lines
+				}
+			}
+		}
+		h.Write([]byte(strings.TrimRight(line, " \t")))
+		h.Write([]byte{'\n'})
+	}
+	return h.Sum64()
+}
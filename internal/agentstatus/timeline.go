@@ -0,0 +1,153 @@
+package agentstatus
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Transition is one status change of an agent window.
+type Transition struct {
+	At   time.Time `json:"at"`
+	From Status    `json:"from,omitempty"` // "" for the first status ide saw
+	To   Status    `json:"to"`
+}
+
+// Timeline is an agent window's transitions, oldest first. The window is
+// in a transition's To status until the next transition.
+type Timeline []Transition
+
+// NewTimeline orders transitions read back from a timeline file by time.
+// The TUI appends them from concurrent commands, so lines written moments
+// apart can land out of order; simultaneous ones keep their file order.
+func NewTimeline(transitions []Transition) Timeline {
+	tl := Timeline(transitions)
+	slices.SortStableFunc(tl, func(a, b Transition) int { return a.At.Compare(b.At) })
+	return tl
+}
+
+// TimelineRetention is how much history the TUI keeps in the state
+// directory; older transitions are dropped when it loads the timelines.
+const TimelineRetention = 7 * 24 * time.Hour
+
+// StatusAt returns the status in effect at t; idle before the first
+// transition.
+func (tl Timeline) StatusAt(t time.Time) Status {
+	status := StatusIdle
+	for _, tr := range tl {
+		if tr.At.After(t) {
+			break
+		}
+		status = tr.To
+	}
+	return status
+}
+
+// Since drops the transitions before t, keeping the last of them (the one
+// still in effect at t) so durations from t onwards stay right.
+func (tl Timeline) Since(t time.Time) Timeline {
+	first := 0
+	for i, tr := range tl {
+		if tr.At.After(t) {
+			break
+		}
+		first = i
+	}
+	return tl[first:]
+}
+
+// Current returns the latest status and when the window entered it.
+func (tl Timeline) Current() (Status, time.Time) {
+	if len(tl) == 0 {
+		return StatusIdle, time.Time{}
+	}
+	last := tl[len(tl)-1]
+	return last.To, last.At
+}
+
+// Close ends the timeline at the last time the window was seen: the window
+// went away or ide stopped watching it, so its status must not run on to
+// now. It returns a transition to idle, or false when the window is already
+// idle.
+func (tl Timeline) Close(at time.Time) (Transition, bool) {
+	status, since := tl.Current()
+	if status == StatusIdle {
+		return Transition{}, false
+	}
+	if at.Before(since) {
+		at = since
+	}
+	return Transition{At: at, From: status, To: StatusIdle}, true
+}
+
+// TimeIn sums how long the window spent in each status between since and
+// now.
+func (tl Timeline) TimeIn(since, now time.Time) map[Status]time.Duration {
+	out := map[Status]time.Duration{}
+	for i, tr := range tl {
+		start, end := tr.At, now
+		if i+1 < len(tl) {
+			end = tl[i+1].At
+		}
+		if start.Before(since) {
+			start = since
+		}
+		if end.After(now) {
+			end = now
+		}
+		if end.After(start) {
+			out[tr.To] += end.Sub(start)
+		}
+	}
+	return out
+}
+
+// Entries counts the transitions into each status between since and now:
+// how many times the agent started cooking, stopped to wait, and so on.
+func (tl Timeline) Entries(since, now time.Time) map[Status]int {
+	out := map[Status]int{}
+	for _, tr := range tl {
+		if !tr.At.Before(since) && !tr.At.After(now) {
+			out[tr.To]++
+		}
+	}
+	return out
+}
+
+// Buckets splits since..now into n equal slots and returns the status the
+// window spent most of each slot in, for sparklines.
+func (tl Timeline) Buckets(since, now time.Time, n int) []Status {
+	if n <= 0 || !now.After(since) {
+		return nil
+	}
+	out := make([]Status, n)
+	step := now.Sub(since) / time.Duration(n)
+	for i := range out {
+		start := since.Add(time.Duration(i) * step)
+		end := start.Add(step)
+		in := tl.TimeIn(start, end)
+		out[i] = tl.StatusAt(start)
+		longest := in[out[i]]
+		// Ties go to the later, more notable status in this order.
+		for _, status := range []Status{StatusIdle, StatusAwaitingInput, StatusCooking, StatusStalled, StatusAwaitingApproval, StatusError} {
+			if d := in[status]; d > 0 && d >= longest {
+				out[i], longest = status, d
+			}
+		}
+	}
+	return out
+}
+
+// FormatDuration renders d coarsely for badges and reports: "45s", "12m",
+// "1h05m", "2d3h".
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+}
@@ -0,0 +1,103 @@
+package agentstatus
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTimelineMetrics(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(min int) time.Time { return t0.Add(time.Duration(min) * time.Minute) }
+	tl := Timeline{
+		{At: at(0), To: StatusCooking},
+		{At: at(10), From: StatusCooking, To: StatusAwaitingInput},
+		{At: at(25), From: StatusAwaitingInput, To: StatusCooking},
+		{At: at(30), From: StatusCooking, To: StatusAwaitingApproval},
+		{At: at(32), From: StatusAwaitingApproval, To: StatusCooking},
+		{At: at(50), From: StatusCooking, To: StatusAwaitingInput},
+	}
+	now := at(60)
+
+	if got, want := tl.TimeIn(at(0), now), map[Status]time.Duration{
+		StatusCooking:          33 * time.Minute,
+		StatusAwaitingInput:    25 * time.Minute,
+		StatusAwaitingApproval: 2 * time.Minute,
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TimeIn = %v, want %v", got, want)
+	}
+	if got, want := tl.TimeIn(at(20), now), map[Status]time.Duration{
+		StatusCooking:          23 * time.Minute,
+		StatusAwaitingInput:    15 * time.Minute,
+		StatusAwaitingApproval: 2 * time.Minute,
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TimeIn since 20m = %v, want %v", got, want)
+	}
+	if got, want := tl.Entries(at(20), now), map[Status]int{
+		StatusCooking:          2,
+		StatusAwaitingApproval: 1,
+		StatusAwaitingInput:    1,
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Entries = %v, want %v", got, want)
+	}
+	if got := tl.Since(at(20)); len(got) != 5 || got[0].To != StatusAwaitingInput {
+		t.Errorf("Since(20m) = %+v, want the transition in effect at 20m first", got)
+	}
+	if got := tl.StatusAt(at(31)); got != StatusAwaitingApproval {
+		t.Errorf("StatusAt(31m) = %s", got)
+	}
+	if got := tl.StatusAt(t0.Add(-time.Minute)); got != StatusIdle {
+		t.Errorf("StatusAt before the first transition = %s, want idle", got)
+	}
+	if status, since := tl.Current(); status != StatusAwaitingInput || !since.Equal(at(50)) {
+		t.Errorf("Current = %s since %v", status, since)
+	}
+	// 20m-30m is half waiting, half cooking: the tie goes to cooking.
+	if got, want := tl.Buckets(at(0), now, 6), []Status{
+		StatusCooking, StatusAwaitingInput, StatusCooking, StatusCooking, StatusCooking, StatusAwaitingInput,
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Buckets = %v, want %v", got, want)
+	}
+
+	// The window went away at 55m: the wait stops counting there.
+	tr, ok := tl.Close(at(55))
+	if !ok || tr.From != StatusAwaitingInput || tr.To != StatusIdle {
+		t.Fatalf("Close = %+v, %v", tr, ok)
+	}
+	closed := append(tl, tr)
+	if got := closed.TimeIn(at(0), now)[StatusAwaitingInput]; got != 20*time.Minute {
+		t.Errorf("TimeIn(awaiting input) after Close = %v, want 20m", got)
+	}
+	if _, ok := closed.Close(now); ok {
+		t.Error("Close of a closed timeline added another transition")
+	}
+	if tr, _ := tl.Close(at(40)); !tr.At.Equal(at(50)) {
+		t.Errorf("Close before the last transition at %v, want it clamped to 50m", tr.At)
+	}
+}
+
+func TestNewTimelineSortsAppendsByTime(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	read := []Transition{
+		{At: t0, To: StatusCooking},
+		{At: t0.Add(2 * time.Second), From: StatusAwaitingInput, To: StatusCooking},
+		{At: t0.Add(time.Second), From: StatusCooking, To: StatusAwaitingInput},
+	}
+	want := Timeline{read[0], read[2], read[1]}
+	if got := NewTimeline(read); !reflect.DeepEqual(got, want) {
+		t.Errorf("NewTimeline = %v, want %v", got, want)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		45 * time.Second:                "45s",
+		12*time.Minute + 30*time.Second: "12m",
+		65 * time.Minute:                "1h05m",
+		51 * time.Hour:                  "2d3h",
+	} {
+		if got := FormatDuration(d); got != want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
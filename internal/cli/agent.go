@@ -0,0 +1,334 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"ide/internal/agentstatus"
+	"ide/internal/config"
+	"ide/internal/state"
+	"ide/internal/tmux"
+)
+
+func dispatchAgent(args []string) int {
+	if len(args) == 0 {
+		return usagef(os.Stderr, "usage: ide agent <next|report|stats|tools> ...")
+	}
+	switch args[0] {
+	case "next":
+		return agentNext(args[1:])
+	case "report":
+		return agentReport(args[1:])
+	case "stats":
+		return agentStats(args[1:])
+	case "tools":
+		return agentTools(args[1:])
+	}
+	return usagef(os.Stderr, "ide agent: unknown subcommand %q", args[0])
+}
+
+// agentWindow is a window of a running ide session that hosts an AI agent.
+type agentWindow struct {
+	session, window string
+}
+
+// isAgentWindow mirrors the TUI's test: the window's template is tagged
+// [ai] or runs a known agent CLI, or its foreground process is one.
+func isAgentWindow(agents *agentstatus.Registry, env config.Environment, window, command string) bool {
+	if tmpl, ok := tmux.FindWindowTemplate(env, window); ok {
+		for _, t := range tmpl.Tags {
+			if strings.EqualFold(t, "ai") {
+				return true
+			}
+		}
+		if agents.IsAITool(tmpl.Cmd) {
+			return true
+		}
+	}
+	return agents.IsAITool(command)
+}
+
+// agentWindows lists the agent windows of every running environment, in
+// tmux's session and window order.
+func agentWindows(agents *agentstatus.Registry, envs []config.Environment, snap tmux.SessionsSnapshot) []agentWindow {
+	bySession := make(map[string]config.Environment, len(envs))
+	for _, e := range envs {
+		bySession[tmux.SessionName(e.Name)] = e
+	}
+	var out []agentWindow
+	for _, s := range snap.Names {
+		env, ok := bySession[s]
+		if !ok {
+			continue
+		}
+		for _, w := range snap.Windows[s] {
+			if isAgentWindow(agents, env, w, snap.Commands[s][w]) {
+				out = append(out, agentWindow{session: s, window: w})
+			}
+		}
+	}
+	return out
+}
+
+// nextAgentWindow picks the agent window after (session, window), wrapping
+// around; when the current window is not an agent window the first one
+// after it in session order is picked.
+func nextAgentWindow(agents []agentWindow, snap tmux.SessionsSnapshot, session, window string) (agentWindow, bool) {
+	if len(agents) == 0 {
+		return agentWindow{}, false
+	}
+	// Position every window in one global order so "after" is well defined
+	// even when the current window hosts no agent.
+	order := map[agentWindow]int{}
+	for _, s := range snap.Names {
+		for _, w := range snap.Windows[s] {
+			order[agentWindow{s, w}] = len(order)
+		}
+	}
+	cur, ok := order[agentWindow{session, window}]
+	if !ok {
+		return agents[0], true
+	}
+	for _, a := range agents {
+		if order[a] > cur {
+			return a, true
+		}
+	}
+	return agents[0], true
+}
+
+// agentNext switches the current tmux client to the next agent window.
+// Bound to a prefix key by ide tmux install, so it prints nothing on
+// success: run-shell would show the output over the pane.
+func agentNext(args []string) int {
+	if len(args) != 0 {
+		return usagef(os.Stderr, "usage: ide agent next")
+	}
+	data, err := config.LoadAll()
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	// Like the TUI, keep logs out of the pane unless DEBUG is set.
+	if os.Getenv("DEBUG") == "" {
+		log.SetOutput(io.Discard)
+	}
+	// A broken agents section still leaves the built-in agents to switch
+	// between, as in the TUI; `ide agent tools` says what is wrong.
+	registry, err := agentstatus.NewRegistry(data.Agents)
+	if err != nil {
+		log.Printf("agent next: %v", err)
+		registry = agentstatus.BuiltinRegistry()
+	}
+	envs := data.Environments
+	snap, err := tmux.ListSessionsSnapshot()
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	session, window, err := tmux.CurrentWindow()
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	next, ok := nextAgentWindow(agentWindows(registry, envs, snap), snap, session, window)
+	if !ok {
+		return errf(os.Stderr, "no agent windows in running sessions")
+	}
+	target := next.session + ":" + next.window
+	if next.session == session {
+		err = tmux.SelectWindow(target)
+	} else {
+		err = tmux.SwitchClient(target)
+	}
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	return 0
+}
+
+const agentReportUsage = "usage: ide agent report --status cooking|awaiting|approval|done|error [--message TEXT] [--ttl DUR]"
+
+// agentReport records the status the agent in $TMUX_PANE reports about
+// itself; the TUI and the search popup show it in place of the detected one
+// until it expires. Meant for agent hooks, so it prints nothing on success.
+func agentReport(args []string) int {
+	fs := newFlagSet("agent report")
+	status := fs.string("status", "cooking, awaiting, approval, done or error")
+	message := fs.string("message", "short note shown with the status")
+	ttl := fs.string("ttl", "how long the report overrides detection (default 10m)")
+	if err := fs.parse(args); err != nil {
+		return parseUsagef(os.Stderr, err, agentReportUsage)
+	}
+	if len(fs.positional()) != 0 || !fs.provided("status") {
+		return usagef(os.Stderr, agentReportUsage)
+	}
+	st, err := agentstatus.ParseReportStatus(*status)
+	if err != nil {
+		return errf(os.Stderr, "--status: %v", err)
+	}
+	expiresIn := agentstatus.DefaultReportTTL
+	if fs.provided("ttl") {
+		d, err := time.ParseDuration(trim(*ttl))
+		if err != nil || d <= 0 {
+			return errf(os.Stderr, "--ttl: %q is not a duration like 5m", *ttl)
+		}
+		expiresIn = d
+	}
+	if os.Getenv("TMUX_PANE") == "" {
+		return errf(os.Stderr, "$TMUX_PANE is not set: run ide agent report from the agent's tmux pane")
+	}
+	session, window, err := tmux.CurrentWindow()
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	panePID, err := tmux.CurrentPanePID()
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	now := time.Now()
+	report := agentstatus.Report{Status: st, Message: trim(*message), At: now, Expires: now.Add(expiresIn), PanePID: panePID}
+	if err := state.WriteJSON(state.AgentReportName(agentstatus.Key(session, window)), report); err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	return 0
+}
+
+const agentStatsUsage = "usage: ide agent stats [env] [--since DUR]"
+
+// agentStats prints, per agent window with a recorded timeline, how long
+// it spent cooking and waiting since --since, how often it entered each
+// state, and where it stands now. The TUI records the timelines, so only
+// the time it was running is covered.
+func agentStats(args []string) int {
+	fs := newFlagSet("agent stats")
+	sinceFlag := fs.string("since", "how far back to look (default 24h)")
+	if err := fs.parse(args); err != nil {
+		return parseUsagef(os.Stderr, err, agentStatsUsage)
+	}
+	pos := fs.positional()
+	if len(pos) > 1 {
+		return usagef(os.Stderr, agentStatsUsage)
+	}
+	window := 24 * time.Hour
+	if fs.provided("since") {
+		d, err := time.ParseDuration(trim(*sinceFlag))
+		if err != nil || d <= 0 {
+			return errf(os.Stderr, "--since: %q is not a duration like 24h", *sinceFlag)
+		}
+		window = d
+	}
+	envs, err := config.Load()
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	names := make(map[string]string, len(envs)) // session -> env name
+	for _, e := range envs {
+		names[tmux.SessionName(e.Name)] = e.Name
+	}
+	if len(pos) == 1 {
+		idx := findEnv(envs, pos[0])
+		if idx < 0 {
+			return errf(os.Stderr, "no such environment %q", pos[0])
+		}
+		names = map[string]string{tmux.SessionName(envs[idx].Name): envs[idx].Name}
+	}
+	keys, err := state.AgentTimelineKeys()
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	slices.Sort(keys)
+	// Without a tmux server no window is live, which is what an empty
+	// snapshot says.
+	snap, _ := tmux.ListSessionsSnapshot()
+	now := time.Now()
+	since := now.Add(-window)
+	printed := 0
+	for _, key := range keys {
+		session, win, _ := strings.Cut(key, ":")
+		env, ok := names[session]
+		if !ok {
+			continue
+		}
+		transitions, err := state.ReadJSONLines[agentstatus.Transition](state.AgentTimelineName(key))
+		if err != nil {
+			return errf(os.Stderr, "%v", err)
+		}
+		tl := agentstatus.NewTimeline(transitions).Since(since)
+		if len(tl) == 0 {
+			continue
+		}
+		live := slices.Contains(snap.Windows[session], win)
+		printAgentStats(os.Stdout, env+" / "+win, tl, live, since, now)
+		printed++
+	}
+	if printed == 0 {
+		fmt.Printf("(no agent activity in the last %s)\n", agentstatus.FormatDuration(window))
+	}
+	return 0
+}
+
+// statsStatuses are the rows of an agent's stats, in display order.
+var statsStatuses = []agentstatus.Status{
+	agentstatus.StatusCooking,
+	agentstatus.StatusStalled,
+	agentstatus.StatusAwaitingInput,
+	agentstatus.StatusAwaitingApproval,
+	agentstatus.StatusError,
+	agentstatus.StatusIdle,
+}
+
+// printAgentStats prints one agent's stats. The timeline of a window that
+// is gone ends at its last transition unless the TUI closed it: nobody saw
+// how long the window stayed in its last status.
+func printAgentStats(w io.Writer, label string, tl agentstatus.Timeline, live bool, since, now time.Time) {
+	fmt.Fprintln(w, label)
+	if tr, ok := tl.Close(time.Time{}); ok && !live {
+		tl = append(tl, tr)
+	}
+	timeIn := tl.TimeIn(since, now)
+	entries := tl.Entries(since, now)
+	for _, status := range statsStatuses {
+		if timeIn[status] == 0 && entries[status] == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%dx\n", status, agentstatus.FormatDuration(timeIn[status]), entries[status])
+	}
+	if !live {
+		fmt.Fprintln(w, "  now\twindow gone")
+		return
+	}
+	status, at := tl.Current()
+	fmt.Fprintf(w, "  now\t%s for %s\n", status, agentstatus.FormatDuration(now.Sub(at)))
+}
+
+// agentTools lists the effective tool registry: the built-in agent CLIs
+// with the config's "agents" section applied.
+func agentTools(args []string) int {
+	if len(args) != 0 {
+		return usagef(os.Stderr, "usage: ide agent tools")
+	}
+	data, err := config.LoadAll()
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	registry, err := agentstatus.NewRegistry(data.Agents)
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	printAgentTools(os.Stdout, registry)
+	return 0
+}
+
+func printAgentTools(w io.Writer, r *agentstatus.Registry) {
+	for _, t := range r.Tools() {
+		source := "builtin"
+		if t.Configured {
+			source = "config"
+		}
+		aliases := emptyDash(strings.Join(t.Aliases, ", "))
+		fmt.Fprintf(w, "%s\t%s\tpack=%s\taliases=%s\tthresholds=%s\n", t.Name, source, emptyDash(t.Pack), aliases, t.Thresholds)
+	}
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"ide/internal/agentstatus"
+	"ide/internal/config"
+	"ide/internal/tmux"
+)
+
+func TestNextAgentWindow(t *testing.T) {
+	envs := []config.Environment{
+		{Name: "api", Windows: []config.WindowTemplate{{Name: "editor"}, {Name: "agent", Tags: []string{"ai"}}}},
+		{Name: "web", Windows: []config.WindowTemplate{{Name: "editor"}, {Name: "shell"}}},
+	}
+	snap := tmux.SessionsSnapshot{
+		Names: []string{"ide-api", "scratch", "ide-web"},
+		Windows: map[string][]string{
+			"ide-api": {"editor", "agent"},
+			"scratch": {"claude"},
+			"ide-web": {"editor", "shell"},
+		},
+		Commands: map[string]map[string]string{
+			"ide-api": {"editor": "nvim", "agent": "node"},
+			"scratch": {"claude": "claude"},
+			"ide-web": {"editor": "nvim", "shell": "codex"},
+		},
+	}
+	agents := agentWindows(agentstatus.BuiltinRegistry(), envs, snap)
+	want := []agentWindow{{"ide-api", "agent"}, {"ide-web", "shell"}}
+	if len(agents) != len(want) || agents[0] != want[0] || agents[1] != want[1] {
+		t.Fatalf("agentWindows = %v, want %v (non-ide sessions skipped)", agents, want)
+	}
+
+	tests := []struct {
+		session, window string
+		want            agentWindow
+	}{
+		{"ide-api", "agent", want[1]},
+		{"ide-web", "shell", want[0]}, // wraps around
+		{"ide-api", "editor", want[0]},
+		{"ide-web", "editor", want[1]},
+		{"elsewhere", "x", want[0]},
+	}
+	for _, tt := range tests {
+		got, ok := nextAgentWindow(agents, snap, tt.session, tt.window)
+		if !ok || got != tt.want {
+			t.Errorf("nextAgentWindow(%s:%s) = %v, %v; want %v", tt.session, tt.window, got, ok, tt.want)
+		}
+	}
+	if _, ok := nextAgentWindow(nil, snap, "ide-api", "agent"); ok {
+		t.Error("nextAgentWindow with no agents reported ok")
+	}
+}
+
+func TestIsAgentWindowMatchesAliasesWithArguments(t *testing.T) {
+	agents, err := agentstatus.NewRegistry(config.Agents{Tools: []config.AgentTool{{Name: "aider", Aliases: []string{"python -m aider"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := config.Environment{Name: "api", Windows: []config.WindowTemplate{{Name: "pair", Cmd: "python -m aider --model x"}, {Name: "serve", Cmd: "python -m http.server"}}}
+	if !isAgentWindow(agents, env, "pair", "python3") {
+		t.Error("window running python -m aider is not an agent window")
+	}
+	if isAgentWindow(agents, env, "serve", "python3") {
+		t.Error("window running python -m http.server is an agent window")
+	}
+}
+
+func TestPrintAgentStats(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tl := agentstatus.Timeline{
+		{At: t0, To: agentstatus.StatusCooking},
+		{At: t0.Add(40 * time.Minute), From: agentstatus.StatusCooking, To: agentstatus.StatusAwaitingInput},
+		{At: t0.Add(50 * time.Minute), From: agentstatus.StatusAwaitingInput, To: agentstatus.StatusCooking},
+		{At: t0.Add(70 * time.Minute), From: agentstatus.StatusCooking, To: agentstatus.StatusAwaitingInput},
+	}
+	var buf bytes.Buffer
+	printAgentStats(&buf, "api / claude", tl, true, t0, t0.Add(82*time.Minute))
+	want := "api / claude\n" +
+		"  cooking\t1h00m\t2x\n" +
+		"  awaiting_input\t22m\t2x\n" +
+		"  now\tawaiting_input for 12m\n"
+	if buf.String() != want {
+		t.Errorf("stats =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	// The window is gone and nobody closed its timeline: the last wait
+	// ends where the record does.
+	buf.Reset()
+	printAgentStats(&buf, "api / claude", tl, false, t0, t0.Add(82*time.Minute))
+	want = "api / claude\n" +
+		"  cooking\t1h00m\t2x\n" +
+		"  awaiting_input\t10m\t2x\n" +
+		"  idle\t12m\t1x\n" +
+		"  now\twindow gone\n"
+	if buf.String() != want {
+		t.Errorf("stats of a gone window =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"ide/internal/config"
+	"ide/internal/tmux"
+)
+
+const attachUsage = "usage: ide attach <env> [window]"
+
+// dispatchAttach starts env's session if it is not running, creates the
+// window first if it is lazy, and attaches to it: switch-client from inside
+// tmux, attach-session from a plain terminal.
+func dispatchAttach(args []string) int {
+	fs := newFlagSet("attach")
+	if err := fs.parse(args); err != nil {
+		return parseUsagef(os.Stderr, err, attachUsage)
+	}
+	pos := fs.positional()
+	if len(pos) < 1 || len(pos) > 2 {
+		return usagef(os.Stderr, attachUsage)
+	}
+	envs, err := config.Load()
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	idx := findEnv(envs, pos[0])
+	if idx < 0 {
+		return errf(os.Stderr, "no such environment %q", pos[0])
+	}
+	env := envs[idx]
+	// Building a session logs every tmux step; like the TUI, keep that out
+	// of the terminal unless DEBUG is set.
+	if os.Getenv("DEBUG") == "" {
+		log.SetOutput(io.Discard)
+	}
+	window := ""
+	if len(pos) == 2 {
+		window = pos[1]
+		if tmpl, ok := tmux.FindWindowTemplate(env, window); ok {
+			window = tmpl.Name
+		}
+	}
+	target, err := prepareAttach(env, window)
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	if os.Getenv("TMUX") != "" {
+		err = tmux.SwitchClient(target)
+	} else {
+		err = tmux.AttachSession(target)
+	}
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	return 0
+}
+
+// prepareAttach brings up env's session and window and returns the target
+// to attach to, like the TUI does on enter.
+func prepareAttach(env config.Environment, window string) (string, error) {
+	if err := tmux.CheckTmuxExists(); err != nil {
+		return "", err
+	}
+	if err := tmux.EnsureSession(env); err != nil {
+		return "", err
+	}
+	if err := tmux.EnsureWindow(env, window); err != nil {
+		return "", err
+	}
+	target := tmux.AttachTarget(env, window)
+	if window == "" {
+		return target, nil
+	}
+	session := tmux.SessionName(env.Name)
+	has, err := tmux.HasWindow(session, window)
+	if err != nil {
+		return "", err
+	}
+	if !has {
+		return "", fmt.Errorf("no window %q in session %q", tmux.SafeWindowName(window), session)
+	}
+	if err := tmux.SelectWindow(target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
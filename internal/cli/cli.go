@@ -1,5 +1,7 @@
 // Package cli implements the non-TUI subcommands: CRUD over environments,
-// templates, and windows in ~/.config/ide/environments.json.
+// templates, and windows in ~/.config/ide/environments.json, plus commands
+// that act on running sessions (send, status, attach, agent), configure
+// notifications (notify) and manage the tmux key bindings (tmux).
 package cli
 
 import (
@@ -13,20 +15,35 @@ import (
 var Subcommands = map[string]bool{
 	"env":      true,
 	"template": true,
+	"send":     true,
+	"status":   true,
+	"tmux":     true,
+	"agent":    true,
+	"attach":   true,
+	"notify":   true,
 }
 
 const Usage = `CLI commands (read/modify ~/.config/ide/environments.json):
 
   ide env list
   ide env show <name>
-  ide env add <name> [--root PATH] [--db CONN] [--folder NAME] [--template NAME]
-  ide env set <name> [--root PATH] [--db CONN] [--folder NAME]
+  ide env add <name> [--root PATH] [--db CONN] [--folder NAME] [--template NAME] [env options]
+  ide env set <name> [--root PATH] [--db CONN] [--folder NAME] [env options]
   ide env rename <old> <new>
   ide env rm <name>
+  ide env gc <name> [--dry-run]             (terminate processes that outlived the session)
+
+  env options (add/set):
+    --stop-timeout DUR                      graceful stop wait before killing (default 10s)
+    --tmux-option NAME=VALUE                tmux option for the session or all its windows
+                                            (repeatable; NAME= removes)
+    --wrapper CMD                           run every window inside CMD: a prefix ("nix develop -c")
+                                            or a {cmd} placeholder ("docker compose exec app sh -c {cmd}")
+    --mute[=false]                          no notifications about this environment's agents
 
   ide env window list <env>
-  ide env window add <env> <window> [--cmd CMD] [--cwd CWD]
-  ide env window set <env> <window> [--name NEW] [--cmd CMD] [--cwd CWD]
+  ide env window add <env> <window> [--cmd CMD] [--cwd CWD] [window options]
+  ide env window set <env> <window> [--name NEW] [--cmd CMD] [--cwd CWD] [window options]
   ide env window rm <env> <window>
 
   ide template list
@@ -36,9 +53,67 @@ const Usage = `CLI commands (read/modify ~/.config/ide/environments.json):
   ide template rm <name>
 
   ide template window list <template>
-  ide template window add <template> <window> [--cmd CMD] [--cwd CWD]
-  ide template window set <template> <window> [--name NEW] [--cmd CMD] [--cwd CWD]
+  ide template window add <template> <window> [--cmd CMD] [--cwd CWD] [window options]
+  ide template window set <template> <window> [--name NEW] [--cmd CMD] [--cwd CWD] [window options]
   ide template window rm <template> <window>
+
+  window options (add/set):
+    --restart never|on-failure|always       re-run Cmd when it exits
+    --max-retries N                         consecutive restarts before giving up (0 = unlimited)
+    --restart-backoff DUR                   first restart delay, doubled per retry (default 1s)
+    --stop-cmd CMD                          typed into the window on stop instead of C-c
+    --wrapper CMD|none                      override the environment's wrapper for this window
+    --shell SHELL                           run cmd and the fallback shell in SHELL instead of $SHELL
+    --keep-shell[=false]                    =false ends the window with cmd instead of dropping into a
+                                            shell (it closes, or stays dead under remain-on-exit)
+    --start-mode wrap|send-keys             run cmd under a wrapper (default) or type it into a plain
+                                            shell so it lands in the shell history
+    --tmux-option NAME=VALUE                tmux window option, overriding the environment's
+                                            (repeatable; NAME= removes)
+    --lazy[=false]                          create the window on first attach, not with the session
+    --when KIND=VALUE                       only create the window if the condition holds: file (glob
+                                            under root), bin (on PATH), env (set) or shell (exits 0)
+                                            (repeatable; KIND= removes)
+
+  ide send <env> <window> [--enter] [--literal] [--] TEXT...
+  ide send <env> <window> --keys KEYS       (tmux key names, e.g. "C-c")
+  ide send <env> <window> [--enter] < FILE  (text from stdin)
+
+  ide status [env]                          (sessions, windows, exited commands)
+
+  ide attach <env> [window]                 start the session (and a lazy window) if needed
+                                            and attach, or switch to it inside tmux
+
+  ide tmux version                          installed tmux and the features ide uses with it
+  ide tmux bindings [binding options]       print the tmux.conf key bindings
+  ide tmux install [--file PATH] [--no-reload] [binding options]
+                                            write them into a managed block of tmux.conf
+                                            and reload running tmux servers
+
+  binding options:
+    --search-key KEY                        search popup, ide --search (default a)
+    --windows-key KEY                       window switcher popup, ide --windows (default w)
+    --next-agent-key KEY                    jump to the next agent window (default N)
+    --width W, --height H                   popup size, cells or percent (default 80%)
+    --exe PATH                              ide binary to run (default: this one)
+
+  ide agent next                            switch the tmux client to the next agent window
+  ide agent report --status STATUS [--message TEXT] [--ttl DUR]
+                                            record the status of the agent in $TMUX_PANE (cooking,
+                                            awaiting, approval, done or error) for the TUI, until it
+                                            expires (default 10m); for agent hooks
+  ide agent stats [env] [--since DUR]       time each agent spent cooking and waiting, and how often,
+                                            from the timelines the TUI records (default: last 24h)
+  ide agent tools                           the agent CLIs ide recognises: built-in ones plus the
+                                            "agents" section of the config, with aliases, screen
+                                            pattern pack and CPU thresholds
+
+  ide notify show                           configured notifiers and quiet hours
+  ide notify set [--via LIST] [--command CMD] [--quiet-hours HH:MM-HH:MM]
+                                            notify when an agent stops cooking, needs approval or
+                                            fails: LIST is comma-separated bell, osc9, osc777,
+                                            notify-send, tmux, command ("" turns notifications off)
+  ide notify test [env] [window]            send a test notification through the configured notifiers
 `
 
 // Dispatch routes a CLI subcommand. args is os.Args[1:]. Returns a process
@@ -53,6 +128,18 @@ func Dispatch(args []string) int {
 		return dispatchEnv(args[1:])
 	case "template":
 		return dispatchTemplate(args[1:])
+	case "send":
+		return dispatchSend(args[1:])
+	case "status":
+		return dispatchStatus(args[1:])
+	case "tmux":
+		return dispatchTmux(args[1:])
+	case "agent":
+		return dispatchAgent(args[1:])
+	case "attach":
+		return dispatchAttach(args[1:])
+	case "notify":
+		return dispatchNotify(args[1:])
 	}
 	fmt.Fprintf(os.Stderr, "ide: unknown subcommand %q\n\n%s", args[0], Usage)
 	return 2
@@ -3,14 +3,18 @@ package cli
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"ide/internal/config"
+	"ide/internal/tmux"
 )
 
 func dispatchEnv(args []string) int {
 	if len(args) == 0 {
-		return usagef(os.Stderr, "usage: ide env <list|show|add|set|rename|rm|window> ...")
+		return usagef(os.Stderr, "usage: ide env <list|show|add|set|rename|rm|gc|window> ...")
 	}
 	switch args[0] {
 	case "list", "ls":
@@ -25,6 +29,8 @@ func dispatchEnv(args []string) int {
 		return envRename(args[1:])
 	case "rm", "remove", "delete":
 		return envRm(args[1:])
+	case "gc":
+		return envGC(args[1:])
 	case "window", "windows":
 		return dispatchEnvWindow(args[1:])
 	}
@@ -70,9 +76,18 @@ func envShow(args []string) int {
 	fmt.Printf("root:   %s\n", emptyDash(e.Root))
 	fmt.Printf("folder: %s\n", emptyDash(e.Folder))
 	fmt.Printf("db:     %s\n", emptyDash(e.DBConnection))
+	fmt.Printf("stop:   %s\n", emptyDash(e.StopTimeout))
+	fmt.Printf("tmux:   %s\n", emptyDash(tmuxOptionList(e.TmuxOptions)))
+	fmt.Printf("wrap:   %s\n", emptyDash(e.Wrapper))
+	if e.Mute {
+		fmt.Printf("notify: muted\n")
+	}
 	fmt.Printf("windows (%d):\n", len(e.Windows))
 	for i, w := range e.Windows {
-		fmt.Printf("  %d. %s\tcmd=%q\tcwd=%q\n", i+1, w.Name, w.Cmd, w.Cwd)
+		fmt.Printf("  %d. %s\tcmd=%q\tcwd=%q%s\n", i+1, w.Name, w.Cmd, w.Cwd, windowOptionSummary(w))
+		if tmux.Wrapper(e, w) != "" {
+			fmt.Printf("     runs: %s\n", tmux.CommandLine(e, w))
+		}
 	}
 	return 0
 }
@@ -83,12 +98,13 @@ func envAdd(args []string) int {
 	db := fs.string("db", "database connection string")
 	folder := fs.string("folder", "display folder/group")
 	template := fs.string("template", "template name to seed windows from")
+	opts := addEnvOptionFlags(fs)
 	if err := fs.parse(args); err != nil {
-		return parseUsagef(os.Stderr, err, "usage: ide env add <name> [--root PATH] [--db CONN] [--folder NAME] [--template NAME]")
+		return parseUsagef(os.Stderr, err, "usage: ide env add <name> [--root PATH] [--db CONN] [--folder NAME] [--template NAME] [env options]")
 	}
 	pos := fs.positional()
 	if len(pos) != 1 {
-		return usagef(os.Stderr, "usage: ide env add <name> [--root PATH] [--db CONN] [--folder NAME] [--template NAME]")
+		return usagef(os.Stderr, "usage: ide env add <name> [--root PATH] [--db CONN] [--folder NAME] [--template NAME] [env options]")
 	}
 	name := trim(pos[0])
 	if name == "" {
@@ -109,6 +125,9 @@ func envAdd(args []string) int {
 		Folder:       trim(*folder),
 		DBConnection: trim(*db),
 	}
+	if err := opts.apply(fs, &env); err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
 
 	if t := trim(*template); t != "" {
 		tIdx := findTemplate(data.Templates, t)
@@ -131,12 +150,13 @@ func envSet(args []string) int {
 	root := fs.string("root", "filesystem root")
 	db := fs.string("db", "database connection string")
 	folder := fs.string("folder", "display folder/group")
+	opts := addEnvOptionFlags(fs)
 	if err := fs.parse(args); err != nil {
-		return parseUsagef(os.Stderr, err, "usage: ide env set <name> [--root PATH] [--db CONN] [--folder NAME]")
+		return parseUsagef(os.Stderr, err, "usage: ide env set <name> [--root PATH] [--db CONN] [--folder NAME] [env options]")
 	}
 	pos := fs.positional()
 	if len(pos) != 1 {
-		return usagef(os.Stderr, "usage: ide env set <name> [--root PATH] [--db CONN] [--folder NAME]")
+		return usagef(os.Stderr, "usage: ide env set <name> [--root PATH] [--db CONN] [--folder NAME] [env options]")
 	}
 	name := pos[0]
 
@@ -157,6 +177,9 @@ func envSet(args []string) int {
 	if fs.provided("folder") {
 		envs[idx].Folder = trim(*folder)
 	}
+	if err := opts.apply(fs, &envs[idx]); err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
 	if err := config.Save(envs); err != nil {
 		return errf(os.Stderr, "%v", err)
 	}
@@ -164,6 +187,95 @@ func envSet(args []string) int {
 	return 0
 }
 
+// envOptionFlags are the environment settings shared by `env add` and
+// `env set`.
+type envOptionFlags struct {
+	stopTimeout *string
+	tmuxOptions *[]string
+	wrapper     *string
+	mute        *bool
+}
+
+func addEnvOptionFlags(fs *flagSet) envOptionFlags {
+	return envOptionFlags{
+		stopTimeout: fs.string("stop-timeout", "how long a graceful stop waits before killing (e.g. 30s)"),
+		tmuxOptions: fs.list("tmux-option", "NAME=VALUE tmux option for the session (repeatable; NAME= removes)"),
+		wrapper:     fs.string("wrapper", `run every window inside it, e.g. "nix develop -c" or "docker compose exec app sh -c {cmd}"`),
+		mute:        fs.bool("mute", "no notifications about this environment's agents (=false unmutes)"),
+	}
+}
+
+// apply copies the provided options onto env and validates them.
+func (o envOptionFlags) apply(fs *flagSet, env *config.Environment) error {
+	if fs.provided("stop-timeout") {
+		v := trim(*o.stopTimeout)
+		if v != "" {
+			if d, err := time.ParseDuration(v); err != nil || d < 0 {
+				return fmt.Errorf("--stop-timeout: %q is not a duration like 30s", v)
+			}
+		}
+		env.StopTimeout = v
+	}
+	if fs.provided("wrapper") {
+		env.Wrapper = trim(*o.wrapper)
+	}
+	if fs.provided("mute") {
+		env.Mute = *o.mute
+	}
+	if fs.provided("tmux-option") {
+		opts, err := applyTmuxOptions(env.TmuxOptions, *o.tmuxOptions, false)
+		if err != nil {
+			return err
+		}
+		env.TmuxOptions = opts
+	}
+	return nil
+}
+
+// applyTmuxOptions merges NAME=VALUE entries into opts, validating each
+// against tmux's known options; "NAME=" removes NAME. opts is not modified.
+func applyTmuxOptions(opts map[string]string, entries []string, forWindow bool) (map[string]string, error) {
+	out := make(map[string]string, len(opts)+len(entries))
+	for name, value := range opts {
+		out[name] = value
+	}
+	for _, e := range entries {
+		name, value, ok := strings.Cut(e, "=")
+		name, value = strings.ToLower(trim(name)), trim(value)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("--tmux-option: %q is not NAME=VALUE", e)
+		}
+		if value == "" {
+			delete(out, name)
+			continue
+		}
+		if err := tmux.ValidateOption(name, value, forWindow); err != nil {
+			return nil, fmt.Errorf("--tmux-option: %w", err)
+		}
+		out[name] = value
+	}
+	return out, nil
+}
+
+// tmuxOptionList renders opts as space-separated NAME=VALUE pairs in name
+// order, quoting values that need it.
+func tmuxOptionList(opts map[string]string) string {
+	names := make([]string, 0, len(opts))
+	for name := range opts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		value := opts[name]
+		if value == "" || strings.ContainsAny(value, " \t\"'") {
+			value = strconv.Quote(value)
+		}
+		parts[i] = name + "=" + value
+	}
+	return strings.Join(parts, " ")
+}
+
 func envRename(args []string) int {
 	if len(args) != 2 {
 		return usagef(os.Stderr, "usage: ide env rename <old> <new>")
@@ -27,29 +27,50 @@ func (p *parseError) Unwrap() error { return p.err }
 // a pre-parser that lets positionals appear anywhere in the argv (stdlib
 // flag stops at the first positional).
 type flagSet struct {
-	fs       *flag.FlagSet
-	seen     map[string]bool
-	posArgs  []string
-	knownVal map[string]bool // names of flags that take a value
-	sink     *stderrSink
+	fs      *flag.FlagSet
+	seen    map[string]bool
+	posArgs []string
+	known   map[string]bool // flag name -> whether it takes a value (false = boolean)
+	sink    *stderrSink
 }
 
 func newFlagSet(name string) *flagSet {
 	fs := flag.NewFlagSet(name, flag.ContinueOnError)
 	sink := &stderrSink{}
 	fs.SetOutput(sink)
-	return &flagSet{fs: fs, knownVal: map[string]bool{}, sink: sink}
+	return &flagSet{fs: fs, known: map[string]bool{}, sink: sink}
 }
 
 func (f *flagSet) string(name, usage string) *string {
-	f.knownVal[name] = true
+	f.known[name] = true
 	return f.fs.String(name, "", usage)
 }
 
+// bool registers a switch. Switches never consume the following argument,
+// so `--enter hello` leaves "hello" as a positional.
+func (f *flagSet) bool(name, usage string) *bool {
+	f.known[name] = false
+	return f.fs.Bool(name, false, usage)
+}
+
+// list registers a repeatable flag; every occurrence is appended in order.
+func (f *flagSet) list(name, usage string) *[]string {
+	f.known[name] = true
+	var vals listValue
+	f.fs.Var(&vals, name, usage)
+	return (*[]string)(&vals)
+}
+
+// listValue is the flag.Value behind flagSet.list.
+type listValue []string
+
+func (l *listValue) String() string     { return strings.Join(*l, ",") }
+func (l *listValue) Set(v string) error { *l = append(*l, v); return nil }
+
 // parse splits args into flag tokens and positional tokens, then runs
 // flag.Parse on the flag tokens. Positionals can appear anywhere in argv.
 func (f *flagSet) parse(args []string) error {
-	flagToks, pos, err := splitArgs(args, f.knownVal)
+	flagToks, pos, err := splitArgs(args, f.known)
 	if err != nil {
 		return &parseError{err: err}
 	}
@@ -66,10 +87,12 @@ func (f *flagSet) provided(name string) bool { return f.seen[name] }
 func (f *flagSet) positional() []string      { return f.posArgs }
 
 // splitArgs walks args once and partitions them into flag tokens (to feed
-// flag.FlagSet) vs positional tokens. Recognised forms:
+// flag.FlagSet) vs positional tokens. known maps each flag name to whether
+// it takes a value. Recognised forms:
 //
 //	--name=value   -> single flag token
-//	--name value   -> two flag tokens (only when "name" is in known)
+//	--name value   -> two flag tokens (only when "name" takes a value)
+//	--name         -> single flag token (only when "name" is a boolean)
 //	--             -> end of flags; rest is positional
 //	anything else  -> positional
 func splitArgs(args []string, known map[string]bool) (flagToks, pos []string, err error) {
@@ -86,10 +109,11 @@ func splitArgs(args []string, known map[string]bool) (flagToks, pos []string, er
 		// strip leading dashes
 		body := strings.TrimLeft(a, "-")
 		name, _, hasEq := strings.Cut(body, "=")
-		if !known[name] {
+		takesValue, ok := known[name]
+		if !ok {
 			return nil, nil, fmt.Errorf("unknown flag --%s", name)
 		}
-		if hasEq {
+		if hasEq || !takesValue {
 			flagToks = append(flagToks, a)
 			continue
 		}
@@ -47,3 +47,50 @@ func TestParseSuccess(t *testing.T) {
 		t.Errorf("positionals = %v, want [envname]", got)
 	}
 }
+
+func TestParseBoolFlagDoesNotConsumeNext(t *testing.T) {
+	fs := newFlagSet("test")
+	enter := fs.bool("enter", "press enter")
+	keys := fs.string("keys", "key names")
+	if err := fs.parse([]string{"env", "--enter", "win", "--keys", "C-c"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !*enter {
+		t.Error("enter = false, want true")
+	}
+	if *keys != "C-c" {
+		t.Errorf("keys = %q, want C-c", *keys)
+	}
+	if got := fs.positional(); len(got) != 2 || got[0] != "env" || got[1] != "win" {
+		t.Errorf("positionals = %v, want [env win]", got)
+	}
+}
+
+func TestParseDoubleDashKeepsFlagLikeText(t *testing.T) {
+	fs := newFlagSet("test")
+	fs.bool("enter", "press enter")
+	if err := fs.parse([]string{"env", "win", "--", "--enter", "-x"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fs.provided("enter") {
+		t.Error("--enter after -- must be positional, not a flag")
+	}
+	if got := fs.positional(); len(got) != 4 || got[2] != "--enter" || got[3] != "-x" {
+		t.Errorf("positionals = %v", got)
+	}
+}
+
+func TestParseListFlagRepeats(t *testing.T) {
+	fs := newFlagSet("test")
+	opts := fs.list("tmux-option", "NAME=VALUE")
+	if err := fs.parse([]string{"--tmux-option", "mouse=on", "env", "--tmux-option=status-style=bg=blue"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"mouse=on", "status-style=bg=blue"}
+	if len(*opts) != 2 || (*opts)[0] != want[0] || (*opts)[1] != want[1] {
+		t.Errorf("tmux-option = %q, want %q", *opts, want)
+	}
+	if !fs.provided("tmux-option") {
+		t.Error("provided(tmux-option) = false, want true")
+	}
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"ide/internal/config"
+	"ide/internal/tmux"
+)
+
+const gcUsage = "usage: ide env gc <name> [--dry-run]"
+
+// envGC terminates processes that outlived a killed session of the named
+// environment. The environment does not have to exist any more: deleting
+// it is one of the ways its session gets killed.
+func envGC(args []string) int {
+	fs := newFlagSet("env gc")
+	dryRun := fs.bool("dry-run", "list the orphans without terminating them")
+	if err := fs.parse(args); err != nil {
+		return parseUsagef(os.Stderr, err, gcUsage)
+	}
+	pos := fs.positional()
+	if len(pos) != 1 {
+		return usagef(os.Stderr, gcUsage)
+	}
+	name := pos[0]
+	if envs, err := config.Load(); err == nil {
+		if idx := findEnv(envs, name); idx >= 0 {
+			name = envs[idx].Name
+		}
+	}
+	session := tmux.SessionName(name)
+
+	orphans, err := tmux.Orphans(session)
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	if len(orphans) == 0 {
+		fmt.Printf("no orphaned processes for %q\n", name)
+		return 0
+	}
+	for _, p := range orphans {
+		fmt.Printf("%d\t%s\t%s\n", p.PID, p.Command, p.Window)
+	}
+	if *dryRun {
+		return 0
+	}
+	remaining, err := tmux.TerminateOrphans(session, tmux.OrphanGrace)
+	if err != nil {
+		for _, p := range remaining {
+			fmt.Fprintf(os.Stderr, "still alive: %d\t%s\n", p.PID, p.Command)
+		}
+		return errf(os.Stderr, "%v", err)
+	}
+	fmt.Printf("terminated %d orphaned processes of %q\n", len(orphans), name)
+	return 0
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"ide/internal/agentstatus"
+	"ide/internal/config"
+	"ide/internal/notify"
+)
+
+func dispatchNotify(args []string) int {
+	if len(args) == 0 {
+		return usagef(os.Stderr, "usage: ide notify <show|set|test> ...")
+	}
+	switch args[0] {
+	case "show":
+		return notifyShow(args[1:])
+	case "set":
+		return notifySet(args[1:])
+	case "test":
+		return notifyTest(args[1:])
+	}
+	return usagef(os.Stderr, "ide notify: unknown subcommand %q", args[0])
+}
+
+func notifyShow(args []string) int {
+	if len(args) != 0 {
+		return usagef(os.Stderr, "usage: ide notify show")
+	}
+	data, err := config.LoadAll()
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	n := data.Notify
+	fmt.Printf("via:     %s\n", emptyDash(strings.Join(n.Via, ", ")))
+	fmt.Printf("command: %s\n", emptyDash(n.Command))
+	fmt.Printf("quiet:   %s\n", emptyDash(n.QuietHours))
+	var muted []string
+	for _, e := range data.Environments {
+		if e.Mute {
+			muted = append(muted, e.Name)
+		}
+	}
+	fmt.Printf("muted:   %s\n", emptyDash(strings.Join(muted, ", ")))
+	return 0
+}
+
+const notifySetUsage = "usage: ide notify set [--via LIST] [--command CMD] [--quiet-hours HH:MM-HH:MM]"
+
+func notifySet(args []string) int {
+	fs := newFlagSet("notify set")
+	via := fs.string("via", "comma-separated notifiers: "+strings.Join(notify.Via, ", "))
+	command := fs.string("command", "run by the command notifier; {env}, {session}, {window}, {status} and {message} are substituted")
+	quiet := fs.string("quiet-hours", `no notifications in this local-time range, e.g. "22:00-07:00" ("" clears)`)
+	if err := fs.parse(args); err != nil {
+		return parseUsagef(os.Stderr, err, notifySetUsage)
+	}
+	if len(fs.positional()) != 0 {
+		return usagef(os.Stderr, notifySetUsage)
+	}
+	data, err := config.LoadAll()
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	n := data.Notify
+	if fs.provided("via") {
+		n.Via = nil
+		for _, v := range strings.Split(*via, ",") {
+			if v = strings.ToLower(trim(v)); v != "" {
+				n.Via = append(n.Via, v)
+			}
+		}
+	}
+	if fs.provided("command") {
+		n.Command = trim(*command)
+	}
+	if fs.provided("quiet-hours") {
+		n.QuietHours = trim(*quiet)
+	}
+	// Building the notifier validates the names, the command and the quiet
+	// hours together, e.g. "command" without --command.
+	if _, err := notify.New(n, os.Stdout); err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	if err := config.SaveNotify(n); err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	fmt.Println("updated notifications")
+	return 0
+}
+
+// notifyTest sends a made-up "waiting for input" event through the
+// configured notifiers, ignoring quiet hours and mutes: it is run to see
+// what a notification looks like.
+func notifyTest(args []string) int {
+	if len(args) > 2 {
+		return usagef(os.Stderr, "usage: ide notify test [env] [window]")
+	}
+	data, err := config.LoadAll()
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	cfg := data.Notify
+	if len(cfg.Via) == 0 {
+		return errf(os.Stderr, "no notifiers configured: ide notify set --via bell,tmux")
+	}
+	cfg.QuietHours = ""
+	n, err := notify.New(cfg, os.Stdout)
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	ev := notify.Event{
+		Env:     "ide",
+		Window:  "test",
+		From:    agentstatus.StatusCooking,
+		To:      agentstatus.StatusAwaitingInput,
+		Message: "test notification",
+		At:      time.Now(),
+	}
+	if len(args) > 0 {
+		ev.Env = args[0]
+	}
+	if len(args) > 1 {
+		ev.Window = args[1]
+	}
+	if err := n.Notify(ev); err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	return 0
+}
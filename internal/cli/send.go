@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"ide/internal/config"
+	"ide/internal/tmux"
+)
+
+const sendUsage = "usage: ide send <env> <window> [--enter] [--literal] [--keys KEYS] [--] [TEXT...]"
+
+// dispatchSend types into a window of a running environment. Text comes
+// from the trailing positionals, or from stdin when there are none (or the
+// only one is "-"). --keys sends tmux key names instead of text.
+func dispatchSend(args []string) int {
+	fs := newFlagSet("send")
+	enter := fs.bool("enter", "press Enter after the text")
+	literal := fs.bool("literal", "type text verbatim, never as key names")
+	keys := fs.string("keys", "space-separated tmux key names, e.g. \"C-c\"")
+	if err := fs.parse(args); err != nil {
+		return parseUsagef(os.Stderr, err, sendUsage)
+	}
+	pos := fs.positional()
+	if len(pos) < 2 {
+		return usagef(os.Stderr, sendUsage)
+	}
+	envName, winName, text := pos[0], pos[1], pos[2:]
+	if fs.provided("keys") && len(text) > 0 {
+		return errf(os.Stderr, "--keys and TEXT are mutually exclusive")
+	}
+
+	envs, err := config.Load()
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	idx := findEnv(envs, envName)
+	if idx < 0 {
+		return errf(os.Stderr, "no such environment %q", envName)
+	}
+	target, err := resolveLiveWindow(envs[idx], winName)
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+
+	if fs.provided("keys") {
+		names := strings.Fields(*keys)
+		if len(names) == 0 {
+			return errf(os.Stderr, "--keys cannot be empty")
+		}
+		if err := tmux.SendKeys(target, false, names...); err != nil {
+			return errf(os.Stderr, "%v", err)
+		}
+	} else {
+		body := strings.Join(text, " ")
+		if len(text) == 0 || (len(text) == 1 && text[0] == "-") {
+			b, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return errf(os.Stderr, "read stdin: %v", err)
+			}
+			body = strings.TrimRight(string(b), "\n")
+		}
+		if body != "" {
+			if err := tmux.SendKeys(target, *literal, body); err != nil {
+				return errf(os.Stderr, "%v", err)
+			}
+		}
+	}
+	if *enter {
+		if err := tmux.SendKeys(target, false, "Enter"); err != nil {
+			return errf(os.Stderr, "%v", err)
+		}
+	}
+	return 0
+}
+
+// resolveLiveWindow maps a window name to a tmux target in env's running
+// session, resolving names the same way the TUI does (config name or its
+// sanitized tmux form). Windows that exist only in tmux are accepted too.
+func resolveLiveWindow(env config.Environment, name string) (string, error) {
+	session := tmux.SessionName(env.Name)
+	has, err := tmux.HasSession(session)
+	if err != nil {
+		return "", err
+	}
+	if !has {
+		return "", fmt.Errorf("session %q is not running", session)
+	}
+	window := name
+	if tmpl, ok := tmux.FindWindowTemplate(env, name); ok {
+		window = tmpl.Name
+	}
+	hasWindow, err := tmux.HasWindow(session, window)
+	if err != nil {
+		return "", err
+	}
+	if !hasWindow && tmux.IsLazyWindow(env, window) {
+		return "", fmt.Errorf("window %q is lazy and not created yet; attach to it first", window)
+	}
+	if !hasWindow {
+		return "", fmt.Errorf("no window %q in session %q", tmux.SafeWindowName(window), session)
+	}
+	return tmux.AttachTarget(env, window), nil
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"ide/internal/config"
+	"ide/internal/tmux"
+)
+
+const statusUsage = "usage: ide status [env]"
+
+// dispatchStatus prints whether each environment's session is up and, for
+// running sessions, what every window is doing — including startup
+// commands that have exited.
+func dispatchStatus(args []string) int {
+	if len(args) > 1 {
+		return usagef(os.Stderr, statusUsage)
+	}
+	envs, err := config.Load()
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	if len(args) == 1 {
+		idx := findEnv(envs, args[0])
+		if idx < 0 {
+			return errf(os.Stderr, "no such environment %q", args[0])
+		}
+		envs = envs[idx : idx+1]
+	}
+	if len(envs) == 0 {
+		fmt.Println("(no environments)")
+		return 0
+	}
+	snap, err := tmux.ListSessionsSnapshot()
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	for _, e := range envs {
+		printEnvStatus(e, snap)
+	}
+	return 0
+}
+
+func printEnvStatus(e config.Environment, snap tmux.SessionsSnapshot) {
+	session := tmux.SessionName(e.Name)
+	windows, running := snap.Windows[session]
+	if !running {
+		fmt.Printf("%s\tdown\n", e.Name)
+		return
+	}
+	fmt.Printf("%s\tup\n", e.Name)
+	skipped := tmux.SkippedWindows(e)
+	for _, w := range tmux.WithLazyWindows(tmux.WithoutWindows(e, skipped), windows) {
+		state := emptyDash(snap.Commands[session][w])
+		if code, ok := snap.Exits[session][w]; ok {
+			state = tmux.ExitLabel(code)
+		} else if !slices.Contains(windows, w) {
+			state = "lazy, not started"
+		}
+		fmt.Printf("  %s\t%s\n", w, state)
+	}
+	for _, w := range e.Windows {
+		name := tmux.SafeWindowName(w.Name)
+		if reason, ok := skipped[name]; ok && !slices.Contains(windows, name) {
+			fmt.Printf("  %s\tskipped (%s)\n", name, reason)
+		}
+	}
+}
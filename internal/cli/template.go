@@ -63,7 +63,7 @@ func templateShow(args []string) int {
 	fmt.Printf("name: %s\n", t.Name)
 	fmt.Printf("windows (%d):\n", len(t.Windows))
 	for i, w := range t.Windows {
-		fmt.Printf("  %d. %s\tcmd=%q\tcwd=%q\n", i+1, w.Name, w.Cmd, w.Cwd)
+		fmt.Printf("  %d. %s\tcmd=%q\tcwd=%q%s\n", i+1, w.Name, w.Cmd, w.Cwd, windowOptionSummary(w))
 	}
 	return 0
 }
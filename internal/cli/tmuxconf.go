@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"ide/internal/tmux"
+)
+
+const (
+	tmuxBindingsUsage = "usage: ide tmux bindings [binding options]"
+	tmuxInstallUsage  = "usage: ide tmux install [--file PATH] [--no-reload] [binding options]"
+)
+
+func dispatchTmux(args []string) int {
+	if len(args) == 0 {
+		return usagef(os.Stderr, "usage: ide tmux <version|bindings|install> ...")
+	}
+	switch args[0] {
+	case "version":
+		return tmuxVersion(args[1:])
+	case "bindings":
+		return tmuxBindings(args[1:])
+	case "install":
+		return tmuxInstall(args[1:])
+	}
+	return usagef(os.Stderr, "ide tmux: unknown subcommand %q", args[0])
+}
+
+// tmuxVersion prints the installed tmux version and which version-gated
+// features ide will use with it.
+func tmuxVersion(args []string) int {
+	if len(args) != 0 {
+		return usagef(os.Stderr, "usage: ide tmux version")
+	}
+	if err := tmux.CheckTmuxExists(); err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	caps, err := tmux.DetectCapabilities()
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	fmt.Printf("tmux %s\n", caps.Version)
+	fmt.Printf("  supported      %s (needs %s+)\n", yesNo(caps.Supported()), tmux.MinVersion)
+	fmt.Printf("  display-popup  %s\n", yesNo(caps.Popup))
+	fmt.Printf("  new-window -b  %s\n", yesNo(caps.NewWindowBefore))
+	if w := caps.Warning(); w != "" {
+		fmt.Println(w)
+	}
+	return 0
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// bindingFlags are the options shared by `tmux bindings` and `tmux install`.
+type bindingFlags struct {
+	exe, searchKey, windowsKey, nextAgentKey, width, height *string
+}
+
+func addBindingFlags(fs *flagSet) bindingFlags {
+	return bindingFlags{
+		exe:          fs.string("exe", "ide binary the bindings run (default: this one)"),
+		searchKey:    fs.string("search-key", "prefix key for the search popup (default a; empty disables)"),
+		windowsKey:   fs.string("windows-key", "prefix key for the window switcher popup (default w; empty disables)"),
+		nextAgentKey: fs.string("next-agent-key", "prefix key for jumping to the next agent window (default N; empty disables)"),
+		width:        fs.string("width", "popup width, cells or percent (default 80%)"),
+		height:       fs.string("height", "popup height, cells or percent (default 80%)"),
+	}
+}
+
+// options overlays the provided flags on tmux.DefaultBindingOptions.
+func (b bindingFlags) options(fs *flagSet) tmux.BindingOptions {
+	opts := tmux.DefaultBindingOptions()
+	set := func(name string, dst *string, v *string) {
+		if fs.provided(name) {
+			*dst = trim(*v)
+		}
+	}
+	set("exe", &opts.Exe, b.exe)
+	set("search-key", &opts.SearchKey, b.searchKey)
+	set("windows-key", &opts.WindowsKey, b.windowsKey)
+	set("next-agent-key", &opts.NextAgentKey, b.nextAgentKey)
+	set("width", &opts.PopupWidth, b.width)
+	set("height", &opts.PopupHeight, b.height)
+	return opts
+}
+
+// tmuxBindings prints the tmux.conf snippet, for users who manage their
+// config by hand.
+func tmuxBindings(args []string) int {
+	fs := newFlagSet("tmux bindings")
+	bf := addBindingFlags(fs)
+	if err := fs.parse(args); err != nil {
+		return parseUsagef(os.Stderr, err, tmuxBindingsUsage)
+	}
+	if len(fs.positional()) != 0 {
+		return usagef(os.Stderr, tmuxBindingsUsage)
+	}
+	fmt.Print(tmux.BindingsBlock(bf.options(fs)))
+	return 0
+}
+
+// tmuxInstall writes the bindings into the managed block of tmux.conf and
+// sources the file into every running tmux server.
+func tmuxInstall(args []string) int {
+	fs := newFlagSet("tmux install")
+	file := fs.string("file", "tmux.conf to manage (default ~/.tmux.conf or ~/.config/tmux/tmux.conf)")
+	noReload := fs.bool("no-reload", "do not source the file into running tmux servers")
+	bf := addBindingFlags(fs)
+	if err := fs.parse(args); err != nil {
+		return parseUsagef(os.Stderr, err, tmuxInstallUsage)
+	}
+	if len(fs.positional()) != 0 {
+		return usagef(os.Stderr, tmuxInstallUsage)
+	}
+	path := trim(*file)
+	if path == "" {
+		var err error
+		if path, err = tmux.ConfPath(); err != nil {
+			return errf(os.Stderr, "%v", err)
+		}
+	}
+	_, statErr := os.Stat(path)
+	changed, err := tmux.InstallBindings(path, bf.options(fs))
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	if changed {
+		fmt.Printf("updated %s\n", path)
+		if statErr == nil {
+			fmt.Printf("previous version saved as %s\n", tmux.BackupPath(path))
+		}
+	} else {
+		fmt.Printf("%s is up to date\n", path)
+	}
+	if *noReload {
+		return 0
+	}
+	n, err := tmux.ReloadServers(path)
+	if err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	fmt.Printf("reloaded %d tmux servers\n", n)
+	return 0
+}
@@ -3,9 +3,11 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"ide/internal/config"
+	"ide/internal/supervisor"
 )
 
 func dispatchEnvWindow(args []string) int {
@@ -61,14 +63,15 @@ func envWindowList(args []string) int {
 
 func envWindowAdd(args []string) int {
 	fs := newFlagSet("env window add")
+	opts := addWindowOptionFlags(fs)
 	cmd := fs.string("cmd", "startup command")
 	cwd := fs.string("cwd", "working directory (relative to env root)")
 	if err := fs.parse(args); err != nil {
-		return parseUsagef(os.Stderr, err, "usage: ide env window add <env> <window> [--cmd CMD] [--cwd CWD]")
+		return parseUsagef(os.Stderr, err, "usage: ide env window add <env> <window> [--cmd CMD] [--cwd CWD] [window options]")
 	}
 	pos := fs.positional()
 	if len(pos) != 2 {
-		return usagef(os.Stderr, "usage: ide env window add <env> <window> [--cmd CMD] [--cwd CWD]")
+		return usagef(os.Stderr, "usage: ide env window add <env> <window> [--cmd CMD] [--cwd CWD] [window options]")
 	}
 	envName, winName := pos[0], trim(pos[1])
 	if winName == "" {
@@ -85,11 +88,15 @@ func envWindowAdd(args []string) int {
 	if findWindow(envs[idx].Windows, winName) >= 0 {
 		return errf(os.Stderr, "window %q already exists in %q", winName, envs[idx].Name)
 	}
-	envs[idx].Windows = append(envs[idx].Windows, config.WindowTemplate{
+	w := config.WindowTemplate{
 		Name: winName,
 		Cmd:  trim(*cmd),
 		Cwd:  trim(*cwd),
-	})
+	}
+	if err := opts.apply(fs, &w); err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	envs[idx].Windows = append(envs[idx].Windows, w)
 	if err := config.Save(envs); err != nil {
 		return errf(os.Stderr, "%v", err)
 	}
@@ -99,15 +106,16 @@ func envWindowAdd(args []string) int {
 
 func envWindowSet(args []string) int {
 	fs := newFlagSet("env window set")
+	opts := addWindowOptionFlags(fs)
 	name := fs.string("name", "new window name")
 	cmd := fs.string("cmd", "startup command")
 	cwd := fs.string("cwd", "working directory")
 	if err := fs.parse(args); err != nil {
-		return parseUsagef(os.Stderr, err, "usage: ide env window set <env> <window> [--name NEW] [--cmd CMD] [--cwd CWD]")
+		return parseUsagef(os.Stderr, err, "usage: ide env window set <env> <window> [--name NEW] [--cmd CMD] [--cwd CWD] [window options]")
 	}
 	pos := fs.positional()
 	if len(pos) != 2 {
-		return usagef(os.Stderr, "usage: ide env window set <env> <window> [--name NEW] [--cmd CMD] [--cwd CWD]")
+		return usagef(os.Stderr, "usage: ide env window set <env> <window> [--name NEW] [--cmd CMD] [--cwd CWD] [window options]")
 	}
 	envName, winName := pos[0], pos[1]
 
@@ -139,6 +147,9 @@ func envWindowSet(args []string) int {
 	if fs.provided("cwd") {
 		envs[eIdx].Windows[wIdx].Cwd = trim(*cwd)
 	}
+	if err := opts.apply(fs, &envs[eIdx].Windows[wIdx]); err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
 	if err := config.Save(envs); err != nil {
 		return errf(os.Stderr, "%v", err)
 	}
@@ -189,14 +200,15 @@ func templateWindowList(args []string) int {
 
 func templateWindowAdd(args []string) int {
 	fs := newFlagSet("template window add")
+	opts := addWindowOptionFlags(fs)
 	cmd := fs.string("cmd", "startup command")
 	cwd := fs.string("cwd", "working directory")
 	if err := fs.parse(args); err != nil {
-		return parseUsagef(os.Stderr, err, "usage: ide template window add <template> <window> [--cmd CMD] [--cwd CWD]")
+		return parseUsagef(os.Stderr, err, "usage: ide template window add <template> <window> [--cmd CMD] [--cwd CWD] [window options]")
 	}
 	pos := fs.positional()
 	if len(pos) != 2 {
-		return usagef(os.Stderr, "usage: ide template window add <template> <window> [--cmd CMD] [--cwd CWD]")
+		return usagef(os.Stderr, "usage: ide template window add <template> <window> [--cmd CMD] [--cwd CWD] [window options]")
 	}
 	tName, winName := pos[0], trim(pos[1])
 	if winName == "" {
@@ -213,11 +225,15 @@ func templateWindowAdd(args []string) int {
 	if findWindow(templates[idx].Windows, winName) >= 0 {
 		return errf(os.Stderr, "window %q already exists in template %q", winName, templates[idx].Name)
 	}
-	templates[idx].Windows = append(templates[idx].Windows, config.WindowTemplate{
+	w := config.WindowTemplate{
 		Name: winName,
 		Cmd:  trim(*cmd),
 		Cwd:  trim(*cwd),
-	})
+	}
+	if err := opts.apply(fs, &w); err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
+	templates[idx].Windows = append(templates[idx].Windows, w)
 	if err := config.SaveTemplates(templates); err != nil {
 		return errf(os.Stderr, "%v", err)
 	}
@@ -227,15 +243,16 @@ func templateWindowAdd(args []string) int {
 
 func templateWindowSet(args []string) int {
 	fs := newFlagSet("template window set")
+	opts := addWindowOptionFlags(fs)
 	name := fs.string("name", "new window name")
 	cmd := fs.string("cmd", "startup command")
 	cwd := fs.string("cwd", "working directory")
 	if err := fs.parse(args); err != nil {
-		return parseUsagef(os.Stderr, err, "usage: ide template window set <template> <window> [--name NEW] [--cmd CMD] [--cwd CWD]")
+		return parseUsagef(os.Stderr, err, "usage: ide template window set <template> <window> [--name NEW] [--cmd CMD] [--cwd CWD] [window options]")
 	}
 	pos := fs.positional()
 	if len(pos) != 2 {
-		return usagef(os.Stderr, "usage: ide template window set <template> <window> [--name NEW] [--cmd CMD] [--cwd CWD]")
+		return usagef(os.Stderr, "usage: ide template window set <template> <window> [--name NEW] [--cmd CMD] [--cwd CWD] [window options]")
 	}
 	tName, winName := pos[0], pos[1]
 	templates, err := config.LoadTemplates()
@@ -266,6 +283,9 @@ func templateWindowSet(args []string) int {
 	if fs.provided("cwd") {
 		templates[tIdx].Windows[wIdx].Cwd = trim(*cwd)
 	}
+	if err := opts.apply(fs, &templates[tIdx].Windows[wIdx]); err != nil {
+		return errf(os.Stderr, "%v", err)
+	}
 	if err := config.SaveTemplates(templates); err != nil {
 		return errf(os.Stderr, "%v", err)
 	}
@@ -315,7 +335,165 @@ func printWindows(windows []config.WindowTemplate) int {
 		return 0
 	}
 	for i, w := range windows {
-		fmt.Printf("%d. %s\tcmd=%q\tcwd=%q\n", i+1, w.Name, w.Cmd, w.Cwd)
+		fmt.Printf("%d. %s\tcmd=%q\tcwd=%q%s\n", i+1, w.Name, w.Cmd, w.Cwd, windowOptionSummary(w))
 	}
 	return 0
 }
+
+// windowOptionFlags are the per-window settings beyond --cmd/--cwd, shared
+// by every `window add` and `window set` command.
+type windowOptionFlags struct {
+	restart    *string
+	maxRetries *string
+	backoff    *string
+	stopCmd    *string
+	tmuxOpts   *[]string
+	lazy       *bool
+	when       *[]string
+	startMode  *string
+	wrapper    *string
+	shell      *string
+	keepShell  *bool
+}
+
+func addWindowOptionFlags(fs *flagSet) windowOptionFlags {
+	return windowOptionFlags{
+		restart:    fs.string("restart", "restart policy: never, on-failure or always"),
+		maxRetries: fs.string("max-retries", "consecutive restarts before giving up (0 = unlimited)"),
+		backoff:    fs.string("restart-backoff", "first restart delay, doubled per retry (e.g. 2s)"),
+		stopCmd:    fs.string("stop-cmd", "typed into the window on stop instead of C-c"),
+		startMode:  fs.string("start-mode", "how cmd is started: wrap or send-keys"),
+		wrapper:    fs.string("wrapper", `overrides the environment's wrapper; "none" runs unwrapped`),
+		shell:      fs.string("shell", "shell to run cmd in instead of $SHELL (bash, zsh, fish, sh)"),
+		keepShell:  fs.bool("keep-shell", "drop into a shell when cmd exits (=false ends the window with it)"),
+		tmuxOpts:   fs.list("tmux-option", "NAME=VALUE tmux window option (repeatable; NAME= removes)"),
+		lazy:       fs.bool("lazy", "create the window on first attach instead of with the session"),
+		when:       fs.list("when", "KIND=VALUE condition, kind file, bin, env or shell (repeatable; KIND= removes)"),
+	}
+}
+
+// apply copies the provided options onto w and validates the result.
+func (o windowOptionFlags) apply(fs *flagSet, w *config.WindowTemplate) error {
+	if fs.provided("restart") {
+		w.Restart = strings.ToLower(trim(*o.restart))
+	}
+	if fs.provided("max-retries") {
+		n, err := strconv.Atoi(trim(*o.maxRetries))
+		if err != nil {
+			return fmt.Errorf("--max-retries: %q is not a number", *o.maxRetries)
+		}
+		w.MaxRetries = n
+	}
+	if fs.provided("restart-backoff") {
+		w.RestartBackoff = trim(*o.backoff)
+	}
+	if fs.provided("stop-cmd") {
+		w.StopCmd = trim(*o.stopCmd)
+	}
+	if fs.provided("start-mode") {
+		mode := strings.ToLower(trim(*o.startMode))
+		switch mode {
+		case "", config.StartWrap, config.StartSendKeys:
+			w.StartMode = mode
+		default:
+			return fmt.Errorf("--start-mode: unknown mode %q (want %s or %s)", *o.startMode, config.StartWrap, config.StartSendKeys)
+		}
+	}
+	if fs.provided("wrapper") {
+		w.Wrapper = trim(*o.wrapper)
+	}
+	if fs.provided("shell") {
+		w.Shell = trim(*o.shell)
+	}
+	if fs.provided("keep-shell") {
+		w.KeepShell = nil
+		if !*o.keepShell {
+			keep := false
+			w.KeepShell = &keep
+		}
+	}
+	if fs.provided("lazy") {
+		w.Lazy = *o.lazy
+	}
+	if fs.provided("when") {
+		for _, arg := range *o.when {
+			if err := applyCondition(&w.When, arg); err != nil {
+				return err
+			}
+		}
+	}
+	if fs.provided("tmux-option") {
+		opts, err := applyTmuxOptions(w.TmuxOptions, *o.tmuxOpts, true)
+		if err != nil {
+			return err
+		}
+		w.TmuxOptions = opts
+	}
+	if _, err := supervisor.PolicyFor(*w); err != nil {
+		return err
+	}
+	return nil
+}
+
+// applyCondition sets one KIND=VALUE part of a when condition; an empty
+// VALUE clears that part.
+func applyCondition(c *config.Condition, arg string) error {
+	kind, value, ok := strings.Cut(arg, "=")
+	if !ok {
+		return fmt.Errorf("--when: %q is not KIND=VALUE", arg)
+	}
+	value = trim(value)
+	switch strings.ToLower(trim(kind)) {
+	case "file":
+		c.File = value
+	case "bin":
+		c.Bin = value
+	case "env":
+		c.Env = value
+	case "shell":
+		c.Shell = value
+	default:
+		return fmt.Errorf("--when: unknown kind %q (want file, bin, env or shell)", kind)
+	}
+	return nil
+}
+
+// windowOptionSummary renders the non-default window options as extra
+// tab-separated columns for list/show output.
+func windowOptionSummary(w config.WindowTemplate) string {
+	var out string
+	if w.Restart != "" {
+		out += fmt.Sprintf("\trestart=%s", w.Restart)
+		if w.MaxRetries > 0 {
+			out += fmt.Sprintf("\tmax_retries=%d", w.MaxRetries)
+		}
+		if w.RestartBackoff != "" {
+			out += fmt.Sprintf("\trestart_backoff=%s", w.RestartBackoff)
+		}
+	}
+	if w.StartMode != "" {
+		out += fmt.Sprintf("\tstart_mode=%s", w.StartMode)
+	}
+	if w.Wrapper != "" {
+		out += fmt.Sprintf("\twrapper=%q", w.Wrapper)
+	}
+	if w.Shell != "" {
+		out += fmt.Sprintf("\tshell=%s", w.Shell)
+	}
+	if w.KeepShell != nil && !*w.KeepShell {
+		out += "\tkeep_shell=false"
+	}
+	if w.StopCmd != "" {
+		out += fmt.Sprintf("\tstop_cmd=%q", w.StopCmd)
+	}
+	if w.Lazy {
+		out += "\tlazy"
+	}
+	if w.When != (config.Condition{}) {
+		out += "\twhen: " + w.When.String()
+	}
+	if len(w.TmuxOptions) > 0 {
+		out += "\ttmux: " + tmuxOptionList(w.TmuxOptions)
+	}
+	return out
+}
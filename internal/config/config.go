@@ -24,6 +24,77 @@ type WindowTemplate struct {
 	Cmd  string   `json:"cmd,omitempty"`
 	Cwd  string   `json:"cwd,omitempty"`
 	Tags []string `json:"tags,omitempty"`
+
+	// Restart policy for Cmd: "never" (default), "on-failure" or "always".
+	// MaxRetries bounds consecutive restarts (0 = unlimited); RestartBackoff
+	// is the first delay as a Go duration ("2s"), doubled per retry.
+	Restart        string `json:"restart,omitempty"`
+	MaxRetries     int    `json:"max_retries,omitempty"`
+	RestartBackoff string `json:"restart_backoff,omitempty"`
+
+	// StartMode is how Cmd is started: StartWrap (the default) runs it
+	// under a wrapper shell that records its exit status; StartSendKeys
+	// types it into a plain interactive shell, so it lands in the shell's
+	// history and re-running it after a crash is up-arrow and Enter.
+	StartMode string `json:"start_mode,omitempty"`
+
+	// Shell runs Cmd and the shell the window falls back to instead of
+	// $SHELL: a name on PATH ("bash", "fish") or a path.
+	Shell string `json:"shell,omitempty"`
+
+	// KeepShell false ends the window with its command instead of dropping
+	// into a shell: it closes, or stays dead with its exit status when the
+	// remain-on-exit tmux option is set. Unset means true.
+	KeepShell *bool `json:"keep_shell,omitempty"`
+
+	// Wrapper overrides the environment's Wrapper for this window;
+	// WrapperNone runs the window unwrapped.
+	Wrapper string `json:"wrapper,omitempty"`
+
+	// StopCmd is typed into the window (followed by Enter) when the session
+	// is stopped; empty means send C-c.
+	StopCmd string `json:"stop_cmd,omitempty"`
+
+	// TmuxOptions are window options ("monitor-activity": "on") set when
+	// the window is created, overriding the environment's.
+	TmuxOptions map[string]string `json:"tmux_options,omitempty"`
+
+	// Lazy windows are left out when the session is built and created the
+	// first time they are attached to.
+	Lazy bool `json:"lazy,omitempty"`
+
+	// When gates the window on the project: it is only created if the
+	// condition holds against the environment's Root.
+	When Condition `json:"when,omitzero"`
+}
+
+// WrapperNone as a window's wrapper opts it out of the environment's.
+const WrapperNone = "none"
+
+// Window start modes, see WindowTemplate.StartMode.
+const (
+	StartWrap     = "wrap"
+	StartSendKeys = "send-keys"
+)
+
+// Condition is a window's when clause. Every part that is set must hold;
+// the zero Condition always holds.
+type Condition struct {
+	File  string `json:"file,omitempty"`  // path or glob, relative to Root, that must match
+	Bin   string `json:"bin,omitempty"`   // executable that must be on PATH
+	Env   string `json:"env,omitempty"`   // environment variable that must be set and non-empty
+	Shell string `json:"shell,omitempty"` // predicate run with sh -c in Root that must exit 0
+}
+
+// String renders the set parts as "file=go.mod bin=go", in a fixed order.
+func (c Condition) String() string {
+	var parts []string
+	for _, p := range [][2]string{{"file", c.File}, {"bin", c.Bin}, {"env", c.Env}, {"shell", c.Shell}} {
+		if p[1] != "" {
+			parts = append(parts, p[0]+"="+p[1])
+		}
+	}
+	return strings.Join(parts, " ")
 }
 
 type Template struct {
@@ -37,18 +108,107 @@ type Environment struct {
 	Folder       string           `json:"folder,omitempty"`
 	DBConnection string           `json:"db_connection,omitempty"`
 	Windows      []WindowTemplate `json:"windows"`
+
+	// StopTimeout is how long a graceful stop waits for window commands to
+	// exit before killing the session, as a Go duration ("30s").
+	StopTimeout string `json:"stop_timeout,omitempty"`
+
+	// TmuxOptions are tmux options set when the session is built. Session
+	// options apply to the session; window options to every window.
+	TmuxOptions map[string]string `json:"tmux_options,omitempty"`
+
+	// Wrapper runs every window inside a project environment: either a
+	// prefix for the window's shell ("nix develop -c", "direnv exec .") or
+	// a command with a {cmd} placeholder for the quoted command line
+	// ("docker compose exec app sh -c {cmd}"). Windows may override it.
+	Wrapper string `json:"wrapper,omitempty"`
+
+	// Mute silences notifications about this environment's agents.
+	Mute bool `json:"mute,omitempty"`
+}
+
+// Notify configures the notifications the TUI sends when an agent stops
+// cooking or asks for something (see internal/notify).
+type Notify struct {
+	// Via lists the notifiers to use: "bell", "osc9", "osc777",
+	// "notify-send", "tmux" and "command". Empty means no notifications.
+	Via []string `json:"via,omitempty"`
+
+	// Command is run with sh -c by the "command" notifier, with {env},
+	// {session}, {window}, {status} and {message} replaced by shell-quoted
+	// values.
+	Command string `json:"command,omitempty"`
+
+	// QuietHours silences every notifier during a local-time range such as
+	// "22:00-07:00".
+	QuietHours string `json:"quiet_hours,omitempty"`
+}
+
+// Agents extends and tunes the built-in registry of AI agent CLIs (see
+// agentstatus.Registry), so a new CLI does not have to wait for a release.
+type Agents struct {
+	// Tools adds agent CLIs, or changes the built-in one of the same name.
+	Tools []AgentTool `json:"tools,omitempty"`
+
+	// Remove drops built-in tools by name.
+	Remove []string `json:"remove,omitempty"`
+
+	// Thresholds overrides the default CPU thresholds of every tool.
+	Thresholds AgentThresholds `json:"thresholds,omitzero"`
+}
+
+// AgentTool is one agent CLI of the registry.
+type AgentTool struct {
+	Name string `json:"name"`
+
+	// Aliases are other commands that run this agent: a process name
+	// such as "aider-chat", or a command line prefix such as
+	// "python -m aider", which matches window templates running it.
+	Aliases []string `json:"aliases,omitempty"`
+
+	// Pack names the built-in screen pattern pack to use, for a tool that
+	// looks like another one (a wrapper around claude, say). Defaults to
+	// the tool's own pack.
+	Pack string `json:"pack,omitempty"`
+
+	Thresholds AgentThresholds `json:"thresholds,omitzero"`
+}
+
+// AgentThresholds tune the CPU heuristic that decides whether an agent is
+// cooking, and when cooking counts as stalled. Zero fields keep the default.
+type AgentThresholds struct {
+	// MinDelta and Ratio set the cooking threshold: CPU above both
+	// baseline+MinDelta and baseline*Ratio (defaults 5 and 1.3).
+	MinDelta float64 `json:"min_delta,omitempty"`
+	Ratio    float64 `json:"ratio,omitempty"`
+
+	// LowSamples is how many samples in a row below the threshold end
+	// cooking (default 3).
+	LowSamples int `json:"low_samples,omitempty"`
+
+	// Warmup is how many samples of a new window only build the baseline
+	// (default 10).
+	Warmup int `json:"warmup,omitempty"`
+
+	// StallMinutes is how long an agent may cook without its screen
+	// changing before it counts as stalled (default 10).
+	StallMinutes float64 `json:"stall_minutes,omitempty"`
 }
 
 type Data struct {
 	Environments []Environment
 	Templates    []Template
 	Theme        string
+	Notify       Notify
+	Agents       Agents
 }
 
 type fileSchema struct {
 	Environments []Environment `json:"environments"`
 	Templates    []Template    `json:"templates,omitempty"`
 	Theme        string        `json:"theme,omitempty"`
+	Notify       Notify        `json:"notify,omitzero"`
+	Agents       Agents        `json:"agents,omitzero"`
 }
 
 func ConfigFilePath() (string, error) {
@@ -166,6 +326,8 @@ func loadAllLocked() (Data, error) {
 		Environments: cfg.Environments,
 		Templates:    cfg.Templates,
 		Theme:        strings.TrimSpace(cfg.Theme),
+		Notify:       normalizeNotify(cfg.Notify),
+		Agents:       cfg.Agents,
 	}, nil
 }
 
@@ -202,6 +364,18 @@ func SaveTheme(theme string) error {
 	return saveAllLocked(data)
 }
 
+// SaveNotify replaces the notification settings.
+func SaveNotify(n Notify) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+	data, err := loadAllLocked()
+	if err != nil {
+		return err
+	}
+	data.Notify = n
+	return saveAllLocked(data)
+}
+
 func SaveAll(data Data) error {
 	configMu.Lock()
 	defer configMu.Unlock()
@@ -229,6 +403,8 @@ func saveAllLocked(data Data) error {
 		Environments: data.Environments,
 		Templates:    data.Templates,
 		Theme:        strings.TrimSpace(data.Theme),
+		Notify:       normalizeNotify(data.Notify),
+		Agents:       data.Agents,
 	}
 	b, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
@@ -281,6 +457,9 @@ func normalizeEnvironment(env *Environment) {
 	env.Name = strings.TrimSpace(env.Name)
 	env.Folder = strings.TrimSpace(env.Folder)
 	env.DBConnection = strings.TrimSpace(env.DBConnection)
+	env.StopTimeout = strings.TrimSpace(env.StopTimeout)
+	env.Wrapper = strings.TrimSpace(env.Wrapper)
+	env.TmuxOptions = normalizeOptions(env.TmuxOptions)
 	if env.Root == "" {
 		env.Root = env.Folder
 	}
@@ -302,6 +481,19 @@ func normalizeTemplate(template *Template) {
 	template.Windows = normalizeWindows(template.Windows)
 }
 
+func normalizeNotify(n Notify) Notify {
+	var via []string
+	for _, v := range n.Via {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			via = append(via, v)
+		}
+	}
+	n.Via = via
+	n.Command = strings.TrimSpace(n.Command)
+	n.QuietHours = strings.TrimSpace(n.QuietHours)
+	return n
+}
+
 // nameTagRe matches [tag] tokens embedded in a window name. Older configs
 // (and the legacy defaults) stored tags inline in the name; normalizeWindows
 // lifts them into the Tags field so detection keeps working.
@@ -317,6 +509,19 @@ func normalizeWindows(windows []WindowTemplate) []WindowTemplate {
 		w.Name = strings.TrimSpace(w.Name)
 		w.Cmd = strings.TrimSpace(w.Cmd)
 		w.Cwd = strings.TrimSpace(w.Cwd)
+		w.Restart = strings.ToLower(strings.TrimSpace(w.Restart))
+		w.RestartBackoff = strings.TrimSpace(w.RestartBackoff)
+		w.StopCmd = strings.TrimSpace(w.StopCmd)
+		w.Wrapper = strings.TrimSpace(w.Wrapper)
+		w.Shell = strings.TrimSpace(w.Shell)
+		w.StartMode = strings.ToLower(strings.TrimSpace(w.StartMode))
+		w.TmuxOptions = normalizeOptions(w.TmuxOptions)
+		w.When = Condition{
+			File:  strings.TrimSpace(w.When.File),
+			Bin:   strings.TrimSpace(w.When.Bin),
+			Env:   strings.TrimSpace(w.When.Env),
+			Shell: strings.TrimSpace(w.When.Shell),
+		}
 		for _, match := range nameTagRe.FindAllStringSubmatch(w.Name, -1) {
 			if !hasTagFold(w.Tags, match[1]) {
 				w.Tags = append(w.Tags, match[1])
@@ -331,6 +536,46 @@ func normalizeWindows(windows []WindowTemplate) []WindowTemplate {
 	return out
 }
 
+// MergeWindowSettings carries settings the TUI's one-line window spec
+// cannot express (restart policy and friends) from previous onto the
+// same-named windows in edited, so editing a spec does not silently drop
+// them. Name, Cmd, Cwd and Tags always come from edited.
+func MergeWindowSettings(edited, previous []WindowTemplate) []WindowTemplate {
+	out := make([]WindowTemplate, len(edited))
+	for i, w := range edited {
+		merged := w
+		for _, prev := range previous {
+			if strings.EqualFold(strings.TrimSpace(prev.Name), strings.TrimSpace(w.Name)) {
+				merged = prev
+				merged.Name, merged.Cmd, merged.Cwd, merged.Tags = w.Name, w.Cmd, w.Cwd, w.Tags
+				break
+			}
+		}
+		out[i] = merged
+	}
+	return out
+}
+
+// normalizeOptions trims tmux_options and lowercases the names; entries
+// with an empty name are dropped, as is an empty map.
+func normalizeOptions(opts map[string]string) map[string]string {
+	if len(opts) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(opts))
+	for name, value := range opts {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		out[name] = strings.TrimSpace(value)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
 func hasTagFold(tags []string, tag string) bool {
 	for _, t := range tags {
 		if strings.EqualFold(t, tag) {
@@ -34,3 +34,22 @@ func TestNormalizeWindowsNameOnlyTagsGetsFallbackName(t *testing.T) {
 		t.Errorf("unexpected result: %+v", got)
 	}
 }
+
+func TestMergeWindowSettingsKeepsRestartPolicy(t *testing.T) {
+	previous := []WindowTemplate{
+		{Name: "server", Cmd: "npm run dev", Restart: "on-failure", MaxRetries: 3, RestartBackoff: "2s"},
+		{Name: "shell"},
+	}
+	edited := []WindowTemplate{
+		{Name: "Server", Cmd: "npm start"},
+		{Name: "logs", Cmd: "tail -f log"},
+	}
+	got := MergeWindowSettings(edited, previous)
+	want := []WindowTemplate{
+		{Name: "Server", Cmd: "npm start", Restart: "on-failure", MaxRetries: 3, RestartBackoff: "2s"},
+		{Name: "logs", Cmd: "tail -f log"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeWindowSettings mismatch:\ngot:  %+v\nwant: %+v", got, want)
+	}
+}
@@ -0,0 +1,27 @@
+package notify
+
+import "sync"
+
+// Recorder is a Notifier that keeps the events it is sent, for tests.
+// Err, when set, is returned from every Notify.
+type Recorder struct {
+	mu     sync.Mutex
+	events []Event
+	Err    error
+}
+
+var _ Notifier = (*Recorder)(nil)
+
+func (r *Recorder) Notify(ev Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, ev)
+	return r.Err
+}
+
+// Events returns a copy of the events received so far.
+func (r *Recorder) Events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...)
+}
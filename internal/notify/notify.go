@@ -0,0 +1,267 @@
+// Package notify tells the user when an agent needs them: it stopped
+// cooking, asks for approval, or failed. Notifiers are configured in the
+// "notify" section of the config file (see config.Notify).
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"ide/internal/agentstatus"
+	"ide/internal/config"
+	"ide/internal/tmux"
+)
+
+// commandTimeout bounds notify-send and custom commands, so a hanging one
+// cannot pile up behind the next notification.
+const commandTimeout = 10 * time.Second
+
+// Event is one agent status transition worth telling the user about.
+type Event struct {
+	Env     string // environment name
+	Session string // tmux session
+	Window  string // tmux window name
+	From    agentstatus.Status
+	To      agentstatus.Status
+	Message string // the agent's self-reported message, if any
+	At      time.Time
+}
+
+// Title is the short headline of the notification, e.g. "ide: api/claude".
+func (e Event) Title() string {
+	return fmt.Sprintf("ide: %s/%s", e.Env, e.Window)
+}
+
+// Body says what happened, followed by the agent's message when it left
+// one.
+func (e Event) Body() string {
+	var what string
+	switch e.To {
+	case agentstatus.StatusAwaitingApproval:
+		what = "needs approval"
+	case agentstatus.StatusError:
+		what = "stopped with an error"
+	case agentstatus.StatusAwaitingInput:
+		what = "is waiting for input"
+	case agentstatus.StatusStalled:
+		what = "seems stalled"
+	default:
+		what = "is " + string(e.To)
+	}
+	if e.Message != "" {
+		return what + ": " + e.Message
+	}
+	return what
+}
+
+// ShouldNotify reports whether a window going from one status to another
+// is worth a notification: an agent that finished cooking, or one that
+// starts asking for approval, reports an error or stalls.
+func ShouldNotify(from, to agentstatus.Status) bool {
+	if from == to {
+		return false
+	}
+	switch to {
+	case agentstatus.StatusAwaitingApproval, agentstatus.StatusError, agentstatus.StatusStalled:
+		return true
+	case agentstatus.StatusAwaitingInput:
+		return from == agentstatus.StatusCooking || from == agentstatus.StatusStalled
+	}
+	return false
+}
+
+// Notifier delivers an Event.
+type Notifier interface {
+	Notify(ev Event) error
+}
+
+// Multi sends each event to all of its notifiers, joining their errors.
+type Multi []Notifier
+
+func (m Multi) Notify(ev Event) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ev); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Bell rings the terminal bell. Inside tmux the bell marks the window and,
+// with bell-action set, reaches the terminal.
+type Bell struct {
+	W io.Writer
+}
+
+func (b Bell) Notify(Event) error {
+	_, err := io.WriteString(b.W, "\a")
+	return err
+}
+
+// OSC sends a desktop notification through the terminal: OSC 9 (iTerm2,
+// WezTerm, Windows Terminal, ...) or OSC 777 (rxvt, foot, Ghostty, ...).
+// Inside tmux the sequence is wrapped for passthrough, which needs
+// `set -g allow-passthrough on`.
+type OSC struct {
+	W    io.Writer
+	Code int // 9 or 777
+}
+
+func (o OSC) Notify(ev Event) error {
+	var seq string
+	if o.Code == 777 {
+		seq = fmt.Sprintf("\x1b]777;notify;%s;%s\x07", oscText(ev.Title()), oscText(ev.Body()))
+	} else {
+		seq = fmt.Sprintf("\x1b]9;%s: %s\x07", oscText(ev.Title()), oscText(ev.Body()))
+	}
+	if os.Getenv("TMUX") != "" {
+		seq = tmuxPassthrough(seq)
+	}
+	_, err := io.WriteString(o.W, seq)
+	return err
+}
+
+// oscText drops the characters that would end or split an OSC sequence.
+func oscText(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == ';' {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// tmuxPassthrough wraps seq in tmux's DCS passthrough, doubling its
+// escapes.
+func tmuxPassthrough(seq string) string {
+	return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+}
+
+// NotifySend runs notify-send (libnotify).
+type NotifySend struct{}
+
+func (NotifySend) Notify(ev Event) error {
+	urgency := "normal"
+	if ev.To == agentstatus.StatusAwaitingApproval || ev.To == agentstatus.StatusError {
+		urgency = "critical"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	return run(exec.CommandContext(ctx, "notify-send", "-a", "ide", "-u", urgency, ev.Title(), ev.Body()))
+}
+
+// TmuxMessage shows the event in the status line of every tmux client.
+type TmuxMessage struct{}
+
+func (TmuxMessage) Notify(ev Event) error {
+	return tmux.DisplayMessageAll(ev.Title() + " " + ev.Body())
+}
+
+// Command runs a user command with sh -c. {env}, {session}, {window},
+// {status} and {message} in Template are replaced with shell-quoted values;
+// the same values are in $IDE_ENV, $IDE_SESSION, $IDE_WINDOW, $IDE_STATUS
+// and $IDE_MESSAGE.
+type Command struct {
+	Template string
+}
+
+func (c Command) Notify(ev Event) error {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	return run(c.command(ctx, ev))
+}
+
+func (c Command) command(ctx context.Context, ev Event) *exec.Cmd {
+	script := strings.NewReplacer(
+		"{env}", shellQuote(ev.Env),
+		"{session}", shellQuote(ev.Session),
+		"{window}", shellQuote(ev.Window),
+		"{status}", shellQuote(string(ev.To)),
+		"{message}", shellQuote(ev.Message),
+	).Replace(c.Template)
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", script)
+	cmd.Env = append(os.Environ(),
+		"IDE_ENV="+ev.Env,
+		"IDE_SESSION="+ev.Session,
+		"IDE_WINDOW="+ev.Window,
+		"IDE_STATUS="+string(ev.To),
+		"IDE_MESSAGE="+ev.Message,
+	)
+	return cmd
+}
+
+// run runs cmd, folding its output into the error.
+func run(cmd *exec.Cmd) error {
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s: %w: %s", cmd.Args[0], err, msg)
+		}
+		return fmt.Errorf("%s: %w", cmd.Args[0], err)
+	}
+	return nil
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'"'"'`) + "'"
+}
+
+// Quiet drops events that fall inside Hours and passes the rest on.
+type Quiet struct {
+	Notifier
+	Hours QuietHours
+}
+
+func (q Quiet) Notify(ev Event) error {
+	if q.Hours.Contains(ev.At) {
+		return nil
+	}
+	return q.Notifier.Notify(ev)
+}
+
+// Via names the notifiers New understands, in the order `ide notify`
+// lists them.
+var Via = []string{"bell", "osc9", "osc777", "notify-send", "tmux", "command"}
+
+// New builds the notifier cfg describes. Bell and OSC notifiers write to w,
+// the terminal the TUI runs in. With no notifiers configured it returns an
+// empty Multi, which does nothing.
+func New(cfg config.Notify, w io.Writer) (Notifier, error) {
+	var multi Multi
+	for _, via := range cfg.Via {
+		switch via {
+		case "bell":
+			multi = append(multi, Bell{W: w})
+		case "osc9":
+			multi = append(multi, OSC{W: w, Code: 9})
+		case "osc777":
+			multi = append(multi, OSC{W: w, Code: 777})
+		case "notify-send":
+			multi = append(multi, NotifySend{})
+		case "tmux":
+			multi = append(multi, TmuxMessage{})
+		case "command":
+			if cfg.Command == "" {
+				return nil, errors.New(`notifier "command" needs notify.command`)
+			}
+			multi = append(multi, Command{Template: cfg.Command})
+		default:
+			return nil, fmt.Errorf("unknown notifier %q (want %s)", via, strings.Join(Via, ", "))
+		}
+	}
+	if cfg.QuietHours == "" {
+		return multi, nil
+	}
+	hours, err := ParseQuietHours(cfg.QuietHours)
+	if err != nil {
+		return nil, err
+	}
+	return Quiet{Notifier: multi, Hours: hours}, nil
+}
@@ -0,0 +1,170 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ide/internal/agentstatus"
+	"ide/internal/config"
+)
+
+func TestShouldNotify(t *testing.T) {
+	cases := []struct {
+		from, to agentstatus.Status
+		want     bool
+	}{
+		{agentstatus.StatusCooking, agentstatus.StatusAwaitingInput, true},
+		{agentstatus.StatusIdle, agentstatus.StatusAwaitingInput, false},
+		{agentstatus.StatusAwaitingInput, agentstatus.StatusAwaitingInput, false},
+		{agentstatus.StatusCooking, agentstatus.StatusAwaitingApproval, true},
+		{agentstatus.StatusIdle, agentstatus.StatusError, true},
+		{agentstatus.StatusError, agentstatus.StatusError, false},
+		{agentstatus.StatusAwaitingInput, agentstatus.StatusCooking, false},
+		{agentstatus.StatusCooking, agentstatus.StatusIdle, false},
+		{agentstatus.StatusCooking, agentstatus.StatusStalled, true},
+		{agentstatus.StatusStalled, agentstatus.StatusCooking, false},
+		{agentstatus.StatusStalled, agentstatus.StatusAwaitingInput, true},
+	}
+	for _, c := range cases {
+		if got := ShouldNotify(c.from, c.to); got != c.want {
+			t.Errorf("ShouldNotify(%s, %s) = %v, want %v", c.from, c.to, got, c.want)
+		}
+	}
+}
+
+func TestQuietHours(t *testing.T) {
+	at := func(hhmm string) time.Time {
+		t.Helper()
+		v, err := time.ParseInLocation("15:04", hhmm, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	cases := []struct {
+		spec string
+		at   string
+		want bool
+	}{
+		{"22:00-07:00", "23:30", true},
+		{"22:00-07:00", "03:00", true},
+		{"22:00-07:00", "07:00", false},
+		{"22:00-07:00", "12:00", false},
+		{"22:00-07:00", "22:00", true},
+		{"12:00-13:30", "13:29", true},
+		{"12:00-13:30", "11:59", false},
+		{"09:00-09:00", "09:00", false},
+	}
+	for _, c := range cases {
+		q, err := ParseQuietHours(c.spec)
+		if err != nil {
+			t.Fatalf("ParseQuietHours(%q): %v", c.spec, err)
+		}
+		if got := q.Contains(at(c.at)); got != c.want {
+			t.Errorf("%s contains %s = %v, want %v", c.spec, c.at, got, c.want)
+		}
+	}
+	for _, bad := range []string{"", "22:00", "25:00-07:00", "late-early"} {
+		if _, err := ParseQuietHours(bad); err == nil {
+			t.Errorf("ParseQuietHours(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestQuietDropsEventsInsideTheRange(t *testing.T) {
+	rec := &Recorder{}
+	q := Quiet{Notifier: rec, Hours: QuietHours{Start: 22 * 60, End: 7 * 60}}
+	night := time.Date(2026, 1, 1, 23, 0, 0, 0, time.Local)
+	day := time.Date(2026, 1, 1, 14, 0, 0, 0, time.Local)
+	for _, at := range []time.Time{night, day} {
+		if err := q.Notify(Event{Window: "agent", At: at}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := rec.Events(); len(got) != 1 || !got[0].At.Equal(day) {
+		t.Errorf("events = %+v, want only the daytime one", got)
+	}
+}
+
+func TestMultiNotifiesAllAndJoinsErrors(t *testing.T) {
+	failing := &Recorder{Err: errors.New("boom")}
+	ok := &Recorder{}
+	err := Multi{failing, ok}.Notify(Event{Window: "agent"})
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("err = %v, want boom", err)
+	}
+	if len(ok.Events()) != 1 {
+		t.Error("a failing notifier stopped the next one")
+	}
+}
+
+func TestOSCSequences(t *testing.T) {
+	ev := Event{Env: "api", Window: "claude", To: agentstatus.StatusAwaitingApproval, Message: "rm -rf; ok?"}
+	cases := []struct {
+		code int
+		tmux string
+		want string
+	}{
+		{9, "", "\x1b]9;ide: api/claude: needs approval: rm -rf  ok?\x07"},
+		{777, "", "\x1b]777;notify;ide: api/claude;needs approval: rm -rf  ok?\x07"},
+		{9, "/tmp/tmux-1000/default,1,0", "\x1bPtmux;\x1b\x1b]9;ide: api/claude: needs approval: rm -rf  ok?\x07\x1b\\"},
+	}
+	for _, c := range cases {
+		t.Setenv("TMUX", c.tmux)
+		var buf bytes.Buffer
+		if err := (OSC{W: &buf, Code: c.code}).Notify(ev); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != c.want {
+			t.Errorf("OSC %d (TMUX=%q) = %q, want %q", c.code, c.tmux, buf.String(), c.want)
+		}
+	}
+}
+
+func TestCommandQuotesTemplateValues(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	c := Command{Template: "printf '%s|%s|%s|%s|%s' {env} {window} {status} \"$IDE_MESSAGE\" {message} > " + out}
+	ev := Event{Env: "my env", Window: "it's", To: agentstatus.StatusError, Message: "$(false)"}
+	if err := run(c.command(context.Background(), ev)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "my env|it's|error|$(false)|$(false)"; string(got) != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := New(config.Notify{Via: []string{"bell", "osc777"}}, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if multi, ok := n.(Multi); !ok || len(multi) != 2 {
+		t.Errorf("New = %#v, want a Multi of two", n)
+	}
+	if n, err = New(config.Notify{Via: []string{"bell"}, QuietHours: "22:00-07:00"}, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := n.(Quiet); !ok {
+		t.Errorf("New with quiet hours = %#v, want Quiet", n)
+	}
+	for _, bad := range []config.Notify{
+		{Via: []string{"pager"}},
+		{Via: []string{"command"}},
+		{Via: []string{"bell"}, QuietHours: "soon"},
+	} {
+		if _, err := New(bad, &buf); err == nil {
+			t.Errorf("New(%+v) succeeded, want an error", bad)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// QuietHours is a daily local-time range, in minutes since midnight. A
+// range whose end is before its start wraps past midnight.
+type QuietHours struct {
+	Start, End int
+}
+
+// ParseQuietHours parses "HH:MM-HH:MM", e.g. "22:00-07:00".
+func ParseQuietHours(s string) (QuietHours, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		return QuietHours{}, fmt.Errorf("quiet hours %q: want HH:MM-HH:MM", s)
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("quiet hours %q: %w", s, err)
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("quiet hours %q: %w", s, err)
+	}
+	return QuietHours{Start: start, End: end}, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("bad time %q", strings.TrimSpace(s))
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Contains reports whether t's local time of day is inside the range. The
+// start is inclusive, the end exclusive; an empty range contains nothing.
+func (q QuietHours) Contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	if q.Start <= q.End {
+		return m >= q.Start && m < q.End
+	}
+	return m >= q.Start || m < q.End
+}
+
+func (q QuietHours) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", q.Start/60, q.Start%60, q.End/60, q.End%60)
+}
@@ -0,0 +1,210 @@
+// Package state reads and writes ide's runtime state: small JSON files that
+// record what happened (orphaned processes, agent reports and timelines,
+// ...) rather than what the user configured. Everything here may be
+// deleted at any time; readers treat a missing file as empty.
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Dir returns ide's state directory: $XDG_STATE_HOME/ide, falling back to
+// ~/.local/state/ide.
+func Dir() (string, error) {
+	if base := os.Getenv("XDG_STATE_HOME"); base != "" {
+		return filepath.Join(base, "ide"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve state dir: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", "ide"), nil
+}
+
+// Path joins name onto the state directory.
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// AgentReportName is the state file holding the self-report of the agent in
+// the tmux window key ("session:window"). Each window gets its own file so
+// concurrent reporters never overwrite each other.
+func AgentReportName(key string) string {
+	return filepath.Join("agents", url.QueryEscape(key)+".json")
+}
+
+// InboxName is the state file holding the unread agent inbox entries.
+const InboxName = "inbox.json"
+
+// agentTimelineDir holds one JSON Lines file of status transitions per
+// agent window.
+const agentTimelineDir = "timelines"
+
+// AgentTimelineName is the JSON Lines state file recording the status
+// transitions of the agent in the tmux window key.
+func AgentTimelineName(key string) string {
+	return filepath.Join(agentTimelineDir, url.QueryEscape(key)+".jsonl")
+}
+
+// AgentTimelineKeys lists the window keys that have a timeline.
+func AgentTimelineKeys() ([]string, error) {
+	dir, err := Path(agentTimelineDir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read state %s: %w", agentTimelineDir, err)
+	}
+	var keys []string
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".jsonl")
+		if !ok || e.IsDir() {
+			continue
+		}
+		if key, err := url.QueryUnescape(name); err == nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// ReadJSON decodes the state file name into v. A missing file leaves v
+// untouched and returns nil.
+func ReadJSON(name string, v any) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read state %s: %w", name, err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("parse state %s: %w", name, err)
+	}
+	return nil
+}
+
+// WriteJSON encodes v into the state file name, replacing it atomically.
+func WriteJSON(name string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal state %s: %w", name, err)
+	}
+	return writeFile(name, b)
+}
+
+// AppendJSONLine encodes v as one line at the end of the JSON Lines state
+// file name. Lines this short are appended atomically, so concurrent
+// writers never interleave.
+func AppendJSONLine(name string, v any) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal state %s: %w", name, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create state dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("append state %s: %w", name, err)
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("append state %s: %w", name, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("append state %s: %w", name, err)
+	}
+	return nil
+}
+
+// ReadJSONLines decodes every line of the JSON Lines state file name. A
+// missing file is empty; lines that do not parse (a write cut short) are
+// skipped.
+func ReadJSONLines[T any](name string) ([]T, error) {
+	path, err := Path(name)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read state %s: %w", name, err)
+	}
+	var out []T
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		var v T
+		if len(bytes.TrimSpace(line)) == 0 || json.Unmarshal(line, &v) != nil {
+			continue
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// WriteJSONLines replaces the JSON Lines state file name with vs,
+// atomically.
+func WriteJSONLines[T any](name string, vs []T) error {
+	var buf bytes.Buffer
+	for _, v := range vs {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("marshal state %s: %w", name, err)
+		}
+		buf.Write(b)
+		buf.WriteByte('\n')
+	}
+	return writeFile(name, buf.Bytes())
+}
+
+// writeFile replaces the state file name with b atomically.
+func writeFile(name string, b []byte) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create state dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".state-*.tmp")
+	if err != nil {
+		return fmt.Errorf("write state %s: %w", name, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write state %s: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write state %s: %w", name, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write state %s: %w", name, err)
+	}
+	return nil
+}
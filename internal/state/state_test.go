@@ -0,0 +1,42 @@
+package state
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDirHonoursXDGStateHome(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/tmp/xdg-state")
+	got, err := Dir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join("/tmp/xdg-state", "ide"); got != want {
+		t.Errorf("Dir() = %q, want %q", got, want)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	missing := map[string]int{"kept": 1}
+	if err := ReadJSON("missing.json", &missing); err != nil {
+		t.Fatalf("ReadJSON on a missing file: %v", err)
+	}
+	if !reflect.DeepEqual(missing, map[string]int{"kept": 1}) {
+		t.Errorf("missing file should leave v untouched, got %v", missing)
+	}
+
+	in := map[string][]int{"ide-a": {1, 2}}
+	if err := WriteJSON("sub/record.json", in); err != nil {
+		t.Fatal(err)
+	}
+	var out map[string][]int
+	if err := ReadJSON("sub/record.json", &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip = %v, want %v", out, in)
+	}
+}
@@ -0,0 +1,181 @@
+// Package supervisor decides when a window's startup command should be
+// re-run after it exits. Like agentstatus it is pure — no tmux calls, no
+// timers — so the ui layer drives it from session snapshots and unit tests
+// can step the state machine with explicit timestamps.
+package supervisor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"ide/internal/config"
+)
+
+// Mode is a window's restart policy.
+type Mode string
+
+const (
+	Never     Mode = "never"
+	OnFailure Mode = "on-failure"
+	Always    Mode = "always"
+)
+
+const (
+	// DefaultBackoff is the first restart delay when restart_backoff is unset.
+	DefaultBackoff = time.Second
+	// MaxBackoff caps the doubling delay between consecutive restarts.
+	MaxBackoff = 5 * time.Minute
+	// StableAfter is how long a restarted command must stay up before its
+	// next exit starts a fresh backoff/retry sequence.
+	StableAfter = time.Minute
+)
+
+// Policy is the parsed restart configuration of one window.
+type Policy struct {
+	Mode       Mode
+	MaxRetries int           // consecutive restarts before giving up; 0 = unlimited
+	Backoff    time.Duration // delay before the first restart, doubled per retry
+}
+
+// PolicyFor parses w's restart settings. An empty restart field means Never.
+// A window with keep_shell false closes when its command exits unless its
+// remain-on-exit option keeps it, and a closed window cannot be restarted,
+// so such a policy needs remain-on-exit set on the window ("on", or
+// "failed" for on-failure).
+func PolicyFor(w config.WindowTemplate) (Policy, error) {
+	p := Policy{Mode: Never, MaxRetries: w.MaxRetries, Backoff: DefaultBackoff}
+	switch Mode(strings.ToLower(strings.TrimSpace(w.Restart))) {
+	case "", Never:
+	case OnFailure:
+		p.Mode = OnFailure
+	case Always:
+		p.Mode = Always
+	default:
+		return Policy{}, fmt.Errorf("unknown restart policy %q (want never, on-failure or always)", w.Restart)
+	}
+	if p.Mode != Never && w.StartMode == config.StartSendKeys {
+		return Policy{}, fmt.Errorf("restart %q needs start_mode %q: a send-keys window records no exit status", w.Restart, config.StartWrap)
+	}
+	if p.Mode != Never && w.KeepShell != nil && !*w.KeepShell {
+		remain := strings.ToLower(strings.TrimSpace(w.TmuxOptions["remain-on-exit"]))
+		if remain != "on" && (remain != "failed" || p.Mode != OnFailure) {
+			want := `"on"`
+			if p.Mode == OnFailure {
+				want = `"on" or "failed"`
+			}
+			return Policy{}, fmt.Errorf("restart %q with keep_shell false needs the window's remain-on-exit tmux option set to %s: otherwise the window closes with its command", w.Restart, want)
+		}
+	}
+	if w.MaxRetries < 0 {
+		return Policy{}, fmt.Errorf("max_retries cannot be negative")
+	}
+	if s := strings.TrimSpace(w.RestartBackoff); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return Policy{}, fmt.Errorf("parse restart_backoff %q: %w", s, err)
+		}
+		if d < 0 {
+			return Policy{}, fmt.Errorf("restart_backoff cannot be negative")
+		}
+		p.Backoff = d
+	}
+	return p, nil
+}
+
+// Enabled reports whether the policy ever restarts anything.
+func (p Policy) Enabled() bool { return p.Mode == OnFailure || p.Mode == Always }
+
+// State is the per-window supervision record the ui layer keeps.
+type State struct {
+	Restarts    int       // restarts performed since the TUI started
+	Consecutive int       // restarts since the command last stayed up for StableAfter
+	LastFailure time.Time // when a non-zero exit was last observed
+	LastCode    int       // exit code of the most recent exit
+	LastStart   time.Time // when the most recent restart completed
+	Due         time.Time // when a scheduled restart should run; zero if none
+	Exited      bool      // the current exit has been observed and decided on
+	InFlight    bool      // a restart has been started but not confirmed
+	GaveUp      bool      // MaxRetries was reached for the current exit
+}
+
+// delay is the backoff before restart number n+1 of a sequence.
+func (p Policy) delay(n int) time.Duration {
+	d := p.Backoff
+	for i := 0; i < n && d < MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > MaxBackoff {
+		d = MaxBackoff
+	}
+	return d
+}
+
+// Observe feeds an exit seen in a snapshot taken at seenAt. It returns true
+// when the caller should restart the window now; the caller then reports
+// completion through Started. Snapshots taken before the last restart are
+// ignored, so a slow poll cannot double-count an exit.
+func (p Policy) Observe(st *State, code int, seenAt, now time.Time) bool {
+	if st.InFlight || seenAt.Before(st.LastStart) {
+		return false
+	}
+	if !st.Exited {
+		st.Exited = true
+		st.GaveUp = false
+		st.LastCode = code
+		if code != 0 {
+			st.LastFailure = seenAt
+		}
+		if !st.LastStart.IsZero() && seenAt.Sub(st.LastStart) >= StableAfter {
+			st.Consecutive = 0
+		}
+		if !p.shouldRestart(code) {
+			return false
+		}
+		if p.MaxRetries > 0 && st.Consecutive >= p.MaxRetries {
+			st.GaveUp = true
+			return false
+		}
+		st.Due = now.Add(p.delay(st.Consecutive))
+	}
+	if st.Due.IsZero() || now.Before(st.Due) {
+		return false
+	}
+	st.Due = time.Time{}
+	st.InFlight = true
+	return true
+}
+
+// Running records that the window was seen without an exit status, e.g.
+// after the user rebuilt the session by hand.
+func (st *State) Running() {
+	if st.InFlight {
+		return
+	}
+	st.Exited = false
+	st.GaveUp = false
+	st.Due = time.Time{}
+}
+
+// Started records the outcome of a restart begun by Observe.
+func (st *State) Started(now time.Time, err error) {
+	st.InFlight = false
+	if err != nil {
+		return
+	}
+	st.Exited = false
+	st.Restarts++
+	st.Consecutive++
+	st.LastStart = now
+}
+
+func (p Policy) shouldRestart(code int) bool {
+	switch p.Mode {
+	case Always:
+		return true
+	case OnFailure:
+		return code != 0
+	default:
+		return false
+	}
+}
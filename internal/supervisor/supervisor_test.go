@@ -0,0 +1,117 @@
+package supervisor
+
+import (
+	"testing"
+	"time"
+
+	"ide/internal/config"
+)
+
+func TestPolicyFor(t *testing.T) {
+	noShell := false
+	tests := []struct {
+		name    string
+		in      config.WindowTemplate
+		want    Policy
+		wantErr bool
+	}{
+		{"unset is never", config.WindowTemplate{}, Policy{Mode: Never, Backoff: DefaultBackoff}, false},
+		{"on-failure with retries", config.WindowTemplate{Restart: "on-failure", MaxRetries: 3}, Policy{Mode: OnFailure, MaxRetries: 3, Backoff: DefaultBackoff}, false},
+		{"always with backoff", config.WindowTemplate{Restart: "Always", RestartBackoff: "500ms"}, Policy{Mode: Always, Backoff: 500 * time.Millisecond}, false},
+		{"unknown mode", config.WindowTemplate{Restart: "sometimes"}, Policy{}, true},
+		{"bad backoff", config.WindowTemplate{Restart: "always", RestartBackoff: "soon"}, Policy{}, true},
+		{"negative retries", config.WindowTemplate{Restart: "always", MaxRetries: -1}, Policy{}, true},
+		{"send-keys without restart", config.WindowTemplate{StartMode: config.StartSendKeys}, Policy{Mode: Never, Backoff: DefaultBackoff}, false},
+		{"send-keys cannot restart", config.WindowTemplate{Restart: "on-failure", StartMode: config.StartSendKeys}, Policy{}, true},
+		{"one-shot closes without remain-on-exit", config.WindowTemplate{Restart: "on-failure", KeepShell: &noShell}, Policy{}, true},
+		{"one-shot kept by remain-on-exit failed", config.WindowTemplate{Restart: "on-failure", KeepShell: &noShell, TmuxOptions: map[string]string{"remain-on-exit": "failed"}}, Policy{Mode: OnFailure, Backoff: DefaultBackoff}, false},
+		{"always needs every exit kept", config.WindowTemplate{Restart: "always", KeepShell: &noShell, TmuxOptions: map[string]string{"remain-on-exit": "failed"}}, Policy{}, true},
+		{"always kept by remain-on-exit on", config.WindowTemplate{Restart: "always", KeepShell: &noShell, TmuxOptions: map[string]string{"remain-on-exit": "on"}}, Policy{Mode: Always, Backoff: DefaultBackoff}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := PolicyFor(tc.in)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("PolicyFor error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("PolicyFor = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestObserveOnFailureIgnoresCleanExit(t *testing.T) {
+	p := Policy{Mode: OnFailure, Backoff: time.Second}
+	var st State
+	now := time.Unix(1000, 0)
+	if p.Observe(&st, 0, now, now.Add(time.Hour)) {
+		t.Fatal("clean exit should not restart under on-failure")
+	}
+	if !st.LastFailure.IsZero() {
+		t.Errorf("clean exit recorded as failure at %v", st.LastFailure)
+	}
+}
+
+func TestObserveBacksOffAndGivesUp(t *testing.T) {
+	p := Policy{Mode: OnFailure, MaxRetries: 2, Backoff: time.Second}
+	var st State
+	now := time.Unix(1000, 0)
+
+	// First exit: scheduled one backoff out, not before.
+	if p.Observe(&st, 1, now, now) {
+		t.Fatal("restart ran before its backoff elapsed")
+	}
+	if !p.Observe(&st, 1, now, now.Add(time.Second)) {
+		t.Fatal("restart not due after backoff")
+	}
+	// While in flight, repeated snapshots of the same exit are ignored.
+	if p.Observe(&st, 1, now, now.Add(2*time.Second)) {
+		t.Fatal("in-flight restart started twice")
+	}
+	now = now.Add(time.Second)
+	st.Started(now, nil)
+
+	// A snapshot taken before the restart completed is stale.
+	if p.Observe(&st, 1, now.Add(-time.Millisecond), now) {
+		t.Fatal("stale snapshot triggered a restart")
+	}
+
+	// Second exit doubles the delay.
+	now = now.Add(time.Second)
+	p.Observe(&st, 1, now, now)
+	if want := now.Add(2 * time.Second); !st.Due.Equal(want) {
+		t.Errorf("second restart due %v, want %v", st.Due, want)
+	}
+	now = now.Add(2 * time.Second)
+	if !p.Observe(&st, 1, now, now) {
+		t.Fatal("second restart not due")
+	}
+	st.Started(now, nil)
+
+	// Third exit hits MaxRetries.
+	now = now.Add(time.Second)
+	if p.Observe(&st, 1, now, now.Add(time.Hour)) || !st.GaveUp {
+		t.Fatalf("expected to give up after %d retries, state %+v", p.MaxRetries, st)
+	}
+	if st.Restarts != 2 || st.LastCode != 1 || !st.LastFailure.Equal(now) {
+		t.Errorf("unexpected state after giving up: %+v", st)
+	}
+}
+
+func TestObserveResetsSequenceAfterStableRun(t *testing.T) {
+	p := Policy{Mode: Always, MaxRetries: 1, Backoff: time.Second}
+	st := State{Restarts: 1, Consecutive: 1, LastStart: time.Unix(1000, 0)}
+	seen := st.LastStart.Add(StableAfter)
+	p.Observe(&st, 0, seen, seen)
+	if st.GaveUp || st.Consecutive != 0 || st.Due.IsZero() {
+		t.Errorf("stable run should start a fresh sequence, state %+v", st)
+	}
+}
+
+func TestDelayIsCapped(t *testing.T) {
+	p := Policy{Backoff: time.Minute}
+	if got := p.delay(20); got != MaxBackoff {
+		t.Errorf("delay(20) = %v, want %v", got, MaxBackoff)
+	}
+}
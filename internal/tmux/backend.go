@@ -0,0 +1,86 @@
+package tmux
+
+import (
+	"time"
+
+	"ide/internal/config"
+)
+
+// Backend is the set of tmux operations the TUI drives. Exec talks to the
+// real tmux binary; Fake keeps everything in memory so session and agent
+// flows can be tested without a server.
+type Backend interface {
+	CheckTmuxExists() error
+	DetectCapabilities() (Capabilities, error)
+
+	ListSessions() ([]string, error)
+	ListWindows(session string) ([]string, error)
+	ListSessionsSnapshot() (SessionsSnapshot, error)
+	HasSession(session string) (bool, error)
+	HasWindow(session, window string) (bool, error)
+	SkippedWindows(env config.Environment) map[string]string
+
+	EnsureSession(env config.Environment) error
+	EnsureWindow(env config.Environment, windowName string) error
+	StopSession(env config.Environment) (StopResult, error)
+	KillSession(session string) error
+	RespawnWindow(env config.Environment, w config.WindowTemplate) error
+	SwapWindow(session, src, dst string) error
+	SelectWindow(target string) error
+
+	SendKeys(target string, literal bool, keys ...string) error
+	CapturePane(session, window string) (string, error)
+	PaneSize(session, window string) (int, int, error)
+
+	CurrentProcess(session, window string) string
+	ProcessInfoByWindow() (map[string]map[string]ProcessInfo, error)
+	SessionProcesses(session string) ([]PaneProcess, error)
+	TerminateOrphans(session string, grace time.Duration) ([]PaneProcess, error)
+}
+
+// Exec is the Backend that shells out to tmux (and ps) — the package-level
+// functions of the same names.
+type Exec struct{}
+
+var _ Backend = Exec{}
+
+func (Exec) CheckTmuxExists() error                       { return CheckTmuxExists() }
+func (Exec) DetectCapabilities() (Capabilities, error)    { return DetectCapabilities() }
+func (Exec) ListSessions() ([]string, error)              { return ListSessions() }
+func (Exec) ListWindows(session string) ([]string, error) { return ListWindows(session) }
+func (Exec) ListSessionsSnapshot() (SessionsSnapshot, error) {
+	return ListSessionsSnapshot()
+}
+func (Exec) HasSession(session string) (bool, error)        { return HasSession(session) }
+func (Exec) HasWindow(session, window string) (bool, error) { return HasWindow(session, window) }
+func (Exec) SkippedWindows(env config.Environment) map[string]string {
+	return SkippedWindows(env)
+}
+func (Exec) EnsureSession(env config.Environment) error { return EnsureSession(env) }
+func (Exec) EnsureWindow(env config.Environment, windowName string) error {
+	return EnsureWindow(env, windowName)
+}
+func (Exec) StopSession(env config.Environment) (StopResult, error) {
+	return StopSession(env)
+}
+func (Exec) KillSession(session string) error { return KillSession(session) }
+func (Exec) RespawnWindow(env config.Environment, w config.WindowTemplate) error {
+	return RespawnWindow(env, w)
+}
+func (Exec) SwapWindow(session, src, dst string) error { return SwapWindow(session, src, dst) }
+func (Exec) SelectWindow(target string) error          { return SelectWindow(target) }
+func (Exec) SendKeys(target string, literal bool, keys ...string) error {
+	return SendKeys(target, literal, keys...)
+}
+func (Exec) CapturePane(session, window string) (string, error) { return CapturePane(session, window) }
+func (Exec) PaneSize(session, window string) (int, int, error)  { return PaneSize(session, window) }
+func (Exec) CurrentProcess(session, window string) string       { return CurrentProcess(session, window) }
+func (Exec) ProcessInfoByWindow() (map[string]map[string]ProcessInfo, error) {
+	return ProcessInfoByWindow()
+}
+func (Exec) SessionProcesses(session string) ([]PaneProcess, error) {
+	return SessionProcesses(session)
+}
+func (Exec) TerminateOrphans(session string, grace time.Duration) ([]PaneProcess, error) {
+	return TerminateOrphans(session, grace)
+}
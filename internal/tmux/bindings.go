@@ -0,0 +1,231 @@
+package tmux
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The managed block ide tmux install writes into the user's tmux.conf is
+// delimited by these marker lines; everything between them is replaced on
+// every install, everything outside is left alone.
+const (
+	bindingsBegin = "# >>> ide key bindings >>> (managed by `ide tmux install`; edits here are overwritten)"
+	bindingsEnd   = "# <<< ide key bindings <<<"
+)
+
+// BindingOptions configures the generated key bindings.
+type BindingOptions struct {
+	Exe          string // ide binary to run; an absolute path survives tmux's minimal PATH
+	SearchKey    string // prefix key for the fuzzy search popup (ide --search)
+	WindowsKey   string // prefix key for the window switcher popup (ide --windows)
+	NextAgentKey string // prefix key for jumping to the next agent window
+	PopupWidth   string // display-popup -w, e.g. "80%" or "120"
+	PopupHeight  string // display-popup -h
+	NoPopup      bool   // tmux before 3.2: open the popups in a new window instead
+}
+
+// DefaultBindingOptions are the bindings the README documents, adjusted to
+// the installed tmux.
+func DefaultBindingOptions() BindingOptions {
+	caps, _ := DetectCapabilities()
+	exe := "ide"
+	if path, err := os.Executable(); err == nil {
+		exe = path
+	}
+	return BindingOptions{
+		Exe:          exe,
+		SearchKey:    "a",
+		WindowsKey:   "w",
+		NextAgentKey: "N",
+		PopupWidth:   "80%",
+		PopupHeight:  "80%",
+		NoPopup:      !caps.Popup,
+	}
+}
+
+// Bindings renders the tmux.conf lines for opts, without the markers.
+// A binding whose key is empty is left out.
+func Bindings(opts BindingOptions) string {
+	exe := opts.Exe
+	if strings.ContainsAny(exe, " \t'\"\\$`;&|<>()*?[]#~") {
+		exe = shellQuote(exe)
+	}
+	launch := fmt.Sprintf("display-popup -E -w %s -h %s", quoteTmuxConf(opts.PopupWidth), quoteTmuxConf(opts.PopupHeight))
+	if opts.NoPopup {
+		launch = "new-window"
+	}
+	var b strings.Builder
+	if opts.SearchKey != "" {
+		fmt.Fprintf(&b, "bind-key %s %s %s\n", quoteTmuxConf(opts.SearchKey), launch, quoteTmuxConf(exe+" --search"))
+	}
+	if opts.WindowsKey != "" {
+		fmt.Fprintf(&b, "bind-key %s %s %s\n", quoteTmuxConf(opts.WindowsKey), launch, quoteTmuxConf(exe+" --windows"))
+	}
+	if opts.NextAgentKey != "" {
+		fmt.Fprintf(&b, "bind-key %s run-shell -b %s\n", quoteTmuxConf(opts.NextAgentKey), quoteTmuxConf(exe+" agent next"))
+	}
+	return b.String()
+}
+
+// BindingsBlock is Bindings wrapped in the managed-block markers.
+func BindingsBlock(opts BindingOptions) string {
+	return bindingsBegin + "\n" + Bindings(opts) + bindingsEnd + "\n"
+}
+
+// quoteTmuxConf quotes s for a tmux.conf command argument when it contains
+// anything tmux's parser would split or interpret.
+func quoteTmuxConf(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t'\"#;$~{}\\") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// installBlock returns conf with block in place of the existing managed
+// block, or appended (after a blank line) when there is none. A begin marker
+// without an end marker is an error: where the block ends is a guess, and a
+// wrong one would drop the user's own lines.
+func installBlock(conf, block string) (string, error) {
+	lines := strings.SplitAfter(conf, "\n")
+	begin, end := -1, -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if begin < 0 && strings.HasPrefix(trimmed, "# >>> ide key bindings >>>") {
+			begin = i
+		} else if begin >= 0 && trimmed == bindingsEnd {
+			end = i
+			break
+		}
+	}
+	if begin < 0 {
+		if conf != "" && !strings.HasSuffix(conf, "\n") {
+			conf += "\n"
+		}
+		if conf != "" && !strings.HasSuffix(conf, "\n\n") {
+			conf += "\n"
+		}
+		return conf + block, nil
+	}
+	if end < 0 {
+		return "", fmt.Errorf("line %d opens the ide key bindings block but no %q line closes it; add that line after the block (or remove the block) and try again", begin+1, bindingsEnd)
+	}
+	return strings.Join(lines[:begin], "") + block + strings.Join(lines[end+1:], ""), nil
+}
+
+// ConfPath picks the tmux.conf to manage: the first that exists of
+// ~/.tmux.conf, $XDG_CONFIG_HOME/tmux/tmux.conf and ~/.config/tmux/tmux.conf
+// (tmux's own search order), else ~/.tmux.conf, which every tmux reads.
+func ConfPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home dir: %w", err)
+	}
+	legacy := filepath.Join(home, ".tmux.conf")
+	candidates := []string{legacy}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		candidates = append(candidates, filepath.Join(xdg, "tmux", "tmux.conf"))
+	}
+	candidates = append(candidates, filepath.Join(home, ".config", "tmux", "tmux.conf"))
+	for _, path := range candidates {
+		if fileExists(path) {
+			return path, nil
+		}
+	}
+	return legacy, nil
+}
+
+// BackupPath is where InstallBindings keeps the previous version of the
+// tmux.conf at path.
+func BackupPath(path string) string {
+	return path + ".bak"
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// InstallBindings writes opts' managed block into the tmux.conf at path,
+// creating the file if needed. An existing file is copied to BackupPath(path)
+// before it is rewritten. It reports whether the file changed.
+func InstallBindings(path string, opts BindingOptions) (bool, error) {
+	old, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("read %s: %w", path, err)
+	}
+	updated, err := installBlock(string(old), BindingsBlock(opts))
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+	if updated == string(old) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+		if err := os.WriteFile(BackupPath(path), old, mode); err != nil {
+			return false, fmt.Errorf("back up %s: %w", path, err)
+		}
+	}
+	if err := os.WriteFile(path, []byte(updated), mode); err != nil {
+		return false, fmt.Errorf("write %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// serverSockets lists the sockets of this user's tmux servers: every socket
+// in $TMUX_TMPDIR/tmux-UID (TMUX_TMPDIR defaults to /tmp), plus the one in
+// $TMUX, which may live elsewhere when the server was started with -S.
+func serverSockets() ([]string, error) {
+	var sockets []string
+	if cur, _, _ := strings.Cut(os.Getenv("TMUX"), ","); cur != "" {
+		sockets = append(sockets, cur)
+	}
+	base := os.Getenv("TMUX_TMPDIR")
+	if base == "" {
+		base = "/tmp"
+	}
+	dir := filepath.Join(base, "tmux-"+strconv.Itoa(os.Getuid()))
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return sockets, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list tmux sockets: %w", err)
+	}
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if e.Type()&os.ModeSocket != 0 && (len(sockets) == 0 || path != sockets[0]) {
+			sockets = append(sockets, path)
+		}
+	}
+	return sockets, nil
+}
+
+// ReloadServers sources path into every running tmux server of this user
+// and returns how many took it. Stale sockets are skipped.
+func ReloadServers(path string) (int, error) {
+	sockets, err := serverSockets()
+	if err != nil {
+		return 0, err
+	}
+	reloaded := 0
+	for _, sock := range sockets {
+		out, err := exec.Command("tmux", "-S", sock, "source-file", path).CombinedOutput()
+		if err != nil {
+			log.Printf("ReloadServers: %s: %v: %s", sock, err, strings.TrimSpace(string(out)))
+			continue
+		}
+		reloaded++
+	}
+	return reloaded, nil
+}
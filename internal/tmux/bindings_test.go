@@ -0,0 +1,101 @@
+package tmux
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBindingsQuotesExecutable(t *testing.T) {
+	opts := BindingOptions{Exe: "/opt/my tools/ide", SearchKey: "a", NextAgentKey: "N", PopupWidth: "60%", PopupHeight: "40"}
+	got := Bindings(opts)
+	want := "bind-key a display-popup -E -w 60% -h 40 ''\\''/opt/my tools/ide'\\'' --search'\n" +
+		"bind-key N run-shell -b ''\\''/opt/my tools/ide'\\'' agent next'\n"
+	if got != want {
+		t.Errorf("Bindings:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestInstallBlock(t *testing.T) {
+	block := BindingsBlock(BindingOptions{Exe: "ide", SearchKey: "a", PopupWidth: "80%", PopupHeight: "80%"})
+	tests := []struct {
+		name string
+		conf string
+		want string
+	}{
+		{"empty file", "", block},
+		{"appends after a blank line", "set -g mouse on", "set -g mouse on\n\n" + block},
+		{
+			"replaces the existing block in place",
+			"set -g mouse on\n" + bindingsBegin + "\nbind-key x kill-server\n" + bindingsEnd + "\nset -g base-index 1\n",
+			"set -g mouse on\n" + block + "set -g base-index 1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := installBlock(tt.conf, block)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("installBlock:\n got %q\nwant %q", got, tt.want)
+			}
+			if again, _ := installBlock(got, block); again != got {
+				t.Errorf("second install changed the file:\n%q", again)
+			}
+			if n := strings.Count(got, bindingsBegin); n != 1 {
+				t.Errorf("got %d managed blocks, want 1", n)
+			}
+		})
+	}
+}
+
+func TestInstallBindingsRefusesUnterminatedBlock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tmux.conf")
+	conf := "set -g mouse on\n" + bindingsBegin + "\nbind-key x kill-server\nset -g base-index 1\n"
+	if err := os.WriteFile(path, []byte(conf), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	opts := BindingOptions{Exe: "ide", SearchKey: "a", PopupWidth: "80%", PopupHeight: "80%"}
+	if _, err := InstallBindings(path, opts); err == nil || !strings.Contains(err.Error(), bindingsEnd) {
+		t.Fatalf("InstallBindings err = %v, want one naming the missing end marker", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != conf {
+		t.Errorf("file changed to %q", got)
+	}
+	if _, err := os.Stat(BackupPath(path)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("backup written for a refused install: %v", err)
+	}
+}
+
+func TestInstallBindingsBacksUpTheFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tmux.conf")
+	conf := "set -g mouse on\n"
+	if err := os.WriteFile(path, []byte(conf), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	opts := BindingOptions{Exe: "ide", SearchKey: "a", PopupWidth: "80%", PopupHeight: "80%"}
+	if changed, err := InstallBindings(path, opts); err != nil || !changed {
+		t.Fatalf("InstallBindings = %v, %v", changed, err)
+	}
+	backup, err := os.ReadFile(BackupPath(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(backup) != conf {
+		t.Errorf("backup = %q, want %q", backup, conf)
+	}
+	if info, err := os.Stat(BackupPath(path)); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("backup mode = %v, %v; want 0600", info.Mode().Perm(), err)
+	}
+}
+
+func TestBindingsWithoutPopup(t *testing.T) {
+	opts := BindingOptions{Exe: "ide", SearchKey: "a", WindowsKey: "w", PopupWidth: "80%", PopupHeight: "80%", NoPopup: true}
+	want := "bind-key a new-window 'ide --search'\nbind-key w new-window 'ide --windows'\n"
+	if got := Bindings(opts); got != want {
+		t.Errorf("Bindings:\n got %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,155 @@
+package tmux
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"ide/internal/config"
+)
+
+// buildStepOption is a session option the batched build updates after every
+// step, so when tmux aborts the chain the failure can be pinned on the step
+// that caused it. It is unset again once the whole chain has run, or once
+// a failure has been pinned (see failedBuildStep).
+const buildStepOption = "@ide_build_step"
+
+// buildStep is one tmux command of a session build.
+type buildStep struct {
+	desc string // what the step does, used as the error prefix
+	args []string
+}
+
+// sessionBuildSteps lists the tmux commands that create env's session: the
+// session with its first window and the session options, then one
+// new-window per remaining window, each followed by its window options.
+// Lazy windows are left for EnsureWindow. env must have at least one window.
+func sessionBuildSteps(env config.Environment) []buildStep {
+	session := SessionName(env.Name)
+	windows := eagerWindows(env)
+	steps := make([]buildStep, 0, len(windows))
+	for i, w := range windows {
+		if i == 0 {
+			step := buildStep{
+				desc: "create tmux session " + strconv.Quote(session),
+				args: []string{"new-session", "-d", "-s", session, "-n", SafeWindowName(w.Name)},
+			}
+			steps = append(steps, withWindowCommand(step, env, w))
+			steps = append(steps, sessionOptionSteps(session, env.TmuxOptions)...)
+			steps = append(steps, windowOptionSteps(session, SafeWindowName(w.Name), env.TmuxOptions, w.TmuxOptions)...)
+			steps = append(steps, typeCommandSteps(session, w)...)
+			continue
+		}
+		steps = append(steps, windowBuildSteps(env, w, nil)...)
+	}
+	return steps
+}
+
+// windowBuildSteps creates w in env's running session, followed by its
+// window options. placement, when set, is passed to new-window in place of
+// "-t session" to put the window somewhere other than the end.
+func windowBuildSteps(env config.Environment, w config.WindowTemplate, placement []string) []buildStep {
+	session := SessionName(env.Name)
+	name := SafeWindowName(w.Name)
+	if placement == nil {
+		placement = []string{"-t", session}
+	}
+	args := append([]string{"new-window"}, placement...)
+	step := buildStep{
+		desc: "create window " + strconv.Quote(name),
+		args: append(args, "-n", name),
+	}
+	steps := []buildStep{withWindowCommand(step, env, w)}
+	steps = append(steps, windowOptionSteps(session, name, env.TmuxOptions, w.TmuxOptions)...)
+	return append(steps, typeCommandSteps(session, w)...)
+}
+
+// withWindowCommand appends w's working directory and startup command to a
+// new-session/new-window step. A send-keys window starts tmux's default
+// shell; typeCommandSteps types its command in afterwards.
+func withWindowCommand(step buildStep, env config.Environment, w config.WindowTemplate) buildStep {
+	if cwd := resolveCwd(env.Root, w.Cwd); cwd != "" {
+		step.args = append(step.args, "-c", cwd)
+	}
+	if command := windowCommand(env, w); command != "" {
+		step.args = append(step.args, command)
+	}
+	return step
+}
+
+// typeCommandSteps types a send-keys window's command into the shell the
+// preceding new-session/new-window step left as the session's current
+// window. Other windows need no steps.
+func typeCommandSteps(session string, w config.WindowTemplate) []buildStep {
+	if !sendsKeys(w) {
+		return nil
+	}
+	var steps []buildStep
+	for _, args := range typeCommandArgs(session, w) {
+		steps = append(steps, buildStep{
+			desc: "type command into window " + strconv.Quote(SafeWindowName(w.Name)),
+			args: args,
+		})
+	}
+	return steps
+}
+
+// chainBuildSteps joins steps into the argv of a single tmux invocation,
+// recording each completed step in buildStepOption. tmux stops at the first
+// failing command, so nothing after a failure runs.
+func chainBuildSteps(session string, steps []buildStep) []string {
+	var args []string
+	for i, step := range steps {
+		for _, a := range step.args {
+			args = append(args, escapeCommandSeparator(a))
+		}
+		args = append(args, ";", "set-option", "-t", session, buildStepOption, strconv.Itoa(i), ";")
+	}
+	return append(args, "set-option", "-u", "-t", session, buildStepOption)
+}
+
+// runBuildSteps runs steps as a single tmux invocation. On failure tmux's
+// trimmed stderr is returned along with the error.
+func runBuildSteps(session string, steps []buildStep) (string, error) {
+	cmd := exec.Command("tmux", chainBuildSteps(session, steps)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return strings.TrimSpace(stderr.String()), err
+}
+
+// buildStepError pins a failed build on the step it stopped at, using
+// tmux's message when there is one.
+func buildStepError(session string, steps []buildStep, msg string, err error) error {
+	step := steps[failedBuildStep(session, len(steps))]
+	log.Printf("build %q: ERROR %s: %v: %s", session, step.desc, err, msg)
+	if msg != "" {
+		return fmt.Errorf("%s: %s", step.desc, msg)
+	}
+	return fmt.Errorf("%s: %w", step.desc, err)
+}
+
+// failedBuildStep works out which of n steps a failed build stopped at from
+// the last step it recorded, then clears the record so a later build of the
+// same session does not inherit it. No session (or no record) means the
+// first step failed.
+func failedBuildStep(session string, n int) int {
+	out, err := runTmux("show-options", "-v", "-t", session, buildStepOption)
+	if err != nil {
+		return 0
+	}
+	if _, err := runTmux("set-option", "-u", "-t", session, buildStepOption); err != nil {
+		log.Printf("build %q: clear %s: %v", session, buildStepOption, err)
+	}
+	done, err := strconv.Atoi(strings.TrimSpace(out))
+	if err != nil {
+		return 0
+	}
+	if done+1 >= n {
+		return n - 1
+	}
+	return done + 1
+}
@@ -0,0 +1,453 @@
+package tmux
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ide/internal/config"
+)
+
+// Fake is an in-memory Backend. It models sessions, their windows, pane
+// output and the processes running in each pane, and records what was sent
+// to it, so tests can drive attach/restart/kill flows and inspect the
+// result. The zero value is not usable; call NewFake.
+type Fake struct {
+	mu       sync.Mutex
+	sessions []*FakeSession
+	orphans  map[string][]PaneProcess
+
+	// Missing, when set, is returned by CheckTmuxExists.
+	Missing error
+	// Caps is what DetectCapabilities reports; NewFake assumes a tmux
+	// with every feature.
+	Caps Capabilities
+	// Survivors are the processes StopSession reports as orphans, per
+	// session; TerminateOrphans clears them.
+	Survivors map[string][]PaneProcess
+
+	// Calls lists every mutating operation in order, e.g.
+	// "ensure ide-api" or "send ide-api:agent [y Enter]".
+	Calls []string
+	// Sent records every SendKeys call.
+	Sent []FakeKeys
+}
+
+// FakeSession is one session of a Fake.
+type FakeSession struct {
+	Name    string
+	Windows []*FakeWindow
+	Active  string            // selected window
+	Builds  int               // how often EnsureSession created it
+	Skipped map[string]string // windows whose when condition failed at build time
+}
+
+// FakeWindow is one window (single pane) of a FakeSession.
+type FakeWindow struct {
+	Name      string
+	Cmd       string // startup command from the template
+	Command   string // foreground process name (pane_current_command)
+	Output    string // what CapturePane returns
+	Cols      int    // pane size; 0 means 80x24
+	Rows      int
+	Exit      *int          // exit status of the startup command, nil while running
+	Info      ProcessInfo   // what ProcessInfoByWindow returns for it
+	Processes []PaneProcess // non-shell processes under the pane's shell
+	Respawns  int
+}
+
+// FakeKeys is one recorded SendKeys call.
+type FakeKeys struct {
+	Target  string
+	Literal bool
+	Keys    []string
+}
+
+var _ Backend = (*Fake)(nil)
+
+// NewFake returns an empty Fake (no server running).
+func NewFake() *Fake {
+	return &Fake{
+		orphans:   map[string][]PaneProcess{},
+		Survivors: map[string][]PaneProcess{},
+		Caps:      CapabilitiesFor(Version{Raw: "fake", Dev: true}),
+	}
+}
+
+func (f *Fake) record(format string, a ...any) {
+	f.Calls = append(f.Calls, fmt.Sprintf(format, a...))
+}
+
+func (f *Fake) session(name string) *FakeSession {
+	for _, s := range f.sessions {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+func (s *FakeSession) window(name string) *FakeWindow {
+	for _, w := range s.Windows {
+		if w.Name == name || w.Name == SafeWindowName(name) {
+			return w
+		}
+	}
+	return nil
+}
+
+// resolve maps a "session" or "session:window" target to its window; a
+// bare session resolves to its active window.
+func (f *Fake) resolve(target string) (*FakeSession, *FakeWindow, error) {
+	name, window, hasWindow := strings.Cut(target, ":")
+	s := f.session(name)
+	if s == nil {
+		return nil, nil, fmt.Errorf("can't find session: %s", name)
+	}
+	if !hasWindow {
+		window = s.Active
+	}
+	w := s.window(window)
+	if w == nil {
+		return s, nil, fmt.Errorf("can't find window: %s", window)
+	}
+	return s, w, nil
+}
+
+// Session returns the named session, or nil. The returned value is live:
+// tests may edit it to script pane output, processes or exits.
+func (f *Fake) Session(name string) *FakeSession {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.session(name)
+}
+
+// Window returns a window of a session, or nil. Like Session it is live.
+func (f *Fake) Window(session, window string) *FakeWindow {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s := f.session(session); s != nil {
+		return s.window(window)
+	}
+	return nil
+}
+
+// SetExit marks a window's startup command as exited with code.
+func (f *Fake) SetExit(session, window string, code int) {
+	if w := f.Window(session, window); w != nil {
+		f.mu.Lock()
+		w.Exit = &code
+		f.mu.Unlock()
+	}
+}
+
+func (f *Fake) CheckTmuxExists() error { return f.Missing }
+
+func (f *Fake) DetectCapabilities() (Capabilities, error) { return f.Caps, nil }
+
+func (f *Fake) ListSessions() ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	names := make([]string, 0, len(f.sessions))
+	for _, s := range f.sessions {
+		names = append(names, s.Name)
+	}
+	return names, nil
+}
+
+func (f *Fake) ListWindows(session string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := f.session(session)
+	if s == nil {
+		return nil, nil
+	}
+	names := make([]string, 0, len(s.Windows))
+	for _, w := range s.Windows {
+		names = append(names, w.Name)
+	}
+	return names, nil
+}
+
+func (f *Fake) ListSessionsSnapshot() (SessionsSnapshot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	snap := SessionsSnapshot{
+		Windows:  map[string][]string{},
+		Commands: map[string]map[string]string{},
+		Exits:    map[string]map[string]int{},
+	}
+	for _, s := range f.sessions {
+		snap.Names = append(snap.Names, s.Name)
+		snap.Commands[s.Name] = map[string]string{}
+		for _, w := range s.Windows {
+			snap.Windows[s.Name] = append(snap.Windows[s.Name], w.Name)
+			snap.Commands[s.Name][w.Name] = w.Command
+			if w.Exit != nil {
+				if snap.Exits[s.Name] == nil {
+					snap.Exits[s.Name] = map[string]int{}
+				}
+				snap.Exits[s.Name][w.Name] = *w.Exit
+			}
+		}
+	}
+	return snap, nil
+}
+
+func (f *Fake) HasSession(session string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.session(session) != nil, nil
+}
+
+func (f *Fake) HasWindow(session, window string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := f.session(session)
+	return s != nil && s.window(window) != nil, nil
+}
+
+// SkippedWindows reads shell predicate outcomes from the session's Skipped
+// record instead of a tmux option.
+func (f *Fake) SkippedWindows(env config.Environment) map[string]string {
+	return skippedWindows(env, func(session string) map[string]string {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if s := f.session(session); s != nil {
+			return s.Skipped
+		}
+		return map[string]string{}
+	})
+}
+
+// fakeCommand is the process name a window's startup command shows up as.
+func fakeCommand(cmd string) string {
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 {
+		return "bash"
+	}
+	return commandName(fields[0])
+}
+
+func (f *Fake) EnsureSession(env config.Environment) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := SessionName(env.Name)
+	if f.session(name) != nil {
+		return nil
+	}
+	f.record("ensure %s", name)
+	env, skipped := enabledWindows(env)
+	if len(env.Windows) == 0 {
+		env.Windows = []config.WindowTemplate{{Name: "shell"}}
+	}
+	s := &FakeSession{Name: name, Builds: 1, Skipped: skipped}
+	for _, w := range eagerWindows(env) {
+		s.Windows = append(s.Windows, newFakeWindow(w))
+	}
+	s.Active = s.Windows[len(s.Windows)-1].Name
+	f.sessions = append(f.sessions, s)
+	return nil
+}
+
+func newFakeWindow(w config.WindowTemplate) *FakeWindow {
+	return &FakeWindow{Name: SafeWindowName(w.Name), Cmd: w.Cmd, Command: fakeCommand(w.Cmd)}
+}
+
+func (f *Fake) EnsureWindow(env config.Environment, windowName string) error {
+	w, ok := FindWindowTemplate(env, windowName)
+	if !ok || !w.Lazy {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := SafeWindowName(w.Name)
+	s := f.session(SessionName(env.Name))
+	if s == nil {
+		return fmt.Errorf("create window %q: session %q is not running", name, SessionName(env.Name))
+	}
+	if s.window(name) != nil {
+		return nil
+	}
+	if ok, reason := CheckCondition(env.Root, w.When); !ok {
+		return fmt.Errorf("create window %q: %s", name, reason)
+	}
+	f.record("create %s:%s", s.Name, name)
+	live := make([]string, 0, len(s.Windows))
+	for _, lw := range s.Windows {
+		live = append(live, lw.Name)
+	}
+	i := lazyWindowIndex(env, name, live)
+	s.Windows = append(s.Windows[:i], append([]*FakeWindow{newFakeWindow(w)}, s.Windows[i:]...)...)
+	s.Active = name
+	return nil
+}
+
+func (f *Fake) StopSession(env config.Environment) (StopResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := SessionName(env.Name)
+	if f.session(name) == nil {
+		return StopResult{}, nil
+	}
+	f.record("stop %s", name)
+	f.removeSession(name)
+	res := StopResult{Orphans: f.Survivors[name]}
+	if len(res.Orphans) > 0 {
+		f.orphans[name] = res.Orphans
+	}
+	delete(f.Survivors, name)
+	return res, nil
+}
+
+func (f *Fake) removeSession(name string) {
+	for i, s := range f.sessions {
+		if s.Name == name {
+			f.sessions = append(f.sessions[:i], f.sessions[i+1:]...)
+			return
+		}
+	}
+}
+
+func (f *Fake) KillSession(session string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("kill %s", session)
+	f.removeSession(session)
+	return nil
+}
+
+func (f *Fake) RespawnWindow(env config.Environment, w config.WindowTemplate) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	target := AttachTarget(env, w.Name)
+	_, win, err := f.resolve(target)
+	if err != nil {
+		return fmt.Errorf("respawn window %q: %w", target, err)
+	}
+	f.record("respawn %s", target)
+	win.Exit = nil
+	win.Respawns++
+	win.Command = fakeCommand(w.Cmd)
+	return nil
+}
+
+func (f *Fake) SwapWindow(session, src, dst string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := f.session(session)
+	if s == nil {
+		return nil
+	}
+	i, j := -1, -1
+	for k, w := range s.Windows {
+		switch w.Name {
+		case src:
+			i = k
+		case dst:
+			j = k
+		}
+	}
+	if i < 0 || j < 0 {
+		return fmt.Errorf("swap-window %s:%s -> %s:%s: can't find window", session, src, session, dst)
+	}
+	f.record("swap %s:%s %s", session, src, dst)
+	s.Windows[i], s.Windows[j] = s.Windows[j], s.Windows[i]
+	return nil
+}
+
+func (f *Fake) SelectWindow(target string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, w, err := f.resolve(target)
+	if err != nil {
+		return fmt.Errorf("select-window %s: %w", target, err)
+	}
+	f.record("select %s", target)
+	s.Active = w.Name
+	return nil
+}
+
+func (f *Fake) SendKeys(target string, literal bool, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, _, err := f.resolve(target); err != nil {
+		return fmt.Errorf("send-keys %s: %w", target, err)
+	}
+	f.record("send %s %v", target, keys)
+	f.Sent = append(f.Sent, FakeKeys{Target: target, Literal: literal, Keys: append([]string(nil), keys...)})
+	return nil
+}
+
+func (f *Fake) CapturePane(session, window string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, w, err := f.resolve(session + ":" + window)
+	if err != nil {
+		return "", fmt.Errorf("capture pane %q: %w", session+":"+window, err)
+	}
+	return w.Output, nil
+}
+
+func (f *Fake) PaneSize(session, window string) (int, int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, w, err := f.resolve(session + ":" + window)
+	if err != nil {
+		return 0, 0, err
+	}
+	if w.Cols <= 0 || w.Rows <= 0 {
+		return 80, 24, nil
+	}
+	return w.Cols, w.Rows, nil
+}
+
+func (f *Fake) CurrentProcess(session, window string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, w, err := f.resolve(session + ":" + window); err == nil {
+		return w.Command
+	}
+	return ""
+}
+
+func (f *Fake) ProcessInfoByWindow() (map[string]map[string]ProcessInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	infos := map[string]map[string]ProcessInfo{}
+	for _, s := range f.sessions {
+		infos[s.Name] = map[string]ProcessInfo{}
+		for _, w := range s.Windows {
+			infos[s.Name][w.Name] = w.Info
+		}
+	}
+	return infos, nil
+}
+
+func (f *Fake) SessionProcesses(session string) ([]PaneProcess, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := f.session(session)
+	if s == nil {
+		return nil, nil
+	}
+	var procs []PaneProcess
+	for _, w := range s.Windows {
+		procs = append(procs, w.Processes...)
+	}
+	return procs, nil
+}
+
+func (f *Fake) TerminateOrphans(session string, grace time.Duration) ([]PaneProcess, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.orphans[session]) > 0 {
+		f.record("terminate %s", session)
+	}
+	delete(f.orphans, session)
+	return nil, nil
+}
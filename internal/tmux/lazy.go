@@ -0,0 +1,112 @@
+package tmux
+
+import (
+	"fmt"
+	"log"
+	"slices"
+
+	"ide/internal/config"
+)
+
+// eagerWindows are the windows EnsureSession creates up front: every window
+// not marked lazy. A session cannot exist without a window, so when all of
+// them are lazy the first one is created anyway.
+func eagerWindows(env config.Environment) []config.WindowTemplate {
+	var out []config.WindowTemplate
+	for _, w := range env.Windows {
+		if !w.Lazy {
+			out = append(out, w)
+		}
+	}
+	if len(out) == 0 && len(env.Windows) > 0 {
+		out = env.Windows[:1]
+	}
+	return out
+}
+
+// IsLazyWindow reports whether windowName (config or tmux form) is one of
+// env's lazy windows.
+func IsLazyWindow(env config.Environment, windowName string) bool {
+	w, ok := FindWindowTemplate(env, windowName)
+	return ok && w.Lazy
+}
+
+// lazyWindowIndex is where the lazy window name belongs among live, the
+// windows of the running session: right after the nearest window configured
+// before it that is running, or first when there is none.
+func lazyWindowIndex(env config.Environment, name string, live []string) int {
+	pos := -1
+	for i, w := range env.Windows {
+		if SafeWindowName(w.Name) == name {
+			pos = i
+			break
+		}
+	}
+	for i := pos - 1; i >= 0; i-- {
+		prev := SafeWindowName(env.Windows[i].Name)
+		for j, l := range live {
+			if l == prev {
+				return j + 1
+			}
+		}
+	}
+	return 0
+}
+
+// WithLazyWindows adds env's lazy windows that have not been created yet to
+// live, the windows of its running session, each in its configured place.
+// This is the window list to show for a running session.
+func WithLazyWindows(env config.Environment, live []string) []string {
+	out := append([]string(nil), live...)
+	for _, w := range env.Windows {
+		if !w.Lazy {
+			continue
+		}
+		name := SafeWindowName(w.Name)
+		if slices.Contains(out, name) {
+			continue
+		}
+		i := lazyWindowIndex(env, name, out)
+		out = append(out[:i], append([]string{name}, out[i:]...)...)
+	}
+	return out
+}
+
+// EnsureWindow creates windowName in env's running session if it is a lazy
+// window that has not been created yet, in its configured place among the
+// windows that are running. Its when condition is checked only when the
+// window is about to be created. Other windows are left alone, so it is safe
+// to call before every attach.
+func EnsureWindow(env config.Environment, windowName string) error {
+	w, ok := FindWindowTemplate(env, windowName)
+	if !ok || !w.Lazy {
+		return nil
+	}
+	session := SessionName(env.Name)
+	name := SafeWindowName(w.Name)
+	live, err := ListWindows(session)
+	if err != nil {
+		return err
+	}
+	if len(live) == 0 {
+		return fmt.Errorf("create window %q: session %q is not running", name, session)
+	}
+	if slices.Contains(live, name) {
+		return nil
+	}
+	if ok, reason := CheckCondition(env.Root, w.When); !ok {
+		return fmt.Errorf("create window %q: %s", name, reason)
+	}
+	var placement []string
+	if i := lazyWindowIndex(env, name, live); i > 0 {
+		placement = []string{"-a", "-t", session + ":" + live[i-1]}
+	} else if caps, _ := DetectCapabilities(); caps.NewWindowBefore {
+		placement = []string{"-b", "-t", session + ":" + live[0]}
+	}
+	steps := windowBuildSteps(env, w, placement)
+	log.Printf("EnsureWindow: session=%q window=%q placement=%v", session, name, placement)
+	if msg, err := runBuildSteps(session, steps); err != nil {
+		return buildStepError(session, steps, msg, err)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package tmux
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// optionKind is the shape of value a tmux option accepts.
+type optionKind int
+
+const (
+	optionString optionKind = iota
+	optionFlag              // on/off
+	optionNumber            // non-negative integer
+	optionChoice            // one of optionSpec.choices
+)
+
+// optionSpec describes one option ide knows how to set.
+type optionSpec struct {
+	window  bool // set with set-option -w on each window rather than on the session
+	kind    optionKind
+	choices []string
+}
+
+// knownOptions are the tmux options accepted in tmux_options. The list is
+// deliberately closed: a typo would otherwise surface as a tmux error
+// halfway through building a session.
+var knownOptions = map[string]optionSpec{
+	// session options
+	"base-index":         {kind: optionNumber},
+	"bell-action":        {kind: optionChoice, choices: []string{"any", "none", "current", "other"}},
+	"activity-action":    {kind: optionChoice, choices: []string{"any", "none", "current", "other"}},
+	"destroy-unattached": {kind: optionFlag},
+	"detach-on-destroy":  {kind: optionChoice, choices: []string{"on", "off", "no-detached", "previous", "next"}},
+	"display-time":       {kind: optionNumber},
+	"history-limit":      {kind: optionNumber},
+	"mouse":              {kind: optionFlag},
+	"renumber-windows":   {kind: optionFlag},
+	"repeat-time":        {kind: optionNumber},
+	"set-titles":         {kind: optionFlag},
+	"set-titles-string":  {kind: optionString},
+	"status":             {kind: optionChoice, choices: []string{"on", "off", "2", "3", "4", "5"}},
+	"status-interval":    {kind: optionNumber},
+	"status-justify":     {kind: optionChoice, choices: []string{"left", "centre", "right", "absolute-centre"}},
+	"status-left":        {kind: optionString},
+	"status-position":    {kind: optionChoice, choices: []string{"top", "bottom"}},
+	"status-right":       {kind: optionString},
+	"status-style":       {kind: optionString},
+	"visual-activity":    {kind: optionChoice, choices: []string{"on", "off", "both"}},
+	"visual-bell":        {kind: optionChoice, choices: []string{"on", "off", "both"}},
+
+	// window options
+	"aggressive-resize":            {window: true, kind: optionFlag},
+	"allow-rename":                 {window: true, kind: optionFlag},
+	"automatic-rename":             {window: true, kind: optionFlag},
+	"automatic-rename-format":      {window: true, kind: optionString},
+	"clock-mode-style":             {window: true, kind: optionChoice, choices: []string{"12", "24"}},
+	"main-pane-height":             {window: true, kind: optionString},
+	"main-pane-width":              {window: true, kind: optionString},
+	"mode-keys":                    {window: true, kind: optionChoice, choices: []string{"vi", "emacs"}},
+	"monitor-activity":             {window: true, kind: optionFlag},
+	"monitor-bell":                 {window: true, kind: optionFlag},
+	"monitor-silence":              {window: true, kind: optionNumber},
+	"pane-active-border-style":     {window: true, kind: optionString},
+	"pane-base-index":              {window: true, kind: optionNumber},
+	"pane-border-format":           {window: true, kind: optionString},
+	"pane-border-status":           {window: true, kind: optionChoice, choices: []string{"off", "top", "bottom"}},
+	"pane-border-style":            {window: true, kind: optionString},
+	"remain-on-exit":               {window: true, kind: optionChoice, choices: []string{"on", "off", "failed"}},
+	"synchronize-panes":            {window: true, kind: optionFlag},
+	"window-size":                  {window: true, kind: optionChoice, choices: []string{"largest", "smallest", "manual", "latest"}},
+	"window-status-current-format": {window: true, kind: optionString},
+	"window-status-current-style":  {window: true, kind: optionString},
+	"window-status-format":         {window: true, kind: optionString},
+	"window-status-style":          {window: true, kind: optionString},
+	"wrap-search":                  {window: true, kind: optionFlag},
+}
+
+// KnownOptions lists the option names accepted in tmux_options, sorted.
+func KnownOptions() []string {
+	names := make([]string, 0, len(knownOptions))
+	for name := range knownOptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateOption checks one tmux_options entry. Session options are only
+// accepted at the environment level (forWindow false); window options are
+// accepted at both levels, an environment's applying to all its windows.
+func ValidateOption(name, value string, forWindow bool) error {
+	spec, ok := knownOptions[name]
+	if !ok {
+		return fmt.Errorf("unknown tmux option %q", name)
+	}
+	if forWindow && !spec.window {
+		return fmt.Errorf("tmux option %q is a session option; set it on the environment", name)
+	}
+	switch spec.kind {
+	case optionFlag:
+		switch value {
+		case "on", "off", "yes", "no", "1", "0":
+		default:
+			return fmt.Errorf("tmux option %q: %q is not on or off", name, value)
+		}
+	case optionNumber:
+		if n, err := strconv.Atoi(value); err != nil || n < 0 {
+			return fmt.Errorf("tmux option %q: %q is not a number", name, value)
+		}
+	case optionChoice:
+		for _, c := range spec.choices {
+			if value == c {
+				return nil
+			}
+		}
+		return fmt.Errorf("tmux option %q: %q is not one of %s", name, value, strings.Join(spec.choices, ", "))
+	}
+	return nil
+}
+
+// ValidateOptions checks every entry of opts, in name order.
+func ValidateOptions(opts map[string]string, forWindow bool) error {
+	for _, name := range sortedOptionNames(opts) {
+		if err := ValidateOption(name, opts[name], forWindow); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedOptionNames(opts map[string]string) []string {
+	names := make([]string, 0, len(opts))
+	for name := range opts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sessionOptionSteps turns the session options among an environment's
+// tmux_options into set-option build steps. Invalid entries are logged and
+// skipped so a hand-edited typo cannot stop a session from coming up. Note
+// that tmux reads history-limit when a pane is created, so the first window
+// keeps the server default.
+func sessionOptionSteps(session string, envOpts map[string]string) []buildStep {
+	var steps []buildStep
+	for _, name := range sortedOptionNames(envOpts) {
+		if spec, ok := knownOptions[name]; ok && spec.window {
+			continue
+		}
+		if err := ValidateOption(name, envOpts[name], false); err != nil {
+			log.Printf("EnsureSession: %s: skipping: %v", session, err)
+			continue
+		}
+		steps = append(steps, buildStep{
+			desc: "set option " + strconv.Quote(name) + " on session " + strconv.Quote(session),
+			args: []string{"set-option", "-t", session, name, envOpts[name]},
+		})
+	}
+	return steps
+}
+
+// windowOptionSteps sets the window options of envOpts, overridden by
+// winOpts, on the session's current window — the one the preceding
+// new-session/new-window step just created. Invalid entries are skipped
+// like in sessionOptionSteps.
+func windowOptionSteps(session, window string, envOpts, winOpts map[string]string) []buildStep {
+	merged := map[string]string{}
+	for name, value := range envOpts {
+		if spec, ok := knownOptions[name]; ok && spec.window {
+			merged[name] = value
+		}
+	}
+	for name, value := range winOpts {
+		merged[name] = value
+	}
+	var steps []buildStep
+	for _, name := range sortedOptionNames(merged) {
+		if err := ValidateOption(name, merged[name], true); err != nil {
+			log.Printf("EnsureSession: %s window %q: skipping: %v", session, window, err)
+			continue
+		}
+		steps = append(steps, buildStep{
+			desc: "set option " + strconv.Quote(name) + " on window " + strconv.Quote(window),
+			args: []string{"set-option", "-w", "-t", session, name, merged[name]},
+		})
+	}
+	return steps
+}
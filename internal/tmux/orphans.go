@@ -0,0 +1,235 @@
+package tmux
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"ide/internal/state"
+)
+
+// orphansFile is the state file holding processes that outlived their
+// session, keyed by session name.
+const orphansFile = "orphans.json"
+
+// orphanSettle is how long StopSession gives the session's processes to
+// react to the kill (SIGHUP) before calling survivors orphans.
+const orphanSettle = time.Second
+
+// OrphanGrace is how long orphans get to exit after SIGTERM before they
+// are sent SIGKILL.
+const OrphanGrace = 3 * time.Second
+
+// orphanExpiry is how long a session's orphans are remembered. Past it
+// they are forgotten rather than risk signalling whatever reuses the PIDs.
+const orphanExpiry = 24 * time.Hour
+
+// OrphanRecord is what the state file keeps per session.
+type OrphanRecord struct {
+	KilledAt  time.Time     `json:"killed_at"`
+	Processes []PaneProcess `json:"processes"`
+}
+
+// shellNames are the shells paneTree leaves out. A shell that outlives its
+// session has usually nothing left to run, and one that matches a recorded
+// PID later is more likely the user's own than ours.
+var shellNames = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "fish": true, "dash": true,
+	"ksh": true, "mksh": true, "tcsh": true, "csh": true, "nu": true,
+}
+
+func paneProcess(window string, row procRow) PaneProcess {
+	return PaneProcess{Window: window, PID: row.pid, Command: commandName(row.comm), Started: row.started}
+}
+
+// paneTree returns the processes in the panes' trees that could become
+// orphans: the pane processes and their descendants, shells excepted.
+func paneTree(panes []sessionPane, rows map[int]procRow) []PaneProcess {
+	children := buildChildMap(rows)
+	var procs []PaneProcess
+	for _, p := range panes {
+		tree := paneDescendants(rows, children, p.pid)
+		if row, ok := rows[p.pid]; ok {
+			tree = append([]procRow{row}, tree...)
+		}
+		for _, row := range tree {
+			if !shellNames[commandName(row.comm)] {
+				procs = append(procs, paneProcess(p.window, row))
+			}
+		}
+	}
+	return procs
+}
+
+// mergeProcesses appends the processes of b not already in a (by PID).
+func mergeProcesses(a, b []PaneProcess) []PaneProcess {
+	seen := make(map[int]bool, len(a))
+	for _, p := range a {
+		seen[p.PID] = true
+	}
+	for _, p := range b {
+		if !seen[p.PID] {
+			a = append(a, p)
+			seen[p.PID] = true
+		}
+	}
+	return a
+}
+
+func commandName(comm string) string {
+	return strings.TrimPrefix(filepath.Base(comm), "-")
+}
+
+// stillAlive filters recorded to the processes present in rows under the
+// same command name and start time, so a recycled PID is not mistaken for
+// a survivor. A process whose start time is unknown on either side cannot
+// be told apart from a recycled one and counts as gone. Zombies are dead,
+// just not reaped yet.
+func stillAlive(recorded []PaneProcess, rows map[int]procRow) []PaneProcess {
+	var alive []PaneProcess
+	for _, p := range recorded {
+		row, ok := rows[p.PID]
+		if !ok || row.state == "Z" || commandName(row.comm) != p.Command {
+			continue
+		}
+		if p.Started.IsZero() || row.started.IsZero() || !sameStart(p.Started, row.started) {
+			continue
+		}
+		alive = append(alive, p)
+	}
+	return alive
+}
+
+// sameStart reports whether two readings of a process's start time agree.
+// They are derived from the boot time (or printed by ps to the second), so
+// they may differ by up to a second.
+func sameStart(a, b time.Time) bool {
+	d := a.Sub(b)
+	return d >= -time.Second && d <= time.Second
+}
+
+// waitForOrphans re-reads the process table until none of recorded is
+// alive or orphanSettle passes, and returns the survivors.
+func waitForOrphans(recorded []PaneProcess) []PaneProcess {
+	deadline := time.Now().Add(orphanSettle)
+	for {
+		rows, err := snapshotProcesses()
+		if err != nil {
+			log.Printf("waitForOrphans: %v", err)
+			return nil
+		}
+		alive := stillAlive(recorded, rows)
+		if len(alive) == 0 || !time.Now().Before(deadline) {
+			return alive
+		}
+		time.Sleep(stopPollInterval)
+	}
+}
+
+// loadOrphanRecords reads the state file, dropping the records older than
+// orphanExpiry.
+func loadOrphanRecords() (map[string]OrphanRecord, error) {
+	records := map[string]OrphanRecord{}
+	if err := state.ReadJSON(orphansFile, &records); err != nil {
+		return nil, err
+	}
+	for session, rec := range records {
+		if time.Since(rec.KilledAt) > orphanExpiry {
+			delete(records, session)
+		}
+	}
+	return records, nil
+}
+
+// recordOrphans stores (or, with none, clears) session's orphans.
+func recordOrphans(session string, procs []PaneProcess, at time.Time) error {
+	records, err := loadOrphanRecords()
+	if err != nil {
+		return err
+	}
+	if len(procs) == 0 {
+		if _, ok := records[session]; !ok {
+			return nil
+		}
+		delete(records, session)
+	} else {
+		records[session] = OrphanRecord{KilledAt: at, Processes: procs}
+	}
+	return state.WriteJSON(orphansFile, records)
+}
+
+// Orphans returns the recorded orphans of session that are still alive,
+// pruning the ones that have since exited.
+func Orphans(session string) ([]PaneProcess, error) {
+	records, err := loadOrphanRecords()
+	if err != nil {
+		return nil, err
+	}
+	rec, ok := records[session]
+	if !ok {
+		return nil, nil
+	}
+	rows, err := snapshotProcesses()
+	if err != nil {
+		return nil, err
+	}
+	alive := stillAlive(rec.Processes, rows)
+	if len(alive) != len(rec.Processes) {
+		if err := recordOrphans(session, alive, rec.KilledAt); err != nil {
+			return alive, err
+		}
+	}
+	return alive, nil
+}
+
+// TerminateOrphans sends SIGTERM to session's live orphans, waits up to
+// grace (normally OrphanGrace) for them to exit, then SIGKILLs the rest.
+// Each signal goes out only to processes a fresh snapshot still matches
+// (see stillAlive). It returns the processes it could not get rid of.
+func TerminateOrphans(session string, grace time.Duration) ([]PaneProcess, error) {
+	orphans, err := Orphans(session)
+	if err != nil || len(orphans) == 0 {
+		return nil, err
+	}
+	signalAll(orphans, syscall.SIGTERM)
+	deadline := time.Now().Add(grace)
+	var alive []PaneProcess
+	for {
+		rows, err := snapshotProcesses()
+		if err != nil {
+			return orphans, err
+		}
+		alive = stillAlive(orphans, rows)
+		if len(alive) == 0 || !time.Now().Before(deadline) {
+			break
+		}
+		time.Sleep(stopPollInterval)
+	}
+	if len(alive) > 0 {
+		signalAll(alive, syscall.SIGKILL)
+		alive = waitForOrphans(alive)
+	}
+	if err := recordOrphans(session, alive, time.Now()); err != nil {
+		return alive, err
+	}
+	if len(alive) > 0 {
+		return alive, fmt.Errorf("%d processes could not be terminated", len(alive))
+	}
+	return nil, nil
+}
+
+func signalAll(procs []PaneProcess, sig os.Signal) {
+	for _, p := range procs {
+		proc, err := os.FindProcess(p.PID)
+		if err != nil {
+			continue
+		}
+		if err := proc.Signal(sig); err != nil {
+			log.Printf("signal %v to pid %d (%s): %v", sig, p.PID, p.Command, err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package tmux
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPaneTreeSkipsShells(t *testing.T) {
+	start := time.Unix(1000, 0)
+	rows := map[int]procRow{
+		100: {pid: 100, ppid: 1, comm: "-zsh", started: start},
+		110: {pid: 110, ppid: 100, comm: "node", started: start},
+		120: {pid: 120, ppid: 100, comm: "bash", started: start},
+		121: {pid: 121, ppid: 120, comm: "esbuild", started: start},
+		999: {pid: 999, ppid: 1, comm: "unrelated"},
+	}
+	got := paneTree([]sessionPane{{id: "%1", window: "server", pid: 100}}, rows)
+	want := []PaneProcess{
+		{Window: "server", PID: 110, Command: "node", Started: start},
+		{Window: "server", PID: 121, Command: "esbuild", Started: start},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("paneTree = %+v, want %+v", got, want)
+	}
+}
+
+func TestStillAliveIgnoresRecycledPIDs(t *testing.T) {
+	start := time.Unix(1000, 0)
+	recorded := []PaneProcess{
+		{Window: "server", PID: 110, Command: "node", Started: start},
+		{Window: "server", PID: 120, Command: "esbuild", Started: start},
+		{Window: "db", PID: 130, Command: "postgres", Started: start},
+		{Window: "worker", PID: 140, Command: "python", Started: start},
+		{Window: "queue", PID: 150, Command: "redis", Started: start},
+		{Window: "legacy", PID: 160, Command: "ruby"},
+		{Window: "tail", PID: 170, Command: "tail", Started: start},
+	}
+	rows := map[int]procRow{
+		110: {pid: 110, ppid: 1, comm: "/usr/bin/node", started: start.Add(300 * time.Millisecond)}, // reparented, still ours
+		120: {pid: 120, ppid: 1, comm: "cron", started: start},                                      // PID reused by something else
+		140: {pid: 140, ppid: 1, comm: "python", state: "Z", started: start},
+		150: {pid: 150, ppid: 1, comm: "redis", started: start.Add(time.Hour)}, // reused by the same command
+		160: {pid: 160, ppid: 1, comm: "ruby", started: start},                 // recorded without a start time
+		170: {pid: 170, ppid: 1, comm: "tail"},                                 // ps could not say
+	}
+	got := stillAlive(recorded, rows)
+	want := []PaneProcess{{Window: "server", PID: 110, Command: "node", Started: start}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("stillAlive = %+v, want %+v", got, want)
+	}
+}
+
+func TestParsePS(t *testing.T) {
+	out := "  1     0  0.2  9852 Ss   Sun Oct 18 13:11:10 2026 launchd\n" +
+		"412     1 12.5  2048 R+   Mon Oct  5 09:01:02 2026 Google Chrome Helper\n" +
+		"bogus line\n"
+	rows := parsePS(out)
+	if len(rows) != 2 {
+		t.Fatalf("parsePS rows = %+v, want 2", rows)
+	}
+	got := rows[412]
+	want := procRow{pid: 412, ppid: 1, cpu: 12.5, state: "R", comm: "Google Chrome Helper", rss: 2048 * 1024,
+		started: time.Date(2026, time.October, 5, 9, 1, 2, 0, time.Local)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePS[412] = %+v, want %+v", got, want)
+	}
+}
+
+func TestRecordOrphansRoundTrip(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	at := time.Now().UTC().Truncate(time.Second)
+	procs := []PaneProcess{{Window: "server", PID: 110, Command: "node"}}
+	if err := recordOrphans("ide-a", procs, at); err != nil {
+		t.Fatal(err)
+	}
+	if err := recordOrphans("ide-b", procs, at); err != nil {
+		t.Fatal(err)
+	}
+	if err := recordOrphans("ide-b", nil, at); err != nil {
+		t.Fatal(err)
+	}
+	records, err := loadOrphanRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]OrphanRecord{"ide-a": {KilledAt: at, Processes: procs}}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %+v, want %+v", records, want)
+	}
+}
+
+func TestOrphanRecordsExpire(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	procs := []PaneProcess{{Window: "server", PID: 110, Command: "node"}}
+	if err := recordOrphans("ide-old", procs, time.Now().Add(-orphanExpiry-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	records, err := loadOrphanRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 0 {
+		t.Errorf("records = %+v, want the expired one dropped", records)
+	}
+}
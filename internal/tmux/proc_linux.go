@@ -0,0 +1,173 @@
+//go:build linux
+
+package tmux
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clockTicks is USER_HZ, the unit of the CPU times in /proc/<pid>/stat.
+// It is 100 on every Linux architecture Go supports; reading the real value
+// would take sysconf(_SC_CLK_TCK) and cgo.
+const clockTicks = 100
+
+// minCPUWindow is the shortest interval a CPU rate is computed over. Agent
+// windows are sampled concurrently, so snapshots can land milliseconds
+// apart; a rate over such a sliver is noise, so those reuse the last one.
+const minCPUWindow = 250 * time.Millisecond
+
+// snapshotProcesses reads the process table from /proc: no fork, and the
+// CPU of each process is its usage since the previous snapshot rather than
+// ps's lifetime average. It falls back to ps when /proc cannot be read
+// (e.g. mounted with hidepid or absent in a container).
+func snapshotProcesses() (map[int]procRow, error) {
+	rows, err := snapshotProc("/proc", time.Now())
+	if err != nil {
+		return snapshotPS()
+	}
+	return rows, nil
+}
+
+// cpuSample is one process's CPU time at a point in time.
+type cpuSample struct {
+	ticks uint64 // utime+stime
+	start uint64 // starttime: tells a reused pid from the process sampled
+	at    time.Time
+	rate  float64 // %CPU computed when this sample became the base
+}
+
+// procCPU remembers the last sample of every process between snapshots.
+var procCPU = struct {
+	sync.Mutex
+	last map[int]cpuSample
+}{last: map[int]cpuSample{}}
+
+// snapshotProc reads every /proc/<pid>/stat under root.
+func snapshotProc(root string, now time.Time) (map[int]procRow, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", root, err)
+	}
+	uptime, err := readUptime(filepath.Join(root, "uptime"))
+	if err != nil {
+		return nil, err
+	}
+	pageSize := int64(os.Getpagesize())
+	boot := now.Add(-uptime)
+
+	rows := make(map[int]procRow, len(entries))
+	ticks := make(map[int]procTimes, len(entries))
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(root, e.Name(), "stat"))
+		if err != nil {
+			continue // exited since ReadDir
+		}
+		row, times, err := parseProcStat(b, pageSize)
+		if err != nil {
+			continue
+		}
+		row.started = boot.Add(time.Duration(times.start) * time.Second / clockTicks)
+		rows[pid] = row
+		ticks[pid] = times
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("no readable processes in " + root)
+	}
+
+	procCPU.Lock()
+	defer procCPU.Unlock()
+	next := make(map[int]cpuSample, len(rows))
+	for pid, times := range ticks {
+		row := rows[pid]
+		row.cpu, next[pid] = cpuRate(procCPU.last[pid], times, uptime, now)
+		rows[pid] = row
+	}
+	procCPU.last = next
+	return rows, nil
+}
+
+// cpuRate returns the process's %CPU and the sample to keep for next time.
+// Without a usable previous sample (a new process, or a pid that was
+// reused) it is the lifetime average, like ps.
+func cpuRate(prev cpuSample, times procTimes, uptime time.Duration, now time.Time) (float64, cpuSample) {
+	cur := cpuSample{ticks: times.cpu, start: times.start, at: now}
+	if prev.at.IsZero() || prev.start != times.start || times.cpu < prev.ticks {
+		age := uptime.Seconds() - float64(times.start)/clockTicks
+		if age > 0 {
+			cur.rate = float64(times.cpu) / clockTicks / age * 100
+		}
+		return cur.rate, cur
+	}
+	elapsed := now.Sub(prev.at)
+	if elapsed < minCPUWindow {
+		return prev.rate, prev
+	}
+	cur.rate = float64(times.cpu-prev.ticks) / clockTicks / elapsed.Seconds() * 100
+	return cur.rate, cur
+}
+
+// procTimes are the CPU fields of /proc/<pid>/stat, in clock ticks.
+type procTimes struct {
+	cpu   uint64 // utime+stime
+	start uint64 // starttime, since boot
+}
+
+// parseProcStat parses one /proc/<pid>/stat line (see proc(5)). comm is
+// in parentheses and may itself contain spaces and parentheses, so the
+// fields after it are found from the last ')'.
+func parseProcStat(b []byte, pageSize int64) (procRow, procTimes, error) {
+	open := bytes.IndexByte(b, '(')
+	end := bytes.LastIndexByte(b, ')')
+	if open < 0 || end < open {
+		return procRow{}, procTimes{}, errors.New("malformed stat")
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b[:open])))
+	if err != nil {
+		return procRow{}, procTimes{}, fmt.Errorf("stat pid: %w", err)
+	}
+	// fields[0] is field 3 of proc(5), state.
+	fields := strings.Fields(string(b[end+1:]))
+	if len(fields) < 22 {
+		return procRow{}, procTimes{}, errors.New("short stat")
+	}
+	num := func(i int) uint64 {
+		n, _ := strconv.ParseUint(fields[i], 10, 64)
+		return n
+	}
+	ppid, _ := strconv.Atoi(fields[1])
+	row := procRow{
+		pid:     pid,
+		ppid:    ppid,
+		state:   fields[0],
+		comm:    string(b[open+1 : end]),
+		threads: int(num(17)),
+		rss:     int64(num(21)) * pageSize,
+	}
+	return row, procTimes{cpu: num(11) + num(12), start: num(19)}, nil
+}
+
+// readUptime reads the seconds since boot from /proc/uptime.
+func readUptime(path string) (time.Duration, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("read uptime: %w", err)
+	}
+	first, _, _ := strings.Cut(strings.TrimSpace(string(b)), " ")
+	secs, err := strconv.ParseFloat(first, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse uptime %q: %w", first, err)
+	}
+	return time.Duration(secs * float64(time.Second)), nil
+}
@@ -0,0 +1,105 @@
+//go:build linux
+
+package tmux
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseProcStat(t *testing.T) {
+	line := "4242 (tmux: server (x)) S 1 4242 4242 0 -1 4194560 100 0 0 0 250 50 0 0 20 0 3 0 1000 123456 25 18446744073709551615\n"
+	row, times, err := parseProcStat([]byte(line), 4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := procRow{pid: 4242, ppid: 1, state: "S", comm: "tmux: server (x)", threads: 3, rss: 25 * 4096}
+	if row != want {
+		t.Errorf("row = %+v, want %+v", row, want)
+	}
+	if times != (procTimes{cpu: 300, start: 1000}) {
+		t.Errorf("times = %+v, want cpu 300 start 1000", times)
+	}
+	for _, bad := range []string{"", "12 (x", "12 (x) S 1"} {
+		if _, _, err := parseProcStat([]byte(bad), 4096); err == nil {
+			t.Errorf("parseProcStat(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+// writeProc fakes a /proc with one process, started start ticks after
+// boot, whose utime+stime is ticks.
+func writeProc(t *testing.T, root string, uptime float64, start, ticks int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(root, "7"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	stat := fmt.Sprintf("7 (claude) R 1 7 7 0 -1 0 0 0 0 0 %d 0 0 0 20 0 12 0 %d 0 100 0\n", ticks, start)
+	if err := os.WriteFile(filepath.Join(root, "7", "stat"), []byte(stat), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "uptime"), []byte(fmt.Sprintf("%.2f 0.00\n", uptime)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSnapshotProcComputesCPUDeltas(t *testing.T) {
+	procCPU.Lock()
+	procCPU.last = map[int]cpuSample{}
+	procCPU.Unlock()
+	root := t.TempDir()
+	t0 := time.Now()
+
+	// Started at 5s of uptime, 100s ago, with 10s of CPU: 10% lifetime.
+	writeProc(t, root, 105, 500, 1000)
+	rows, err := snapshotProc(root, t0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rows[7].cpu; math.Abs(got-10) > 0.01 {
+		t.Errorf("first sample cpu = %.2f, want the 10%% lifetime average", got)
+	}
+	if rows[7].threads != 12 || rows[7].comm != "claude" {
+		t.Errorf("row = %+v", rows[7])
+	}
+	if want := t0.Add(-100 * time.Second); !rows[7].started.Equal(want) {
+		t.Errorf("started = %v, want %v", rows[7].started, want)
+	}
+
+	// 1.6s of CPU over the next 2s: 80% now, though the lifetime average
+	// has barely moved.
+	writeProc(t, root, 107, 500, 1160)
+	rows, err = snapshotProc(root, t0.Add(2*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rows[7].cpu; math.Abs(got-80) > 0.01 {
+		t.Errorf("second sample cpu = %.2f, want 80", got)
+	}
+
+	// A snapshot right after keeps the last rate instead of dividing by
+	// a sliver of time.
+	writeProc(t, root, 107, 500, 1161)
+	rows, err = snapshotProc(root, t0.Add(2*time.Second+10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rows[7].cpu; math.Abs(got-80) > 0.01 {
+		t.Errorf("close sample cpu = %.2f, want the previous 80", got)
+	}
+
+	// The pid now belongs to a process started at 90s with 12s of CPU:
+	// its lifetime average, not a delta against the old process.
+	writeProc(t, root, 110, 9000, 1200)
+	rows, err = snapshotProc(root, t0.Add(5*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rows[7].cpu; math.Abs(got-60) > 0.01 {
+		t.Errorf("reused pid cpu = %.2f, want the new process's 60%% lifetime average", got)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package tmux
+
+// snapshotProcesses returns the full process table keyed by PID. Without
+// /proc that is one `ps` run.
+func snapshotProcesses() (map[int]procRow, error) {
+	return snapshotPS()
+}
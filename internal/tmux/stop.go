@@ -0,0 +1,226 @@
+package tmux
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ide/internal/config"
+)
+
+// DefaultStopTimeout is how long StopSession waits for window commands to
+// exit when the environment does not set stop_timeout.
+const DefaultStopTimeout = 10 * time.Second
+
+// stopPollInterval is how often StopSession re-reads the process table
+// while waiting for window commands to exit.
+const stopPollInterval = 200 * time.Millisecond
+
+// PaneProcess is a process running under one of a session's panes, below
+// the pane's own shell.
+type PaneProcess struct {
+	Window  string    `json:"window"`
+	PID     int       `json:"pid"`
+	Command string    `json:"command"`
+	Started time.Time `json:"started,omitzero"` // zero when the platform does not say
+}
+
+// StopResult reports what a graceful stop could not shut down cleanly.
+type StopResult struct {
+	Forced  []PaneProcess // still running when the stop timeout ran out
+	Orphans []PaneProcess // still alive after the session was killed
+}
+
+// sessionPane is one pane of a session, as listed by list-panes -s.
+type sessionPane struct {
+	id     string // %N, stable across window renames and reorders
+	window string
+	pid    int
+	shell  int // the wrapped fallback shell's pid (ShellPIDOption); 0 when none
+}
+
+func listSessionPanes(session string) ([]sessionPane, error) {
+	out, err := runTmux("list-panes", "-s", "-t", session, "-F", "#{pane_id}\t#{window_name}\t#{pane_pid}\t#{"+ShellPIDOption+"}")
+	if err != nil {
+		return nil, fmt.Errorf("list panes for %q: %w", session, err)
+	}
+	var panes []sessionPane
+	for _, line := range splitNonEmptyLines(out) {
+		parts := strings.SplitN(line, "\t", 4)
+		if len(parts) < 3 {
+			continue
+		}
+		pid, err := strconv.Atoi(parts[2])
+		if err != nil {
+			continue
+		}
+		p := sessionPane{id: parts[0], window: parts[1], pid: pid}
+		if len(parts) == 4 {
+			p.shell, _ = strconv.Atoi(parts[3])
+		}
+		panes = append(panes, p)
+	}
+	return panes, nil
+}
+
+// paneDescendants returns every process below root (excluding root itself)
+// ordered by PID, so the pane shell never counts as "still running".
+func paneDescendants(rows map[int]procRow, children map[int][]int, root int) []procRow {
+	var out []procRow
+	stack := append([]int(nil), children[root]...)
+	visited := map[int]bool{root: true}
+	for len(stack) > 0 {
+		n := len(stack) - 1
+		pid := stack[n]
+		stack = stack[:n]
+		if visited[pid] {
+			continue
+		}
+		visited[pid] = true
+		if row, ok := rows[pid]; ok {
+			out = append(out, row)
+		}
+		stack = append(stack, children[pid]...)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].pid < out[j].pid })
+	return out
+}
+
+// runningUnder lists the processes below each pane's own process, which is
+// the window's wrapper or interactive shell. Only that top-level process is
+// plumbing: a shell below it runs a command (a script, or the login shell
+// startupCommand wraps the command in) and is work like any other. The one
+// exception is the fallback shell of a prefix wrapper, which recorded its
+// pid: it and the wrapper processes above it are plumbing too, while what
+// runs in it is work.
+func runningUnder(panes []sessionPane, rows map[int]procRow) []PaneProcess {
+	children := buildChildMap(rows)
+	var procs []PaneProcess
+	for _, p := range panes {
+		idle := fallbackShellChain(rows, p)
+		for _, row := range paneDescendants(rows, children, p.pid) {
+			if !idle[row.pid] {
+				procs = append(procs, paneProcess(p.window, row))
+			}
+		}
+	}
+	return procs
+}
+
+// fallbackShellChain returns p's recorded fallback shell and its ancestors
+// below the pane's own process. A recorded pid that is not below the pane
+// (the shell exited and the pid went to another process) yields nothing.
+func fallbackShellChain(rows map[int]procRow, p sessionPane) map[int]bool {
+	chain := map[int]bool{}
+	for pid := p.shell; pid > 0 && !chain[pid]; pid = rows[pid].ppid {
+		if pid == p.pid {
+			return chain
+		}
+		if _, ok := rows[pid]; !ok {
+			break
+		}
+		chain[pid] = true
+	}
+	return nil
+}
+
+// SessionProcesses lists what is still running inside session's panes,
+// window by window. The panes' own shells are skipped, so idle panes
+// contribute nothing.
+func SessionProcesses(session string) ([]PaneProcess, error) {
+	panes, err := listSessionPanes(session)
+	if err != nil {
+		return nil, err
+	}
+	if len(panes) == 0 {
+		return nil, nil
+	}
+	rows, err := snapshotProcesses()
+	if err != nil {
+		return nil, err
+	}
+	return runningUnder(panes, rows), nil
+}
+
+// StopTimeout resolves env's stop_timeout, falling back to
+// DefaultStopTimeout when it is unset or unparsable.
+func StopTimeout(env config.Environment) time.Duration {
+	s := strings.TrimSpace(env.StopTimeout)
+	if s == "" {
+		return DefaultStopTimeout
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		log.Printf("StopTimeout: env=%q invalid stop_timeout %q, using %s", env.Name, s, DefaultStopTimeout)
+		return DefaultStopTimeout
+	}
+	return d
+}
+
+// StopSession shuts env's session down gracefully: each window gets C-c (or
+// its stop_cmd) in reverse window order, then the pane process trees get up
+// to StopTimeout(env) to exit before the session is killed regardless.
+// The pane trees are recorded before the stop keys go out and again just
+// before the kill, so a server that reparents while shutting down is still
+// caught; anything recorded that is alive afterwards is reported and saved
+// as an orphan (see Orphans).
+func StopSession(env config.Environment) (StopResult, error) {
+	var res StopResult
+	session := SessionName(env.Name)
+	panes, err := listSessionPanes(session)
+	if err != nil {
+		return res, err
+	}
+	var tree []PaneProcess
+	if rows, err := snapshotProcesses(); err == nil {
+		tree = paneTree(panes, rows)
+	}
+	for i := len(panes) - 1; i >= 0; i-- {
+		p := panes[i]
+		tmpl, _ := FindWindowTemplate(env, p.window)
+		if stop := strings.TrimSpace(tmpl.StopCmd); stop != "" {
+			err = SendKeys(p.id, true, stop)
+			if err == nil {
+				err = SendKeys(p.id, false, "Enter")
+			}
+		} else {
+			err = SendKeys(p.id, false, "C-c")
+		}
+		if err != nil {
+			log.Printf("StopSession: %s window %q: %v", session, p.window, err)
+		}
+	}
+
+	deadline := time.Now().Add(StopTimeout(env))
+	for {
+		rows, err := snapshotProcesses()
+		if err != nil {
+			log.Printf("StopSession: %v", err)
+			break
+		}
+		res.Forced = runningUnder(panes, rows)
+		if len(res.Forced) == 0 || !time.Now().Before(deadline) {
+			tree = mergeProcesses(tree, paneTree(panes, rows))
+			break
+		}
+		time.Sleep(stopPollInterval)
+	}
+	if len(res.Forced) > 0 {
+		log.Printf("StopSession: %s still has %d processes after %s, killing", session, len(res.Forced), StopTimeout(env))
+	}
+	if err := KillSession(session); err != nil {
+		return res, err
+	}
+
+	res.Orphans = waitForOrphans(tree)
+	if len(res.Orphans) > 0 {
+		log.Printf("StopSession: %d processes of %s survived the kill", len(res.Orphans), session)
+	}
+	if err := recordOrphans(session, res.Orphans, time.Now()); err != nil {
+		log.Printf("StopSession: record orphans: %v", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,81 @@
+package tmux
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"ide/internal/config"
+)
+
+func TestRunningUnderSkipsOnlyPaneShells(t *testing.T) {
+	rows := map[int]procRow{
+		100: {pid: 100, ppid: 1, comm: "zsh"},
+		205: {pid: 205, ppid: 100, comm: "-bash"},
+		210: {pid: 210, ppid: 205, comm: "/usr/bin/node"},
+		220: {pid: 220, ppid: 210, comm: "esbuild"},
+		300: {pid: 300, ppid: 1, comm: "bash"},
+		400: {pid: 400, ppid: 1, comm: "sh"},
+		410: {pid: 410, ppid: 400, comm: "bash"},
+		999: {pid: 999, ppid: 1, comm: "unrelated"},
+	}
+	panes := []sessionPane{
+		{id: "%1", window: "server", pid: 100},
+		{id: "%2", window: "shell", pid: 300},
+		{id: "%3", window: "script", pid: 400},
+	}
+	got := runningUnder(panes, rows)
+	want := []PaneProcess{
+		{Window: "server", PID: 205, Command: "bash"},
+		{Window: "server", PID: 210, Command: "node"},
+		{Window: "server", PID: 220, Command: "esbuild"},
+		{Window: "script", PID: 410, Command: "bash"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("runningUnder = %+v, want %+v", got, want)
+	}
+}
+
+func TestRunningUnderSkipsTheWrappedFallbackShell(t *testing.T) {
+	rows := map[int]procRow{
+		100: {pid: 100, ppid: 1, comm: "sh"},
+		110: {pid: 110, ppid: 100, comm: "nix"},
+		120: {pid: 120, ppid: 110, comm: "zsh"},
+		130: {pid: 130, ppid: 120, comm: "vim"},
+		200: {pid: 200, ppid: 1, comm: "sh"},
+		210: {pid: 210, ppid: 200, comm: "nix"},
+		220: {pid: 220, ppid: 210, comm: "zsh"},
+	}
+	panes := []sessionPane{
+		{id: "%1", window: "busy", pid: 100, shell: 120},
+		{id: "%2", window: "idle", pid: 200, shell: 220},
+		{id: "%3", window: "stale", pid: 200, shell: 999},
+	}
+	got := runningUnder(panes, rows)
+	want := []PaneProcess{
+		{Window: "busy", PID: 130, Command: "vim"},
+		{Window: "stale", PID: 210, Command: "nix"},
+		{Window: "stale", PID: 220, Command: "zsh"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("runningUnder = %+v, want %+v", got, want)
+	}
+}
+
+func TestStopTimeout(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"", DefaultStopTimeout},
+		{"30s", 30 * time.Second},
+		{"0s", 0},
+		{"soon", DefaultStopTimeout},
+		{"-5s", DefaultStopTimeout},
+	}
+	for _, tc := range tests {
+		if got := StopTimeout(config.Environment{StopTimeout: tc.in}); got != tc.want {
+			t.Errorf("StopTimeout(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"ide/internal/config"
 )
@@ -115,23 +116,35 @@ type SessionsSnapshot struct {
 	Names    []string                     // session names, in tmux's default order
 	Windows  map[string][]string          // window names per session
 	Commands map[string]map[string]string // session -> window -> first-pane command
+	Exits    map[string]map[string]int    // session -> window -> exit code, only for exited commands
 }
 
+// snapshotFormat is the list-panes format ListSessionsSnapshot parses. The
+// fourth field is the dead pane's status when remain-on-exit kept it around;
+// the fifth is the status startupCommand recorded before falling back to a
+// shell.
+const snapshotFormat = "#{session_name}\t#{window_name}\t#{pane_current_command}\t#{?pane_dead,#{pane_dead_status},}\t#{" + ExitStatusOption + "}"
+
 // ListSessionsSnapshot fetches every session/window/pane-command in one shot.
 // Empty server (no tmux running) returns an empty snapshot with nil error.
 func ListSessionsSnapshot() (SessionsSnapshot, error) {
-	out, err := runTmux("list-panes", "-a", "-F", "#{session_name}\t#{window_name}\t#{pane_current_command}")
+	out, err := runTmux("list-panes", "-a", "-F", snapshotFormat)
 	if err != nil {
 		return SessionsSnapshot{}, fmt.Errorf("list panes: %w", err)
 	}
+	return parseSessionsSnapshot(out), nil
+}
+
+func parseSessionsSnapshot(out string) SessionsSnapshot {
 	snap := SessionsSnapshot{
 		Windows:  map[string][]string{},
 		Commands: map[string]map[string]string{},
+		Exits:    map[string]map[string]int{},
 	}
 	seenSession := map[string]bool{}
 	seenWindow := map[string]map[string]bool{}
 	for _, line := range splitNonEmptyLines(out) {
-		parts := strings.SplitN(line, "\t", 3)
+		parts := strings.SplitN(line, "\t", 5)
 		if len(parts) < 3 {
 			continue
 		}
@@ -149,9 +162,40 @@ func ListSessionsSnapshot() (SessionsSnapshot, error) {
 			// command, matching what `display-message #{pane_current_command}`
 			// returns for an unspecified pane target.
 			snap.Commands[s][w] = cmd
+			if code, ok := parseExitStatus(parts[3:]); ok {
+				if snap.Exits[s] == nil {
+					snap.Exits[s] = map[string]int{}
+				}
+				snap.Exits[s][w] = code
+			}
 		}
 	}
-	return snap, nil
+	return snap
+}
+
+// parseExitStatus reads the dead-pane and recorded exit status fields of a
+// snapshot line. A dead pane wins: its status is the most recent one.
+func parseExitStatus(fields []string) (int, bool) {
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if code, err := strconv.Atoi(field); err == nil {
+			return code, true
+		}
+	}
+	return 0, false
+}
+
+// ExitLabel describes a window command that exited with code, for the TUI
+// and `ide status` alike. A clean exit is not a failure, so it reads
+// "finished".
+func ExitLabel(code int) string {
+	if code == 0 {
+		return "finished (code 0)"
+	}
+	return fmt.Sprintf("exited (code %d)", code)
 }
 
 func HasWindow(session, window string) (bool, error) {
@@ -171,64 +215,85 @@ func HasWindow(session, window string) (bool, error) {
 	return false, nil
 }
 
+// EnsureSession creates env's session with all of its windows but the lazy
+// ones and those whose when condition does not hold, in a single tmux
+// invocation. A session that already exists is left as it is.
 func EnsureSession(env config.Environment) error {
 	session := SessionName(env.Name)
 	log.Printf("EnsureSession: env=%q session=%q windows=%d", env.Name, session, len(env.Windows))
 
+	// Attaching calls this every time: a running session must not pay for
+	// the when conditions, whose shell predicates only decide what a new
+	// session is built with.
+	if exists, err := HasSession(session); err == nil && exists {
+		log.Printf("EnsureSession: session %q already exists, skipping", session)
+		return nil
+	}
+
+	configured := env
+	env, skipped := enabledWindows(env)
 	if len(env.Windows) == 0 {
-		log.Printf("EnsureSession: no windows defined, falling back to default shell window")
+		log.Printf("EnsureSession: no windows defined (or enabled), falling back to default shell window")
 		env.Windows = []config.WindowTemplate{{Name: "shell"}}
 	}
 
-	first := env.Windows[0]
-	firstName := SafeWindowName(first.Name)
-	firstCwd := resolveCwd(env.Root, first.Cwd)
-
-	args := []string{"new-session", "-d", "-s", session, "-n", firstName}
-	if firstCwd != "" {
-		args = append(args, "-c", firstCwd)
+	steps := sessionBuildSteps(env)
+	if step, ok := recordSkipsStep(configured, skipped); ok {
+		steps = append(steps, step)
 	}
-	if firstCommand := startupCommand(first.Cmd); firstCommand != "" {
-		args = append(args, firstCommand)
+	for i, step := range steps {
+		log.Printf("EnsureSession: step[%d] %s args=%v", i, step.desc, step.args)
 	}
-	log.Printf("EnsureSession: creating session with first window %q cwd=%q cmd=%q args=%v", firstName, firstCwd, first.Cmd, args)
-	cmd := exec.Command("tmux", args...)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		// tmux reports "duplicate session: NAME" when the session already exists; treat as no-op so this is race-free vs. concurrent creators.
-		if strings.Contains(stderr.String(), "duplicate session") {
+	if msg, err := runBuildSteps(session, steps); err != nil {
+		// tmux reports "duplicate session: NAME" when the session already exists; the chain stops there, so treat as no-op to stay race-free vs. concurrent creators.
+		if strings.Contains(msg, "duplicate session") {
 			log.Printf("EnsureSession: session %q already exists, skipping", session)
 			return nil
 		}
-		log.Printf("EnsureSession: ERROR creating session %q: %v: %s", session, err, strings.TrimSpace(stderr.String()))
-		return fmt.Errorf("create tmux session %q: %w", session, err)
+		return buildStepError(session, steps, msg, err)
 	}
-	log.Printf("EnsureSession: session %q created", session)
 
-	for i, w := range env.Windows[1:] {
-		name := SafeWindowName(w.Name)
-		cwd := resolveCwd(env.Root, w.Cwd)
-		args = []string{"new-window", "-t", session, "-n", name}
-		if cwd != "" {
-			args = append(args, "-c", cwd)
-		}
-		if command := startupCommand(w.Cmd); command != "" {
-			args = append(args, command)
+	// The search popup (ide --search), window switcher (ide --windows) and
+	// next-agent jump live in the user's tmux.conf (see ide tmux install)
+	// rather than runtime bindings, so nothing is bound here.
+
+	log.Printf("EnsureSession: done, session %q has %d windows", session, len(eagerWindows(env)))
+	return nil
+}
+
+// RespawnWindow re-runs w's startup command in its window of env's session,
+// killing whatever the pane runs now (usually the fallback shell) and
+// clearing the recorded exit status first. A send-keys window gets a fresh
+// shell with the command typed into it again.
+func RespawnWindow(env config.Environment, w config.WindowTemplate) error {
+	target := AttachTarget(env, w.Name)
+	args := []string{"set-option", "-p", "-u", "-t", target, ExitStatusOption, ";",
+		"set-option", "-p", "-u", "-t", target, ShellPIDOption, ";",
+		"respawn-pane", "-k", "-t", target}
+	if cwd := resolveCwd(env.Root, w.Cwd); cwd != "" {
+		args = append(args, "-c", cwd)
+	}
+	if command := windowCommand(env, w); command != "" {
+		args = append(args, escapeCommandSeparator(command))
+	}
+	if sendsKeys(w) {
+		for _, typed := range typeCommandArgs(target, w) {
+			args = append(args, ";")
+			for _, a := range typed {
+				args = append(args, escapeCommandSeparator(a))
+			}
 		}
-		log.Printf("EnsureSession: creating window[%d] %q cwd=%q cmd=%q args=%v", i+1, name, cwd, w.Cmd, args)
-		if err := exec.Command("tmux", args...).Run(); err != nil {
-			log.Printf("EnsureSession: ERROR creating window %q: %v", name, err)
-			return fmt.Errorf("create window %q: %w", name, err)
+	}
+	log.Printf("RespawnWindow: target=%q cmd=%q", target, w.Cmd)
+	cmd := exec.Command("tmux", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("respawn window %q: %s", target, msg)
 		}
-		log.Printf("EnsureSession: window %q created", name)
+		return fmt.Errorf("respawn window %q: %w", target, err)
 	}
-
-	// The search popup (ide --search) and current-session window switcher
-	// (ide --windows) are both wired up via the user's tmux config rather than
-	// runtime bindings, so nothing is bound here.
-
-	log.Printf("EnsureSession: done, session %q has %d windows", session, len(env.Windows))
 	return nil
 }
 
@@ -242,6 +307,75 @@ func SwapWindow(session, src, dst string) error {
 	return nil
 }
 
+// CurrentWindow returns the session and window name of the tmux client ide
+// was started from (the one owning $TMUX_PANE, when set).
+func CurrentWindow() (string, string, error) {
+	args := []string{"display-message", "-p"}
+	if pane := os.Getenv("TMUX_PANE"); pane != "" {
+		args = append(args, "-t", pane)
+	}
+	out, err := runTmux(append(args, "#{session_name}\t#{window_name}")...)
+	if err != nil {
+		return "", "", fmt.Errorf("current tmux window: %w", err)
+	}
+	session, window, _ := strings.Cut(strings.TrimSpace(out), "\t")
+	return session, window, nil
+}
+
+// CurrentPanePID is the PID of the process $TMUX_PANE runs (see
+// CurrentWindow).
+func CurrentPanePID() (int, error) {
+	args := []string{"display-message", "-p"}
+	if pane := os.Getenv("TMUX_PANE"); pane != "" {
+		args = append(args, "-t", pane)
+	}
+	out, err := runTmux(append(args, "#{pane_pid}")...)
+	if err != nil {
+		return 0, fmt.Errorf("current tmux pane: %w", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(out))
+	if err != nil {
+		return 0, fmt.Errorf("current tmux pane: pid %q: %w", strings.TrimSpace(out), err)
+	}
+	return pid, nil
+}
+
+// SwitchClient moves the current tmux client to target, across sessions.
+func SwitchClient(target string) error {
+	if _, err := runTmux("switch-client", "-t", target); err != nil {
+		return fmt.Errorf("switch-client %s: %w", target, err)
+	}
+	return nil
+}
+
+// DisplayMessageAll shows msg in the status line of every attached client.
+// A client that fails (e.g. it detached meanwhile) does not keep the others
+// from getting it; the failures are joined.
+func DisplayMessageAll(msg string) error {
+	out, err := runTmux("list-clients", "-F", "#{client_name}")
+	if err != nil {
+		return fmt.Errorf("list-clients: %w", err)
+	}
+	var errs []error
+	for _, client := range strings.Fields(out) {
+		if _, err := runTmux("display-message", "-c", client, "--", msg); err != nil {
+			errs = append(errs, fmt.Errorf("display-message %s: %w", client, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AttachSession attaches the terminal ide runs in to target, returning
+// when the client detaches.
+func AttachSession(target string) error {
+	cmd := exec.Command("tmux", "attach-session", "-t", target)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("attach-session %s: %w", target, err)
+	}
+	return nil
+}
+
 // SelectWindow brings target to the foreground in the running tmux session.
 // Best-effort: any error is returned.
 func SelectWindow(target string) error {
@@ -251,6 +385,58 @@ func SelectWindow(target string) error {
 	return nil
 }
 
+// SendKeys types keys into target via `tmux send-keys`. With literal set the
+// keys are passed with -l, so every argument is typed verbatim instead of
+// being looked up as a key name ("Enter", "C-c"). Unlike runTmux callers
+// that tolerate a missing session, a missing target is reported as an error:
+// keystrokes that silently go nowhere are worse than a failure.
+func SendKeys(target string, literal bool, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	args := []string{"send-keys", "-t", target}
+	if literal {
+		args = append(args, "-l")
+	}
+	args = append(args, "--")
+	for _, k := range keys {
+		args = append(args, escapeCommandSeparator(k))
+	}
+	cmd := exec.Command("tmux", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if text := strings.TrimSpace(stderr.String()); text != "" {
+			return fmt.Errorf("send-keys %s: %s", target, text)
+		}
+		return fmt.Errorf("send-keys %s: %w", target, err)
+	}
+	return nil
+}
+
+// escapeCommandSeparator protects an argument that ends in ";" from being
+// read by tmux as a command separator (tmux would silently drop the ";" and
+// treat the rest of argv as a new command). A trailing "\;" is tmux's
+// escape for a literal semicolon.
+func escapeCommandSeparator(arg string) string {
+	if strings.HasSuffix(arg, ";") {
+		return strings.TrimSuffix(arg, ";") + `\;`
+	}
+	return arg
+}
+
+// FindWindowTemplate finds a config window template by name within an
+// environment. Live tmux windows carry the sanitized name (spaces become
+// dashes), so both forms match.
+func FindWindowTemplate(env config.Environment, windowName string) (config.WindowTemplate, bool) {
+	for _, w := range env.Windows {
+		if w.Name == windowName || SafeWindowName(w.Name) == windowName {
+			return w, true
+		}
+	}
+	return config.WindowTemplate{}, false
+}
+
 func AttachTarget(env config.Environment, windowName string) string {
 	session := SessionName(env.Name)
 	if strings.TrimSpace(windowName) == "" {
@@ -293,16 +479,73 @@ func SafeWindowName(name string) string {
 	return strings.ReplaceAll(name, " ", "-")
 }
 
-func startupCommand(command string) string {
-	if strings.TrimSpace(command) == "" {
+// ExitStatusOption is the pane user option startupCommand records the
+// window command's exit status in once it returns.
+const ExitStatusOption = "@ide_exit"
+
+// startupCommand runs command in a login shell, records its exit status on
+// the pane, then drops into an interactive shell so the window stays usable.
+// The outer script is pinned to /bin/sh because tmux runs it with the user's
+// default-shell, and `$?` is not portable to shells like fish. It traps INT
+// so a C-c (including the one a graceful stop sends) ends the command but
+// not the wrapper; the trap is a handler, not an ignore, so the command
+// still gets default SIGINT behaviour. A non-empty wrapper (see Wrapper)
+// runs both the command and the fallback shell inside it. With keep_shell
+// false there is no fallback shell: the pane exits with the command's
+// status, which a remain-on-exit pane then shows.
+func startupCommand(w config.WindowTemplate, wrapper string) string {
+	command := strings.TrimSpace(w.Cmd)
+	if command == "" {
 		return ""
 	}
-	shell := os.Getenv("SHELL")
-	if shell == "" {
-		shell = "/bin/sh"
+	shell := windowShell(w)
+	script := "trap : INT; " + wrapCommand(wrapper, shell, command) +
+		`; s=$?; tmux set-option -p -t "$TMUX_PANE" ` + ExitStatusOption + ` $s 2>/dev/null`
+	if keepsShell(w) {
+		script += "; " + fallbackShell(wrapper, shell)
+	} else {
+		script += "; exit $s"
+	}
+	return "/bin/sh -c " + shellQuote(script)
+}
+
+// keepsShell reports whether w's window drops into a shell once its
+// command exits (keep_shell, true unless set to false).
+func keepsShell(w config.WindowTemplate) bool {
+	return w.KeepShell == nil || *w.KeepShell
+}
+
+// sendsKeys reports whether w's command is typed into a plain interactive
+// shell (start_mode send-keys) instead of being run by startupCommand. No
+// exit status is recorded for such a window: the shell outlives it.
+func sendsKeys(w config.WindowTemplate) bool {
+	return w.StartMode == config.StartSendKeys && strings.TrimSpace(w.Cmd) != ""
+}
+
+// windowCommand is the command new-window and respawn-pane run for w in
+// env; empty means tmux's default shell. Windows without a command to run
+// (including send-keys ones, which type it in) get a plain shell, which is
+// wrapped or swapped for the window's shell when either is set.
+func windowCommand(env config.Environment, w config.WindowTemplate) string {
+	wrapper := Wrapper(env, w)
+	if sendsKeys(w) || strings.TrimSpace(w.Cmd) == "" {
+		if wrapper == "" && w.Shell == "" {
+			return ""
+		}
+		return "/bin/sh -c " + shellQuote(fallbackShell(wrapper, windowShell(w)))
+	}
+	return startupCommand(w, wrapper)
+}
+
+// typeCommandArgs are the tmux commands that type w's command into target
+// and press Enter. -l sends the text verbatim, so it reaches the shell
+// exactly as written in the config and needs none of the quoting
+// startupCommand does; callers still escape a trailing ";" for tmux.
+func typeCommandArgs(target string, w config.WindowTemplate) [][]string {
+	return [][]string{
+		{"send-keys", "-t", target, "-l", "--", strings.TrimSpace(w.Cmd)},
+		{"send-keys", "-t", target, "Enter"},
 	}
-	script := strings.TrimSpace(command) + "; exec " + shell + " -i"
-	return shell + " -lc " + shellQuote(script)
 }
 
 func shellQuote(value string) string {
@@ -365,36 +608,57 @@ func CheckTmuxExists() error {
 
 // ProcessInfo contains process metrics for a pane
 type ProcessInfo struct {
-	PID   int
-	CPU   float64
-	State string
+	PID     int
+	CPU     float64
+	State   string
+	RSS     int64 // resident memory of the pane's process tree, in bytes
+	Threads int   // threads in the tree; 0 when the platform does not say
 }
 
-// procRow is one entry from the system-wide `ps` snapshot.
+// procRow is one entry from the system-wide process snapshot.
 type procRow struct {
-	pid   int
-	ppid  int
-	cpu   float64
-	state string
+	pid     int
+	ppid    int
+	cpu     float64
+	state   string
+	comm    string
+	rss     int64     // bytes
+	threads int       // 0 from ps, which has no portable thread count
+	started time.Time // zero when unknown
 }
 
-// snapshotProcesses runs `ps` ONCE and returns the full process table keyed
-// by PID. Old code spawned one `pgrep` per node plus one `ps` per child of
-// the pane's process tree, for every AI window, every 500ms. That fork-bombed
-// the system; this version is one subprocess per poll regardless of tree size.
-func snapshotProcesses() (map[int]procRow, error) {
+// snapshotPS runs `ps` ONCE and returns the full process table keyed by
+// PID. Old code spawned one `pgrep` per node plus one `ps` per child of the
+// pane's process tree, for every AI window, every 500ms. That fork-bombed
+// the system; this version is one subprocess per poll regardless of tree
+// size. snapshotProcesses uses it where /proc is not available.
+//
+// ps reports %cpu as an average over the process's lifetime, so a
+// long-running agent that just started cooking barely moves it.
+func snapshotPS() (map[int]procRow, error) {
 	// Use "=" suffix on format specifiers to suppress headers (works on
-	// macOS and Linux). Order: pid, ppid, %cpu, state.
-	cmd := exec.Command("ps", "-A", "-o", "pid=", "-o", "ppid=", "-o", "%cpu=", "-o", "state=")
+	// macOS and Linux). Order: pid, ppid, %cpu, rss, state, lstart, comm.
+	// lstart is five words ("Sun Oct 18 13:11:10 2026", in the C locale);
+	// comm goes last because it may contain spaces.
+	cmd := exec.Command("ps", "-A", "-o", "pid=", "-o", "ppid=", "-o", "%cpu=", "-o", "rss=", "-o", "state=", "-o", "lstart=", "-o", "comm=")
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	if err := cmd.Run(); err != nil {
 		return nil, fmt.Errorf("ps -A: %w", err)
 	}
+	return parsePS(out.String()), nil
+}
+
+// psStartLayout is ps's lstart format once its padding is collapsed.
+const psStartLayout = "Mon Jan 2 15:04:05 2006"
+
+// parsePS parses the output of snapshotPS's ps run.
+func parsePS(out string) map[int]procRow {
 	rows := map[int]procRow{}
-	for _, line := range strings.Split(out.String(), "\n") {
+	for _, line := range strings.Split(out, "\n") {
 		fields := strings.Fields(line)
-		if len(fields) < 4 {
+		if len(fields) < 11 {
 			continue
 		}
 		pid, err := strconv.Atoi(fields[0])
@@ -406,13 +670,16 @@ func snapshotProcesses() (map[int]procRow, error) {
 			continue
 		}
 		cpu, _ := strconv.ParseFloat(fields[2], 64)
-		state := fields[3]
+		rssKB, _ := strconv.ParseInt(fields[3], 10, 64)
+		state := fields[4]
 		if len(state) > 0 {
 			state = state[:1]
 		}
-		rows[pid] = procRow{pid: pid, ppid: ppid, cpu: cpu, state: state}
+		started, _ := time.ParseInLocation(psStartLayout, strings.Join(fields[5:10], " "), time.Local)
+		comm := strings.Join(fields[10:], " ")
+		rows[pid] = procRow{pid: pid, ppid: ppid, cpu: cpu, state: state, comm: comm, rss: rssKB * 1024, started: started}
 	}
-	return rows, nil
+	return rows
 }
 
 // buildChildMap groups the process table by parent PID.
@@ -424,32 +691,72 @@ func buildChildMap(rows map[int]procRow) map[int][]int {
 	return children
 }
 
-// GetPaneProcessInfo retrieves the current process info for a pane.
-// It sums CPU usage across the pane's shell AND all its descendants, so a
-// single-process pane (no shell wrapper) is still detected as active.
-func GetPaneProcessInfo(session, window string) (ProcessInfo, error) {
-	target := session + ":" + SafeWindowName(window)
+// windowPanesFormat is the list-panes format ProcessInfoByWindow parses.
+const windowPanesFormat = "#{session_name}\t#{window_name}\t#{pane_active}\t#{pane_pid}"
 
-	out, err := runTmux("display-message", "-p", "-t", target, "#{pane_pid}")
+// ProcessInfoByWindow returns the process info of every window of every
+// session at once, keyed by session and window name: one list-panes for
+// the pane PIDs and one process-table read, however many windows there are.
+// Each window is measured at its active pane, like a window target is. The
+// CPU is summed across the pane's shell and all its descendants, so a
+// single-process pane (no shell wrapper) is still detected as active.
+func ProcessInfoByWindow() (map[string]map[string]ProcessInfo, error) {
+	out, err := runTmux("list-panes", "-a", "-F", windowPanesFormat)
 	if err != nil {
-		return ProcessInfo{}, err
+		return nil, fmt.Errorf("list panes: %w", err)
 	}
-	shellPID, err := strconv.Atoi(strings.TrimSpace(out))
-	if err != nil {
-		return ProcessInfo{}, fmt.Errorf("parse pane PID %q: %w", out, err)
+	pids := parseWindowPanes(out)
+	if len(pids) == 0 {
+		return map[string]map[string]ProcessInfo{}, nil
 	}
-
 	rows, err := snapshotProcesses()
 	if err != nil {
-		return ProcessInfo{}, err
+		return nil, err
 	}
 	children := buildChildMap(rows)
+	infos := make(map[string]map[string]ProcessInfo, len(pids))
+	for session, windows := range pids {
+		infos[session] = make(map[string]ProcessInfo, len(windows))
+		for window, pid := range windows {
+			infos[session][window] = treeInfo(rows, children, pid)
+		}
+	}
+	return infos, nil
+}
+
+// parseWindowPanes maps each window to the PID of its active pane, or of
+// its first pane when tmux marks none active.
+func parseWindowPanes(out string) map[string]map[string]int {
+	pids := map[string]map[string]int{}
+	for _, line := range splitNonEmptyLines(out) {
+		parts := strings.Split(line, "\t")
+		if len(parts) != 4 {
+			continue
+		}
+		pid, err := strconv.Atoi(parts[3])
+		if err != nil {
+			continue
+		}
+		session, window, active := parts[0], parts[1], parts[2] == "1"
+		if pids[session] == nil {
+			pids[session] = map[string]int{}
+		}
+		if _, seen := pids[session][window]; !seen || active {
+			pids[session][window] = pid
+		}
+	}
+	return pids
+}
 
+// treeInfo aggregates the process tree rooted at pid (a pane's shell).
+func treeInfo(rows map[int]procRow, children map[int][]int, shellPID int) ProcessInfo {
 	// Walk the subtree (including the shell itself) and aggregate CPU + a
 	// running flag. Using an iterative stack instead of recursion so an
 	// adversarial process loop (shouldn't happen, but ps under namespacing
 	// has produced cycles before) doesn't blow the call stack.
 	totalCPU := 0.0
+	var rss int64
+	threads := 0
 	hasRunning := false
 	stack := []int{shellPID}
 	visited := map[int]bool{}
@@ -463,6 +770,8 @@ func GetPaneProcessInfo(session, window string) (ProcessInfo, error) {
 		visited[pid] = true
 		if row, ok := rows[pid]; ok {
 			totalCPU += row.cpu
+			rss += row.rss
+			threads += row.threads
 			if row.state == "R" {
 				hasRunning = true
 			}
@@ -475,8 +784,10 @@ func GetPaneProcessInfo(session, window string) (ProcessInfo, error) {
 		state = "R"
 	}
 	return ProcessInfo{
-		PID:   shellPID,
-		CPU:   totalCPU,
-		State: state,
-	}, nil
+		PID:     shellPID,
+		CPU:     totalCPU,
+		State:   state,
+		RSS:     rss,
+		Threads: threads,
+	}
 }
@@ -1,9 +1,14 @@
 package tmux
 
 import (
+	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
+
+	"ide/internal/config"
 )
 
 func TestBuildChildMap(t *testing.T) {
@@ -108,6 +113,23 @@ func TestSplitNonEmptyLines(t *testing.T) {
 	}
 }
 
+func TestParseWindowPanes(t *testing.T) {
+	out := "ide-svc\tagent\t0\t100\n" +
+		"ide-svc\tagent\t1\t101\n" +
+		"ide-svc\tagent\t0\t102\n" +
+		"ide-svc\tdb\t0\t200\n" +
+		"ide-web\tagent\t1\t300\n" +
+		"ide-web\tbroken\t1\tnope\n" +
+		"short line\n"
+	want := map[string]map[string]int{
+		"ide-svc": {"agent": 101, "db": 200},
+		"ide-web": {"agent": 300},
+	}
+	if got := parseWindowPanes(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseWindowPanes = %v, want %v", got, want)
+	}
+}
+
 func TestResolveCwd(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -132,3 +154,392 @@ func TestResolveCwd(t *testing.T) {
 		})
 	}
 }
+
+func TestEscapeCommandSeparator(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{"a;b", "a;b"},
+		{"make test;", `make test\;`},
+		{";", `\;`},
+		{"", ""},
+	}
+	for _, tc := range tests {
+		if got := escapeCommandSeparator(tc.in); got != tc.want {
+			t.Errorf("escapeCommandSeparator(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParseSessionsSnapshotExits(t *testing.T) {
+	out := strings.Join([]string{
+		"ide-a\teditor\tnvim\t\t",
+		"ide-a\tserver\tbash\t\t1",
+		"ide-a\tserver\tnode\t\t",
+		"ide-a\tjob\tbash\t0\t2",
+		"ide-b\tshell\tzsh",
+	}, "\n")
+	snap := parseSessionsSnapshot(out)
+	if want := []string{"ide-a", "ide-b"}; !reflect.DeepEqual(snap.Names, want) {
+		t.Fatalf("Names = %v, want %v", snap.Names, want)
+	}
+	if want := []string{"editor", "server", "job"}; !reflect.DeepEqual(snap.Windows["ide-a"], want) {
+		t.Errorf("Windows[ide-a] = %v, want %v", snap.Windows["ide-a"], want)
+	}
+	// Only the first pane of a window counts; a dead pane's status wins
+	// over the recorded one.
+	want := map[string]map[string]int{"ide-a": {"server": 1, "job": 0}}
+	if !reflect.DeepEqual(snap.Exits, want) {
+		t.Errorf("Exits = %v, want %v", snap.Exits, want)
+	}
+}
+
+func TestExitLabel(t *testing.T) {
+	if got := ExitLabel(0); got != "finished (code 0)" {
+		t.Errorf("ExitLabel(0) = %q, want a clean exit not labelled exited", got)
+	}
+	if got := ExitLabel(2); got != "exited (code 2)" {
+		t.Errorf("ExitLabel(2) = %q", got)
+	}
+}
+
+func TestStartupCommandRecordsExitStatus(t *testing.T) {
+	t.Setenv("SHELL", "/bin/zsh")
+	if got := startupCommand(config.WindowTemplate{Cmd: "  "}, ""); got != "" {
+		t.Errorf("startupCommand(blank) = %q, want empty", got)
+	}
+	got := startupCommand(config.WindowTemplate{Cmd: "npm run dev"}, "")
+	for _, part := range []string{"/bin/sh -c ", inScript("'/bin/zsh' -lc"), "npm run dev", ExitStatusOption + " $s", inScript("exec '/bin/zsh' -il")} {
+		if !strings.Contains(got, part) {
+			t.Errorf("startupCommand = %q, missing %q", got, part)
+		}
+	}
+}
+
+func TestStartupCommandShellAndKeepShell(t *testing.T) {
+	t.Setenv("SHELL", "/bin/zsh")
+	keep := false
+	tests := []struct {
+		name        string
+		w           config.WindowTemplate
+		want, wantN []string
+	}{
+		{"window shell", config.WindowTemplate{Cmd: "make", Shell: "fish"}, []string{inScript("'fish' -lc"), inScript("exec 'fish' -il")}, []string{"zsh"}},
+		{"one-shot", config.WindowTemplate{Cmd: "make generate", KeepShell: &keep}, []string{inScript("'/bin/zsh' -lc"), ExitStatusOption + " $s", "exit $s"}, []string{"-i"}},
+	}
+	for _, tc := range tests {
+		got := startupCommand(tc.w, "")
+		for _, part := range tc.want {
+			if !strings.Contains(got, part) {
+				t.Errorf("%s: startupCommand = %q, missing %q", tc.name, got, part)
+			}
+		}
+		for _, part := range tc.wantN {
+			if strings.Contains(got, part) {
+				t.Errorf("%s: startupCommand = %q, should not contain %q", tc.name, got, part)
+			}
+		}
+	}
+	if got := windowCommand(config.Environment{}, config.WindowTemplate{Shell: "bash"}); got != `/bin/sh -c 'exec '"'"'bash'"'"' -il'` {
+		t.Errorf("windowCommand(plain bash window) = %q", got)
+	}
+}
+
+// inScript is how part reads inside the single-quoted script startupCommand
+// hands to /bin/sh -c.
+func inScript(part string) string {
+	return strings.ReplaceAll(part, "'", `'"'"'`)
+}
+
+func TestChainBuildSteps(t *testing.T) {
+	env := config.Environment{Name: "demo", Root: "/srv/demo", Windows: []config.WindowTemplate{
+		{Name: "editor"},
+		{Name: "logs", Cwd: "var/log;"},
+	}}
+	steps := sessionBuildSteps(env)
+	if len(steps) != 2 {
+		t.Fatalf("got %d steps, want 2", len(steps))
+	}
+	if steps[0].desc != `create tmux session "ide-demo"` || steps[1].desc != `create window "logs"` {
+		t.Errorf("unexpected step descriptions: %q, %q", steps[0].desc, steps[1].desc)
+	}
+
+	got := chainBuildSteps("ide-demo", steps)
+	want := []string{
+		"new-session", "-d", "-s", "ide-demo", "-n", "editor", "-c", "/srv/demo",
+		";", "set-option", "-t", "ide-demo", buildStepOption, "0", ";",
+		"new-window", "-t", "ide-demo", "-n", "logs", "-c", `/srv/demo/var/log\;`,
+		";", "set-option", "-t", "ide-demo", buildStepOption, "1", ";",
+		"set-option", "-u", "-t", "ide-demo", buildStepOption,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("chainBuildSteps:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestSessionBuildStepsAppliesTmuxOptions(t *testing.T) {
+	env := config.Environment{
+		Name:        "demo",
+		TmuxOptions: map[string]string{"status-style": "bg=blue", "monitor-activity": "on", "bogus": "x"},
+		Windows: []config.WindowTemplate{
+			{Name: "editor"},
+			{Name: "logs", TmuxOptions: map[string]string{"monitor-activity": "off", "remain-on-exit": "on"}},
+		},
+	}
+	var got []string
+	for _, step := range sessionBuildSteps(env) {
+		got = append(got, strings.Join(step.args, " "))
+	}
+	want := []string{
+		"new-session -d -s ide-demo -n editor",
+		"set-option -t ide-demo status-style bg=blue",
+		"set-option -w -t ide-demo monitor-activity on",
+		"new-window -t ide-demo -n logs",
+		"set-option -w -t ide-demo monitor-activity off",
+		"set-option -w -t ide-demo remain-on-exit on",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sessionBuildSteps:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestSessionBuildStepsTypesSendKeysCommands(t *testing.T) {
+	env := config.Environment{Name: "demo", Windows: []config.WindowTemplate{
+		{Name: "dev", Cmd: `npm run dev -- --host '0.0.0.0'`, StartMode: config.StartSendKeys},
+		{Name: "loop", Cmd: "while true; do date; sleep 1; done;", StartMode: config.StartSendKeys},
+		{Name: "shell", StartMode: config.StartSendKeys},
+	}}
+	steps := sessionBuildSteps(env)
+	var got [][]string
+	for _, step := range steps {
+		got = append(got, step.args)
+	}
+	want := [][]string{
+		{"new-session", "-d", "-s", "ide-demo", "-n", "dev"},
+		{"send-keys", "-t", "ide-demo", "-l", "--", `npm run dev -- --host '0.0.0.0'`},
+		{"send-keys", "-t", "ide-demo", "Enter"},
+		{"new-window", "-t", "ide-demo", "-n", "loop"},
+		{"send-keys", "-t", "ide-demo", "-l", "--", "while true; do date; sleep 1; done;"},
+		{"send-keys", "-t", "ide-demo", "Enter"},
+		{"new-window", "-t", "ide-demo", "-n", "shell"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sessionBuildSteps:\n got %q\nwant %q", got, want)
+	}
+	chained := chainBuildSteps("ide-demo", steps)
+	if !slices.Contains(chained, `while true; do date; sleep 1; done\;`) {
+		t.Errorf("trailing ; of a typed command not escaped: %q", chained)
+	}
+}
+
+func TestSessionBuildStepsSkipsLazyWindows(t *testing.T) {
+	tests := []struct {
+		name    string
+		windows []config.WindowTemplate
+		want    []string
+	}{
+		{
+			name:    "lazy windows left out",
+			windows: []config.WindowTemplate{{Name: "editor"}, {Name: "db", Lazy: true}, {Name: "logs"}},
+			want:    []string{"new-session -d -s ide-demo -n editor", "new-window -t ide-demo -n logs"},
+		},
+		{
+			name:    "lazy first window",
+			windows: []config.WindowTemplate{{Name: "prof", Lazy: true}, {Name: "editor"}},
+			want:    []string{"new-session -d -s ide-demo -n editor"},
+		},
+		{
+			name:    "all lazy keeps the first",
+			windows: []config.WindowTemplate{{Name: "a", Lazy: true}, {Name: "b", Lazy: true}},
+			want:    []string{"new-session -d -s ide-demo -n a"},
+		},
+	}
+	for _, tc := range tests {
+		var got []string
+		for _, step := range sessionBuildSteps(config.Environment{Name: "demo", Windows: tc.windows}) {
+			got = append(got, strings.Join(step.args, " "))
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: sessionBuildSteps:\n got %q\nwant %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestWithLazyWindows(t *testing.T) {
+	env := config.Environment{Name: "demo", Windows: []config.WindowTemplate{
+		{Name: "prof", Lazy: true},
+		{Name: "editor"},
+		{Name: "db shell", Lazy: true},
+		{Name: "logs"},
+		{Name: "tail", Lazy: true},
+	}}
+	tests := []struct {
+		live, want []string
+	}{
+		{[]string{"editor", "logs"}, []string{"prof", "editor", "db-shell", "logs", "tail"}},
+		{[]string{"editor", "db-shell", "logs"}, []string{"prof", "editor", "db-shell", "logs", "tail"}},
+		// live order wins; a lazy window follows the window configured before it
+		{[]string{"logs", "scratch", "editor"}, []string{"prof", "logs", "tail", "scratch", "editor", "db-shell"}},
+	}
+	for _, tc := range tests {
+		if got := WithLazyWindows(env, tc.live); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("WithLazyWindows(%q) = %q, want %q", tc.live, got, tc.want)
+		}
+	}
+	if !IsLazyWindow(env, "db-shell") || IsLazyWindow(env, "logs") {
+		t.Error("IsLazyWindow should match lazy windows by either name form")
+	}
+}
+
+func TestValidateOption(t *testing.T) {
+	tests := []struct {
+		name, value string
+		forWindow   bool
+		wantErr     bool
+	}{
+		{"history-limit", "50000", false, false},
+		{"history-limit", "lots", false, true},
+		{"history-limit", "50000", true, true}, // session option on a window
+		{"automatic-rename", "off", true, false},
+		{"automatic-rename", "maybe", true, true},
+		{"remain-on-exit", "failed", false, false},
+		{"status-position", "middle", false, true},
+		{"status-style", "bg=red,fg=white", false, false},
+		{"no-such-option", "on", false, true},
+	}
+	for _, tt := range tests {
+		err := ValidateOption(tt.name, tt.value, tt.forWindow)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateOption(%q, %q, %v) = %v, wantErr %v", tt.name, tt.value, tt.forWindow, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckCondition(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("IDE_WHEN_SET", "1")
+	t.Setenv("IDE_WHEN_EMPTY", "")
+	tests := []struct {
+		when config.Condition
+		want bool
+	}{
+		{config.Condition{}, true},
+		{config.Condition{File: "go.mod"}, true},
+		{config.Condition{File: "*.mod"}, true},
+		{config.Condition{File: "package.json"}, false},
+		{config.Condition{Bin: "sh"}, true},
+		{config.Condition{Bin: "no-such-binary-ide"}, false},
+		{config.Condition{Env: "IDE_WHEN_SET"}, true},
+		{config.Condition{Env: "IDE_WHEN_EMPTY"}, false},
+		{config.Condition{Shell: "test -f go.mod"}, true},
+		{config.Condition{Shell: "false"}, false},
+		{config.Condition{File: "go.mod", Env: "IDE_WHEN_EMPTY"}, false}, // every part must hold
+	}
+	for _, tc := range tests {
+		got, reason := CheckCondition(root, tc.when)
+		if got != tc.want {
+			t.Errorf("CheckCondition(%v) = %v (%s), want %v", tc.when, got, reason, tc.want)
+		}
+		if !got && reason == "" {
+			t.Errorf("CheckCondition(%v) failed without a reason", tc.when)
+		}
+	}
+}
+
+func TestEnsureSessionSkipsWindowsWhoseConditionFails(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	env := config.Environment{Name: "uni", Root: root, Windows: []config.WindowTemplate{
+		{Name: "editor"},
+		{Name: "test", When: config.Condition{File: "go.mod"}},
+		{Name: "dev", When: config.Condition{File: "package.json"}},
+		{Name: "db", Lazy: true, When: config.Condition{Env: "IDE_WHEN_UNSET"}},
+	}}
+	fake := NewFake()
+	if err := fake.EnsureSession(env); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := fake.ListWindows("ide-uni"); !reflect.DeepEqual(got, []string{"editor", "test"}) {
+		t.Errorf("windows = %q, want the dev window skipped", got)
+	}
+	if err := fake.EnsureWindow(env, "db"); err == nil {
+		t.Error("EnsureWindow created a lazy window whose condition fails")
+	}
+
+	// Once created, the window stays reachable when the condition no
+	// longer holds: only a creation checks it.
+	t.Setenv("IDE_WHEN_UNSET", "1")
+	if err := fake.EnsureWindow(env, "db"); err != nil {
+		t.Fatal(err)
+	}
+	os.Unsetenv("IDE_WHEN_UNSET")
+	if err := fake.EnsureWindow(env, "db"); err != nil {
+		t.Errorf("EnsureWindow of the existing db window = %v, want nil", err)
+	}
+}
+
+func TestSkippedWindowsLeavesShellPredicatesToTheBuild(t *testing.T) {
+	t.Setenv("TMUX_TMPDIR", t.TempDir()) // no server: nothing recorded
+	env := config.Environment{Name: "uni", Root: t.TempDir(), Windows: []config.WindowTemplate{
+		{Name: "editor"},
+		{Name: "dev", When: config.Condition{File: "package.json"}},
+		{Name: "vpn", When: config.Condition{Shell: "exit 1"}},
+	}}
+	if got, want := SkippedWindows(env), map[string]string{"dev": "no package.json in " + env.Root}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SkippedWindows = %q, want %q (shell predicate not run)", got, want)
+	}
+	skipped := checkWindows(env)
+	if _, ok := skipped["vpn"]; !ok {
+		t.Fatalf("checkWindows = %q, want the vpn window skipped", skipped)
+	}
+	step, ok := recordSkipsStep(env, skipped)
+	if !ok {
+		t.Fatal("recordSkipsStep: no step for an env with a shell predicate")
+	}
+	want := []string{"set-option", "-t", "ide-uni", skippedOption, `{"vpn":"\"exit 1\" failed: exit status 1"}`}
+	if !reflect.DeepEqual(step.args, want) {
+		t.Errorf("record step = %q, want %q", step.args, want)
+	}
+	if _, ok := recordSkipsStep(config.Environment{Name: "plain", Windows: env.Windows[:2]}, skipped); ok {
+		t.Error("recordSkipsStep: step for an env without shell predicates")
+	}
+}
+
+func TestCommandLineAppliesWrapper(t *testing.T) {
+	t.Setenv("SHELL", "/bin/zsh")
+	env := config.Environment{Name: "demo", Wrapper: "nix develop -c"}
+	tests := []struct {
+		name string
+		env  config.Environment
+		w    config.WindowTemplate
+		want string
+	}{
+		{"unwrapped", config.Environment{}, config.WindowTemplate{Cmd: "make"}, `'/bin/zsh' -lc 'make'`},
+		{"shell path with a space", config.Environment{}, config.WindowTemplate{Cmd: "make", Shell: "/opt/my tools/zsh"}, `'/opt/my tools/zsh' -lc 'make'`},
+		{"unwrapped shell", config.Environment{}, config.WindowTemplate{}, ""},
+		{"env prefix", env, config.WindowTemplate{Cmd: "go test ./..."}, `nix develop -c '/bin/zsh' -lc 'go test ./...'`},
+		{"env prefix shell", env, config.WindowTemplate{}, "nix develop -c '/bin/zsh' -il"},
+		{"send-keys gets a wrapped shell", env, config.WindowTemplate{Cmd: "make", StartMode: config.StartSendKeys}, "nix develop -c '/bin/zsh' -il"},
+		{"window override", env, config.WindowTemplate{Cmd: "make", Wrapper: "direnv exec ."}, `direnv exec . '/bin/zsh' -lc 'make'`},
+		{"window opts out", env, config.WindowTemplate{Cmd: "make", Wrapper: config.WrapperNone}, `'/bin/zsh' -lc 'make'`},
+		{"placeholder", config.Environment{Wrapper: "docker compose exec app sh -c {cmd}"}, config.WindowTemplate{Cmd: "echo 'hi'"},
+			`docker compose exec app sh -c 'echo '"'"'hi'"'"''`},
+		{"placeholder shell", config.Environment{Wrapper: "docker compose exec app sh -c {cmd}"}, config.WindowTemplate{},
+			`docker compose exec app sh -c 'exec "${SHELL:-/bin/sh}" -il'`},
+	}
+	for _, tc := range tests {
+		if got := CommandLine(tc.env, tc.w); got != tc.want {
+			t.Errorf("%s: CommandLine = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+	if got := windowCommand(env, config.WindowTemplate{Cmd: "make"}); !strings.Contains(got, inScript(fallbackShell("nix develop -c", "'/bin/zsh'"))) {
+		t.Errorf("windowCommand = %q, want the fallback shell wrapped too", got)
+	}
+}
@@ -0,0 +1,121 @@
+package tmux
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Version is a parsed `tmux -V`.
+type Version struct {
+	Major, Minor int
+	Raw          string // as reported, e.g. "3.3a" or "next-3.5"
+	Dev          bool   // a development build ("master", "next-3.5"): assumed to have everything
+}
+
+// AtLeast reports whether v is major.minor or newer. Development builds
+// are newer than every release.
+func (v Version) AtLeast(major, minor int) bool {
+	if v.Dev {
+		return true
+	}
+	return v.Major > major || (v.Major == major && v.Minor >= minor)
+}
+
+func (v Version) String() string { return v.Raw }
+
+// MinVersion is the oldest tmux ide works with: per-pane user options
+// (set-option -p), which exit tracking relies on, arrived in 3.0.
+var MinVersion = Version{Major: 3, Minor: 0, Raw: "3.0"}
+
+// versionRe matches the numeric part of a tmux version ("3.3a", "next-3.5").
+var versionRe = regexp.MustCompile(`(\d+)\.(\d+)`)
+
+// ParseVersion parses the output of `tmux -V` ("tmux 3.3a"). Builds from
+// git report "master" or "next-X.Y" and are marked Dev.
+func ParseVersion(s string) (Version, error) {
+	raw := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), "tmux"))
+	if raw == "" {
+		return Version{}, fmt.Errorf("parse tmux version %q: empty", s)
+	}
+	v := Version{Raw: raw, Dev: raw == "master" || strings.HasPrefix(raw, "next-")}
+	m := versionRe.FindStringSubmatch(raw)
+	if m == nil {
+		if v.Dev {
+			return v, nil
+		}
+		return Version{}, fmt.Errorf("parse tmux version %q: no version number", s)
+	}
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	return v, nil
+}
+
+// Capabilities are the version-dependent tmux features ide uses. Only
+// features newer than MinVersion are listed; older ones are assumed.
+type Capabilities struct {
+	Version Version
+	// Popup is display-popup (3.2); without it the key bindings open the
+	// search and window switcher in a new window instead.
+	Popup bool
+	// NewWindowBefore is new-window -b (3.2); without it a lazy window
+	// configured ahead of every running one is created at the end.
+	NewWindowBefore bool
+}
+
+// CapabilitiesFor derives the capabilities of v.
+func CapabilitiesFor(v Version) Capabilities {
+	return Capabilities{
+		Version:         v,
+		Popup:           v.AtLeast(3, 2),
+		NewWindowBefore: v.AtLeast(3, 2),
+	}
+}
+
+// Supported reports whether the version meets MinVersion.
+func (c Capabilities) Supported() bool {
+	return c.Version.AtLeast(MinVersion.Major, MinVersion.Minor)
+}
+
+// Warning is the startup message for a tmux that is too old or lacks
+// popups, or "" when everything is available.
+func (c Capabilities) Warning() string {
+	switch {
+	case !c.Supported():
+		return fmt.Sprintf("tmux %s is too old: ide needs %s or newer (3.2+ for popups). Sessions may fail to start.", c.Version, MinVersion)
+	case !c.Popup:
+		return fmt.Sprintf("tmux %s has no display-popup (3.2+): search and window switcher bindings open in a new window.", c.Version)
+	}
+	return ""
+}
+
+var (
+	capsOnce sync.Once
+	caps     Capabilities
+	capsErr  error
+)
+
+// DetectCapabilities runs `tmux -V` once per process and caches the result.
+// When the version cannot be determined everything is assumed available,
+// so an unusual build string does not switch features off.
+func DetectCapabilities() (Capabilities, error) {
+	capsOnce.Do(func() {
+		out, err := exec.Command("tmux", "-V").Output()
+		if err != nil {
+			capsErr = fmt.Errorf("tmux -V: %w", err)
+			caps = CapabilitiesFor(Version{Raw: "unknown", Dev: true})
+			return
+		}
+		v, err := ParseVersion(string(out))
+		if err != nil {
+			log.Printf("DetectCapabilities: %v", err)
+			v = Version{Raw: strings.TrimSpace(string(out)), Dev: true}
+		}
+		caps = CapabilitiesFor(v)
+	})
+	return caps, capsErr
+}
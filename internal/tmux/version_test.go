@@ -0,0 +1,56 @@
+package tmux
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in           string
+		major, minor int
+		dev, wantErr bool
+	}{
+		{"tmux 3.3a\n", 3, 3, false, false},
+		{"tmux 3.2", 3, 2, false, false},
+		{"tmux 2.9a", 2, 9, false, false},
+		{"tmux next-3.5", 3, 5, true, false},
+		{"tmux master", 0, 0, true, false},
+		{"tmux", 0, 0, false, true},
+		{"tmux unknown", 0, 0, false, true},
+	}
+	for _, tt := range tests {
+		v, err := ParseVersion(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseVersion(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (v.Major != tt.major || v.Minor != tt.minor || v.Dev != tt.dev) {
+			t.Errorf("ParseVersion(%q) = %+v, want %d.%d dev=%v", tt.in, v, tt.major, tt.minor, tt.dev)
+		}
+	}
+}
+
+func TestCapabilitiesFor(t *testing.T) {
+	tests := []struct {
+		version               string
+		supported, popup      bool
+		newWindowBefore, warn bool
+	}{
+		{"tmux 3.4", true, true, true, false},
+		{"tmux 3.2", true, true, true, false},
+		{"tmux 3.1c", true, false, false, true},
+		{"tmux 2.8", false, false, false, true},
+		{"tmux master", true, true, true, false},
+	}
+	for _, tt := range tests {
+		v, err := ParseVersion(tt.version)
+		if err != nil {
+			t.Fatalf("ParseVersion(%q): %v", tt.version, err)
+		}
+		c := CapabilitiesFor(v)
+		if c.Supported() != tt.supported || c.Popup != tt.popup || c.NewWindowBefore != tt.newWindowBefore {
+			t.Errorf("%s: got supported=%v popup=%v before=%v", tt.version, c.Supported(), c.Popup, c.NewWindowBefore)
+		}
+		if (c.Warning() != "") != tt.warn {
+			t.Errorf("%s: Warning() = %q, want warning=%v", tt.version, c.Warning(), tt.warn)
+		}
+	}
+}
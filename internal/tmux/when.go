@@ -0,0 +1,188 @@
+package tmux
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ide/internal/config"
+)
+
+// conditionTimeout bounds a when.shell predicate, so a hanging one cannot
+// stall building the session.
+const conditionTimeout = 5 * time.Second
+
+// skippedOption is a session option holding, as a JSON object, the windows
+// whose when.shell predicate failed when the session was built, with the
+// reason. Shell predicates run only then (and before a lazy window is
+// created); SkippedWindows reads their outcome from here rather than run
+// them again on every config load.
+const skippedOption = "@ide_skipped"
+
+// CheckCondition evaluates c against root. When it does not hold, the
+// reason names the first part that failed.
+func CheckCondition(root string, c config.Condition) (bool, string) {
+	return checkCondition(root, c, true)
+}
+
+// checkCondition is CheckCondition, leaving c.Shell out unless shell is set.
+func checkCondition(root string, c config.Condition, shell bool) (bool, string) {
+	if c.File != "" {
+		matches, err := filepath.Glob(resolveCwd(root, c.File))
+		if err != nil || len(matches) == 0 {
+			return false, fmt.Sprintf("no %s in %s", c.File, emptyAs(root, "the working directory"))
+		}
+	}
+	if c.Bin != "" {
+		if _, err := exec.LookPath(c.Bin); err != nil {
+			return false, fmt.Sprintf("%s is not on PATH", c.Bin)
+		}
+	}
+	if c.Env != "" && os.Getenv(c.Env) == "" {
+		return false, fmt.Sprintf("$%s is not set", c.Env)
+	}
+	if shell && c.Shell != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), conditionTimeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, "/bin/sh", "-c", c.Shell)
+		cmd.Dir = root
+		if err := cmd.Run(); err != nil {
+			if ctx.Err() != nil {
+				return false, fmt.Sprintf("%q timed out after %s", c.Shell, conditionTimeout)
+			}
+			return false, fmt.Sprintf("%q failed: %v", c.Shell, err)
+		}
+	}
+	return true, ""
+}
+
+func emptyAs(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+// SkippedWindows returns env's windows whose when condition does not hold,
+// by tmux window name, with the reason. Shell predicates are not run: their
+// outcome is the one recorded when env's session was built (see
+// skippedOption), and a window whose predicate has not run yet counts as
+// enabled.
+func SkippedWindows(env config.Environment) map[string]string {
+	return skippedWindows(env, recordedSkips)
+}
+
+// skippedWindows is SkippedWindows with the session's recorded shell
+// predicate outcomes read by recorded, which Fake keeps in memory.
+func skippedWindows(env config.Environment, recorded func(session string) map[string]string) map[string]string {
+	skipped := map[string]string{}
+	var skips map[string]string
+	for _, w := range env.Windows {
+		if w.When == (config.Condition{}) {
+			continue
+		}
+		name := SafeWindowName(w.Name)
+		if ok, reason := checkCondition(env.Root, w.When, false); !ok {
+			skipped[name] = reason
+			continue
+		}
+		if w.When.Shell == "" {
+			continue
+		}
+		if skips == nil {
+			skips = recorded(SessionName(env.Name))
+		}
+		if reason, ok := skips[name]; ok {
+			skipped[name] = reason
+		}
+	}
+	return skipped
+}
+
+// checkWindows evaluates the when condition of each of env's windows, shell
+// predicates included, and returns the ones that do not hold.
+func checkWindows(env config.Environment) map[string]string {
+	skipped := map[string]string{}
+	for _, w := range env.Windows {
+		if w.When == (config.Condition{}) {
+			continue
+		}
+		if ok, reason := CheckCondition(env.Root, w.When); !ok {
+			skipped[SafeWindowName(w.Name)] = reason
+		}
+	}
+	return skipped
+}
+
+// recordedSkips reads session's skippedOption. A session that is not
+// running, or has no record, yields an empty map.
+func recordedSkips(session string) map[string]string {
+	recorded := map[string]string{}
+	out, err := runTmux("show-options", "-v", "-t", session, skippedOption)
+	if err != nil || strings.TrimSpace(out) == "" {
+		return recorded
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(out)), &recorded); err != nil {
+		log.Printf("recordedSkips: %s: %v", session, err)
+	}
+	return recorded
+}
+
+// recordSkipsStep is the build step that stores the outcome of env's shell
+// predicates in skippedOption, or false when env has none.
+func recordSkipsStep(env config.Environment, skipped map[string]string) (buildStep, bool) {
+	record := map[string]string{}
+	hasShell := false
+	for _, w := range env.Windows {
+		if w.When.Shell == "" {
+			continue
+		}
+		hasShell = true
+		name := SafeWindowName(w.Name)
+		if reason, ok := skipped[name]; ok {
+			record[name] = reason
+		}
+	}
+	if !hasShell {
+		return buildStep{}, false
+	}
+	b, _ := json.Marshal(record)
+	session := SessionName(env.Name)
+	return buildStep{
+		desc: "record when.shell results",
+		args: []string{"set-option", "-t", session, skippedOption, string(b)},
+	}, true
+}
+
+// WithoutWindows returns env with the named windows (tmux form) removed,
+// e.g. the ones SkippedWindows reports.
+func WithoutWindows(env config.Environment, names map[string]string) config.Environment {
+	if len(names) == 0 {
+		return env
+	}
+	windows := make([]config.WindowTemplate, 0, len(env.Windows))
+	for _, w := range env.Windows {
+		if _, skip := names[SafeWindowName(w.Name)]; !skip {
+			windows = append(windows, w)
+		}
+	}
+	env.Windows = windows
+	return env
+}
+
+// enabledWindows drops the windows whose when condition does not hold,
+// logging why. It also returns what checkWindows found, for
+// recordSkipsStep.
+func enabledWindows(env config.Environment) (config.Environment, map[string]string) {
+	skipped := checkWindows(env)
+	for name, reason := range skipped {
+		log.Printf("EnsureSession: %s: skipping window %q: %s", SessionName(env.Name), name, reason)
+	}
+	return WithoutWindows(env, skipped), skipped
+}
@@ -0,0 +1,102 @@
+package tmux
+
+import (
+	"os"
+	"strings"
+
+	"ide/internal/config"
+)
+
+// cmdPlaceholder marks where a wrapper wants the quoted command line.
+const cmdPlaceholder = "{cmd}"
+
+// Wrapper resolves the wrapper w runs inside: its own, unless unset, in
+// which case the environment's. config.WrapperNone opts the window out.
+func Wrapper(env config.Environment, w config.WindowTemplate) string {
+	wrapper := strings.TrimSpace(w.Wrapper)
+	if wrapper == "" {
+		wrapper = strings.TrimSpace(env.Wrapper)
+	}
+	if wrapper == config.WrapperNone {
+		return ""
+	}
+	return wrapper
+}
+
+// CommandLine is what w's window runs in env, wrapper included but without
+// the exit-status bookkeeping startupCommand adds around it. It is empty
+// for a plain, unwrapped shell.
+func CommandLine(env config.Environment, w config.WindowTemplate) string {
+	wrapper := Wrapper(env, w)
+	if sendsKeys(w) || strings.TrimSpace(w.Cmd) == "" {
+		if wrapper == "" && w.Shell == "" {
+			return ""
+		}
+		return strings.TrimPrefix(interactiveShell(wrapper, windowShell(w)), "exec ")
+	}
+	return wrapCommand(wrapper, windowShell(w), strings.TrimSpace(w.Cmd))
+}
+
+// wrapCommand runs command through wrapper. A wrapper with a {cmd}
+// placeholder gets the command quoted in its place and brings its own shell
+// ("docker compose exec app sh -c {cmd}"); any other wrapper is a prefix
+// for shell, as a login shell, running the command ("nix develop -c").
+func wrapCommand(wrapper, shell, command string) string {
+	if strings.Contains(wrapper, cmdPlaceholder) {
+		return strings.ReplaceAll(wrapper, cmdPlaceholder, shellQuote(command))
+	}
+	line := shell + " -lc " + shellQuote(command)
+	if wrapper == "" {
+		return line
+	}
+	return wrapper + " " + line
+}
+
+// interactiveShell is the shell a window runs once there is no command (or
+// no longer one): shell, or the one inside wrapper. Only the unwrapped shell
+// is exec'd, since a prefix wrapper may be shell syntax
+// (". .venv/bin/activate &&") rather than a program. A {cmd} wrapper runs
+// its own side's $SHELL, as the host's may not exist there. The shell is a
+// login one (-l) as well: it is started from /bin/sh -c, so it would
+// otherwise miss the profile the user's default-shell reads.
+func interactiveShell(wrapper, shell string) string {
+	switch {
+	case wrapper == "":
+		return "exec " + shell + " -il"
+	case strings.Contains(wrapper, cmdPlaceholder):
+		return strings.ReplaceAll(wrapper, cmdPlaceholder, shellQuote(`exec "${SHELL:-/bin/sh}" -il`))
+	default:
+		return wrapper + " " + shell + " -il"
+	}
+}
+
+// ShellPIDOption is the pane user option the fallback shell of a prefix
+// wrapper records its pid in. The wrapper cannot be exec'd, so the idle
+// shell sits below the pane's own process, and stopping a session must
+// still tell it from work.
+const ShellPIDOption = "@ide_shell"
+
+// fallbackShell is interactiveShell as a window runs it: under a prefix
+// wrapper, a /bin/sh records its pid in ShellPIDOption and then execs the
+// shell, which keeps the pid.
+func fallbackShell(wrapper, shell string) string {
+	if wrapper == "" || strings.Contains(wrapper, cmdPlaceholder) {
+		return interactiveShell(wrapper, shell)
+	}
+	record := `tmux set-option -p -t "$TMUX_PANE" ` + ShellPIDOption + ` $$ 2>/dev/null; exec ` + shell + " -il"
+	return wrapper + " /bin/sh -c " + shellQuote(record)
+}
+
+// windowShell is the shell w's command and fallback shell run in: its own
+// shell if set, else $SHELL, else /bin/sh. It is shell-quoted, ready for a
+// command line, since both settings come from the user and a path may hold
+// spaces or metacharacters.
+func windowShell(w config.WindowTemplate) string {
+	if w.Shell != "" {
+		return shellQuote(w.Shell)
+	}
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shellQuote(shell)
+	}
+	return "/bin/sh"
+}
@@ -2,31 +2,35 @@ package ui
 
 import (
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
 
 	"ide/internal/agentstatus"
 	"ide/internal/config"
+	"ide/internal/notify"
 	"ide/internal/tmux"
 )
 
-// isAIToolProcess and windowKey are thin shims around the agentstatus
-// package so existing call sites in this package don't need to be rewritten.
-func isAIToolProcess(name string) bool        { return agentstatus.IsAITool(name) }
+// windowKey is a thin shim around the agentstatus package so existing call
+// sites in this package don't need to be rewritten.
 func windowKey(session, window string) string { return agentstatus.Key(session, window) }
-func detectAgentStatus(current ProcessInfo, currentStatus AgentStatus, lowActivityCount int, baselineCPU float64, sampleCount int) (AgentStatus, int, float64, int) {
-	return agentstatus.Detect(current, currentStatus, lowActivityCount, baselineCPU, sampleCount)
-}
 
 // isAIWindow reports whether the window should be tracked as an AI agent
 // window — because the template has the [ai] tag, the template's command is
 // a known AI CLI, or its current foreground process is a known AI CLI.
 func (m Model) isAIWindow(env config.Environment, windowName, currentProcess string) bool {
 	if tmpl, ok := findWindowTemplate(env, windowName); ok {
-		if HasTag(tmpl, "ai") || isAIToolProcess(tmpl.Cmd) {
+		if HasTag(tmpl, "ai") || m.agents.IsAITool(tmpl.Cmd) {
 			return true
 		}
 	}
-	return isAIToolProcess(currentProcess)
+	return m.agents.IsAITool(currentProcess)
 }
 
 // getWindowAgentStatus returns the current agent status for a window
@@ -62,14 +66,28 @@ func (m Model) getWindowAgentStatus(session, window string) AgentStatus {
 
 // getWindowStatusColor returns the color for a given agent status
 func (m Model) getWindowStatusColor(status AgentStatus) string {
-	theme := m.currentTheme()
+	if color := agentStatusColor(status); color != "" {
+		return color
+	}
+	return m.currentTheme().Muted
+}
+
+// agentStatusColor is the fixed color of a non-idle status, shared with the
+// search popup; empty for idle.
+func agentStatusColor(status AgentStatus) string {
 	switch status {
 	case AgentStatusCooking:
 		return "#fbbf24" // Amber/yellow for cooking
 	case AgentStatusAwaitingInput:
 		return "#22d3ee" // Cyan for awaiting input
+	case AgentStatusApproval:
+		return "#f87171" // Red for a pending permission prompt
+	case AgentStatusError:
+		return "#e879f9" // Magenta for a reported error
+	case AgentStatusStalled:
+		return "#fb923c" // Orange for cooking with a frozen screen
 	default:
-		return theme.Muted
+		return ""
 	}
 }
 
@@ -87,13 +105,20 @@ func (m *Model) jumpToNextCookingSession(direction int) {
 			continue
 		}
 		status := m.getSessionAgentStatus(env)
-		if status == AgentStatusCooking || status == AgentStatusAwaitingInput {
+		if status != AgentStatusIdle {
 			m.selectedEnv = idx
 			m.selectedWindow = 0
 			m.focusPane = focusPaneEnvironments
 			statusLabel := "Cooking"
-			if status == AgentStatusAwaitingInput {
+			switch status {
+			case AgentStatusAwaitingInput:
 				statusLabel = "Awaiting Input"
+			case AgentStatusApproval:
+				statusLabel = "Awaiting Approval"
+			case AgentStatusError:
+				statusLabel = "Error"
+			case AgentStatusStalled:
+				statusLabel = "Stalled"
 			}
 			m.status = fmt.Sprintf("Jumped to %s (%s)", env.Name, statusLabel)
 			return
@@ -106,13 +131,13 @@ func (m *Model) jumpToNextCookingSession(direction int) {
 func (m Model) windowNamesForEnv(env config.Environment) []string {
 	session := tmux.SessionName(env.Name)
 	if windows, ok := m.sessionWindows[session]; ok && len(windows) > 0 {
-		return windows
+		return tmux.WithLazyWindows(m.enabledEnv(env), windows)
 	}
-	return tmux.WindowNames(env)
+	return tmux.WindowNames(m.enabledEnv(env))
 }
 
 // getSessionAgentStatus returns the highest-priority agent status across all windows of a session.
-// Priority: Cooking > AwaitingInput > Idle
+// Priority: AwaitingApproval > Error > Stalled > Cooking > AwaitingInput > Idle
 func (m Model) getSessionAgentStatus(env config.Environment) AgentStatus {
 	session := tmux.SessionName(env.Name)
 	windows := m.windowNamesForEnv(env)
@@ -128,74 +153,227 @@ func (m Model) getSessionAgentStatus(env config.Environment) AgentStatus {
 			continue
 		}
 		if hasInfo {
-			if info.Status == AgentStatusCooking {
-				return AgentStatusCooking
-			}
-			if info.Status == AgentStatusAwaitingInput {
-				highest = AgentStatusAwaitingInput
+			if agentStatusRank(info.Status) > agentStatusRank(highest) {
+				highest = info.Status
 			}
 		}
 	}
 	return highest
 }
 
+// agentStatusRank orders statuses by how much they need the user, for
+// getSessionAgentStatus.
+func agentStatusRank(status AgentStatus) int {
+	switch status {
+	case AgentStatusApproval:
+		return 5
+	case AgentStatusError:
+		return 4
+	case AgentStatusStalled:
+		return 3
+	case AgentStatusCooking:
+		return 2
+	case AgentStatusAwaitingInput:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// closeAgentTimeline ends the timeline of a window ide no longer samples at
+// its last sample, so the status it was in does not run on to now. A
+// timeline loaded from a previous run but never sampled since closes at its
+// last transition.
+func (m *Model) closeAgentTimeline(key string) tea.Cmd {
+	tl := m.agentTimelines[key]
+	at := m.windowProcessInfo[key].Current.Timestamp
+	tr, ok := tl.Close(at)
+	if !ok {
+		return nil
+	}
+	m.agentTimelines[key] = append(tl, tr)
+	return recordTransitionCmd(key, tr)
+}
+
+// CloseAgentTimelines records that ide stopped watching the agents: every
+// open timeline ends at its window's last sample. Main calls it once the
+// program has exited, so the records are written before ide does.
+func (m Model) CloseAgentTimelines() {
+	for key := range m.agentTimelines {
+		if cmd := m.closeAgentTimeline(key); cmd != nil {
+			cmd()
+		}
+	}
+}
+
 // updateWindowProcessInfoFromMsg updates the process info from an agentStatusUpdateMsg
-// This should be called from the Update method when receiving agentStatusUpdateMsg
-func (m *Model) updateWindowProcessInfoFromMsg(session, window string, procInfo ProcessInfo, command string) {
+// This should be called from the Update method for each update of an agentStatusesMsg.
+// The returned command records the transition, files an inbox entry when the
+// agent finished cooking and sends a notification when the new status calls
+// for one.
+func (m *Model) updateWindowProcessInfoFromMsg(session, window string, sample AgentSample, command string) tea.Cmd {
 	log.Printf("[updateWindowProcessInfoFromMsg] Processing msg for session=%s window=%s", session, window)
 
 	key := windowKey(session, window)
 
-	// Get previous info and current tracking state
-	var previous ProcessInfo
-	var currentStatus AgentStatus
-	var lowActivityCount int
-	var baselineCPU float64
-	var sampleCount int
-	previousCommand := ""
-	if existing, ok := m.windowProcessInfo[key]; ok {
-		previous = existing.Current
-		currentStatus = existing.Status
-		lowActivityCount = existing.LowActivityCount
-		baselineCPU = existing.BaselineCPU
-		sampleCount = existing.SampleCount
-		previousCommand = existing.Command
-	}
-
+	// The detectors work on the previous tracking state (hysteresis counters
+	// and adaptive baseline) and update it in place.
+	info, seen := m.windowProcessInfo[key]
+	previous := info.Status
 	log.Printf("[updateWindowProcessInfoFromMsg] Current tracking: status=%s baseline=%.2f samples=%d",
-		currentStatus, baselineCPU, sampleCount)
+		info.Status, info.BaselineCPU, info.SampleCount)
 
-	// Detect status with hysteresis and adaptive baseline
-	status, newLowActivityCount, newBaselineCPU, newSampleCount := detectAgentStatus(
-		procInfo, currentStatus, lowActivityCount, baselineCPU, sampleCount,
-	)
+	status, ok := agentstatus.DefaultDetector(m.agents).Detect(sample, &info)
+	if !ok {
+		status = AgentStatusIdle
+	}
 
-	log.Printf("[updateWindowProcessInfoFromMsg] New status: %s baseline=%.2f", status, newBaselineCPU)
+	log.Printf("[updateWindowProcessInfoFromMsg] New status: %s baseline=%.2f", status, info.BaselineCPU)
 
 	if command == "" {
-		command = previousCommand
+		command = info.Command
+	}
+	info.Previous = info.Current
+	info.Current = sample.Process
+	info.Status = status
+	info.Command = command
+	info.Report = sample.Report
+	info.Tool = sample.Tool
+	info.Prompt, info.PromptShown = nil, false
+	if status == AgentStatusApproval {
+		info.Prompt = m.agents.ApprovalPrompt(sample.Tool, sample.Output, agentPromptLines)
+		info.PromptShown = m.agents.ShowsApproval(sample.Tool, sample.Output)
+	}
+	m.windowProcessInfo[key] = info
+
+	// Only a change is a transition: after a restart the first sample
+	// usually repeats the recorded status.
+	var record tea.Cmd
+	tl := m.agentTimelines[key]
+	if current, _ := tl.Current(); len(tl) == 0 || current != status {
+		at := sample.Process.Timestamp
+		if at.IsZero() {
+			at = time.Now()
+		}
+		tr := agentstatus.Transition{At: at, From: previous, To: status}
+		m.agentTimelines[key] = append(tl, tr).Since(at.Add(-agentSparkSpan))
+		record = recordTransitionCmd(key, tr)
 	}
 
-	// Update tracking
-	m.windowProcessInfo[key] = WindowProcessInfo{
-		Current:          procInfo,
-		Previous:         previous,
-		Status:           status,
-		LowActivityCount: newLowActivityCount,
-		BaselineCPU:      newBaselineCPU,
-		SampleCount:      newSampleCount,
-		Command:          command,
+	// The first sample of a window only establishes where it stands: an
+	// agent that was already waiting when ide started is not news.
+	if !seen {
+		return record
 	}
+	cmds := []tea.Cmd{record}
+	if status == AgentStatusAwaitingInput && (previous == AgentStatusCooking || previous == AgentStatusStalled) {
+		cmds = append(cmds, m.addInboxEntry(session, window, sample.Tool, sample.Output, sample.Process.Timestamp))
+	}
+	if notify.ShouldNotify(previous, status) {
+		cmds = append(cmds, m.notifyCmd(notify.Event{
+			Session: session,
+			Window:  window,
+			From:    previous,
+			To:      status,
+			Message: sample.Report.Message,
+			At:      sample.Process.Timestamp,
+		}))
+	}
+	return tea.Batch(cmds...)
 }
 
-// formatWindowLabel formats a window name with its status suffix
-func (m Model) formatWindowLabel(name string, status AgentStatus) string {
+// newNotifier builds the notifier for the loaded config. Bell and OSC
+// notifications are written to term. Tests swap it out.
+var newNotifier = func(cfg config.Notify, term io.Writer) (notify.Notifier, error) {
+	return notify.New(cfg, term)
+}
+
+// terminalOut is the terminal the TUI draws on, where terminalEscapeMsg
+// writes. Tests swap it out.
+var terminalOut io.Writer = os.Stdout
+
+// terminalEscapeMsg carries bell and OSC sequences to Update, which writes
+// them to terminalOut: from a command's goroutine they would race the
+// renderer's own writes.
+type terminalEscapeMsg struct {
+	seq string
+}
+
+// escapeBuffer collects what the bell and OSC notifiers write, for
+// notifyCmd to hand to Update. Concurrent commands share it; whichever
+// drains it first carries every pending sequence.
+type escapeBuffer struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (b *escapeBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *escapeBuffer) take() string {
+	if b == nil {
+		return ""
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := b.buf.String()
+	b.buf.Reset()
+	return s
+}
+
+// notifyCmd delivers ev off the update loop, unless the window's
+// environment is muted. Terminal sequences come back as a
+// terminalEscapeMsg.
+func (m Model) notifyCmd(ev notify.Event) tea.Cmd {
+	if m.notifier == nil {
+		return nil
+	}
+	env, ok := m.envForSession(ev.Session)
+	if !ok || env.Mute {
+		return nil
+	}
+	ev.Env = env.Name
+	if ev.At.IsZero() {
+		ev.At = time.Now()
+	}
+	n, escapes := m.notifier, m.escapes
+	return func() tea.Msg {
+		if err := n.Notify(ev); err != nil {
+			log.Printf("notify %s: %v", ev.Title(), err)
+		}
+		if seq := escapes.take(); seq != "" {
+			return terminalEscapeMsg{seq: seq}
+		}
+		return nil
+	}
+}
+
+// agentStatusGlyph is the marker drawn next to a window or session with
+// the given status; empty for idle.
+func agentStatusGlyph(status AgentStatus) string {
 	switch status {
 	case AgentStatusCooking:
-		return name + " ●"
+		return "●"
 	case AgentStatusAwaitingInput:
-		return name + " ◆"
+		return "◆"
+	case AgentStatusApproval:
+		return "▲"
+	case AgentStatusError:
+		return "✖"
+	case AgentStatusStalled:
+		return "◌"
 	default:
-		return name
+		return ""
+	}
+}
+
+// formatWindowLabel formats a window name with its status suffix
+func (m Model) formatWindowLabel(name string, status AgentStatus) string {
+	if glyph := agentStatusGlyph(status); glyph != "" {
+		return name + " " + glyph
 	}
+	return name
 }
@@ -3,6 +3,7 @@ package ui
 import (
 	"testing"
 
+	"ide/internal/agentstatus"
 	"ide/internal/config"
 )
 
@@ -17,7 +18,7 @@ func TestModelIsAIWindow(t *testing.T) {
 			{Name: "build", Cmd: "make watch"},
 		},
 	}
-	m := Model{}
+	m := Model{agents: agentstatus.BuiltinRegistry()}
 	tests := []struct {
 		name    string
 		window  string
@@ -2,9 +2,12 @@ package ui
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 
+	"ide/internal/agentstatus"
 	"ide/internal/tmux"
 )
 
@@ -16,8 +19,18 @@ type agentItem struct {
 	envName    string
 	windowName string
 	status     AgentStatus
+	message    string // from the agent's active self-report, if any
+	timeline   agentstatus.Timeline
+	tool       string
+	prompt     []string // the approval prompt, drawn under the row
+	shown      bool     // the approval prompt was detected on screen
 }
 
+// agentPromptLines is how much of an approval prompt the Agents pane shows
+// under the agent: enough to see what it wants to run, so it can be
+// answered without attaching.
+const agentPromptLines = 3
+
 // agentItems collects every AI window from every running session into a
 // flat list, in declaration order (envs ordered by config, windows ordered
 // by tmux). Idle agents are included so the pane lists every agent the
@@ -30,6 +43,9 @@ func (m Model) agentItems() []agentItem {
 			continue
 		}
 		for _, wName := range m.windowNamesForEnv(env) {
+			if m.isPendingWindow(env, wName) {
+				continue
+			}
 			key := windowKey(session, wName)
 			info, hasInfo := m.windowProcessInfo[key]
 			cmd := ""
@@ -40,14 +56,28 @@ func (m Model) agentItems() []agentItem {
 				continue
 			}
 			status := AgentStatusIdle
+			message := ""
+			var prompt []string
+			shown := false
 			if hasInfo {
 				status = info.Status
+				if info.Report.Active(info.Current.Timestamp) {
+					message = info.Report.Message
+				}
+				if status == AgentStatusApproval {
+					prompt, shown = info.Prompt, info.PromptShown
+				}
 			}
 			items = append(items, agentItem{
 				envIdx:     envIdx,
 				envName:    env.Name,
 				windowName: wName,
 				status:     status,
+				message:    message,
+				timeline:   m.agentTimelines[key],
+				tool:       info.Tool,
+				prompt:     prompt,
+				shown:      shown,
 			})
 		}
 	}
@@ -61,18 +91,32 @@ func (m Model) renderAgentsPane(width, height int) string {
 	contentWidth := paneContentWidth(width)
 
 	items := m.agentItems()
-	rows := make([]string, 0, len(items))
+	now := time.Now()
+	rows := make([]string, 0, agentPaneRows(items))
+	selectedRow := 0
+	promptStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Muted)).
+		Background(lipgloss.Color(theme.PaneBG))
 	for idx, it := range items {
 		indicator := ""
-		switch it.status {
-		case AgentStatusCooking:
-			indicator = " ●"
-		case AgentStatusAwaitingInput:
-			indicator = " ◆"
+		if glyph := agentStatusGlyph(it.status); glyph != "" {
+			indicator = " " + glyph
 		}
 		content := fmt.Sprintf("%s %s / %s%s", numPrefix(idx), it.envName, it.windowName, indicator)
+		if spark := agentSparkline(it.timeline, now); spark != "" {
+			content += " " + spark
+		}
+		if badge := agentWaitBadge(it.status, it.timeline, now); badge != "" {
+			content += " " + badge
+		}
+		if it.message != "" {
+			content += " — " + it.message
+		}
 
 		selected := idx == m.selectedAgent
+		if selected {
+			selectedRow = len(rows)
+		}
 		selectedStyle := selectedLineStyle
 		var defaultStyle *lipgloss.Style
 		if it.status != AgentStatusIdle {
@@ -91,8 +135,70 @@ func (m Model) renderAgentsPane(width, height int) string {
 			defaultStyle = &ds
 		}
 		rows = append(rows, renderListRow(content, selected, contentWidth, theme, selectedStyle, defaultStyle))
+		for _, line := range it.prompt {
+			rows = append(rows, renderListRow("    │ "+line, false, contentWidth, theme, selectedStyle, &promptStyle))
+		}
 	}
 
 	empty := []string{"", "No AI agents detected.", "Start a session with an [ai]-tagged window or run a known AI CLI."}
-	return m.renderListPane(width, height, title, focused, rows, m.selectedAgent, empty)
+	return m.renderListPane(width, height, title, focused, rows, selectedRow, empty)
+}
+
+// agentPaneRows is how many lines the Agents pane needs for items: one per
+// agent plus the prompts of those awaiting approval.
+func agentPaneRows(items []agentItem) int {
+	n := len(items)
+	for _, it := range items {
+		n += len(it.prompt)
+	}
+	return n
+}
+
+// agentSparkSpan and agentSparkSlots size the Agents pane sparkline: the
+// last hour, in five-minute slots.
+const (
+	agentSparkSpan  = time.Hour
+	agentSparkSlots = 12
+)
+
+// agentSparkline draws what the agent did over the last agentSparkSpan,
+// taller for busier: cooking is a full bar, waiting on the user half of
+// one, idle a baseline. Empty until the agent has a timeline.
+func agentSparkline(tl agentstatus.Timeline, now time.Time) string {
+	if len(tl) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, status := range tl.Buckets(now.Add(-agentSparkSpan), now, agentSparkSlots) {
+		switch status {
+		case AgentStatusCooking:
+			b.WriteString("█")
+		case AgentStatusApproval, AgentStatusError, AgentStatusStalled:
+			b.WriteString("▅")
+		case AgentStatusAwaitingInput:
+			b.WriteString("▃")
+		default:
+			b.WriteString("▁")
+		}
+	}
+	return b.String()
+}
+
+// agentWaitBadge says how long an agent has been waiting on the user, e.g.
+// "waiting 12m", or "stalled 3m" when it is stuck; empty while it cooks or
+// idles.
+func agentWaitBadge(status AgentStatus, tl agentstatus.Timeline, now time.Time) string {
+	label := "waiting"
+	switch status {
+	case AgentStatusAwaitingInput, AgentStatusApproval, AgentStatusError:
+	case AgentStatusStalled:
+		label = "stalled"
+	default:
+		return ""
+	}
+	current, since := tl.Current()
+	if current != status || since.IsZero() {
+		return ""
+	}
+	return label + " " + agentstatus.FormatDuration(now.Sub(since))
 }
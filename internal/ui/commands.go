@@ -5,21 +5,32 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/charmbracelet/x/vt"
 
+	"ide/internal/agentstatus"
 	"ide/internal/config"
+	"ide/internal/state"
 	"ide/internal/tmux"
 )
 
+// backend is the tmux the commands below drive. Tests swap in a tmux.Fake.
+var backend tmux.Backend = tmux.Exec{}
+
 type configLoadedMsg struct {
 	envs      []config.Environment
 	templates []config.Template
 	theme     string
+	skipped   map[string]map[string]string // env name -> window -> failed when condition
+	notify    config.Notify
+	agents    *agentstatus.Registry // nil when the "agents" section is broken
 	err       error
 }
 
@@ -27,6 +38,8 @@ type sessionsLoadedMsg struct {
 	names    []string
 	windows  map[string][]string
 	commands map[string]map[string]string // session -> window -> foreground command
+	exits    map[string]map[string]int    // session -> window -> exit code of an exited startup command
+	at       time.Time                    // when the snapshot was requested
 	err      error
 }
 
@@ -66,6 +79,7 @@ type envWindowsSavedMsg struct {
 type sessionRestartedMsg struct {
 	envName    string
 	session    string
+	orphans    int // processes that outlived the old session
 	sessionErr error
 	err        error
 }
@@ -77,6 +91,22 @@ type templateDeletedMsg struct {
 
 type sessionKilledMsg struct {
 	session string
+	forced  int                // processes still running when the stop timeout ran out
+	orphans []tmux.PaneProcess // processes still alive after the kill
+	err     error
+}
+
+type orphansTerminatedMsg struct {
+	session   string
+	remaining []tmux.PaneProcess
+	err       error
+}
+
+// sessionProcessesMsg carries what is still running in a session, fetched
+// before the kill confirmation opens.
+type sessionProcessesMsg struct {
+	session string
+	procs   []tmux.PaneProcess
 	err     error
 }
 
@@ -84,9 +114,21 @@ type environmentDeletedMsg struct {
 	environment string
 	session     string
 	killed      bool
+	orphans     int // processes that outlived the killed session
 	err         error
 }
 
+type windowRespawnedMsg struct {
+	key string // session:window
+	err error
+}
+
+type keysSentMsg struct {
+	target string
+	done   string // status on success; "Sent to <target>" when empty
+	err    error
+}
+
 type themePersistedMsg struct {
 	name string
 	err  error
@@ -101,12 +143,26 @@ type panePreviewMsg struct {
 
 type previewTickMsg struct{}
 
+// tmuxCapabilitiesMsg reports the installed tmux's version and features.
+type tmuxCapabilitiesMsg struct {
+	caps tmux.Capabilities
+	err  error
+}
+
 // agentStatusUpdateMsg carries process info updates for agent status detection
 type agentStatusUpdateMsg struct {
 	session  string
 	window   string
 	procInfo ProcessInfo
 	command  string
+	tool     string // registry name of the agent, for its pattern pack
+	output   string // ANSI-stripped pane text; "" when the capture failed
+	report   agentstatus.Report
+}
+
+// agentStatusesMsg carries one tick's samples of every agent window.
+type agentStatusesMsg struct {
+	updates []agentStatusUpdateMsg
 }
 
 func loadConfigCmd() tea.Cmd {
@@ -135,7 +191,42 @@ func loadConfigCmd() tea.Cmd {
 			}
 			return strings.ToLower(templates[i].Name) < strings.ToLower(templates[j].Name)
 		})
-		return configLoadedMsg{envs: envs, templates: templates, theme: theme}
+		return configLoadedMsg{envs: envs, templates: templates, theme: theme, skipped: skippedWindows(envs), notify: data.Notify, agents: agentRegistry(data.Agents)}
+	}
+}
+
+// agentRegistry builds the registry of the config's agent tools and
+// thresholds. It is nil for a broken "agents" section, which keeps the
+// previous registry; `ide agent tools` reports what is wrong with it.
+func agentRegistry(cfg config.Agents) *agentstatus.Registry {
+	r, err := agentstatus.NewRegistry(cfg)
+	if err != nil {
+		log.Printf("loadConfig: %v", err)
+		return nil
+	}
+	return r
+}
+
+// skippedWindows evaluates the when conditions of every environment's
+// windows. It runs off the update loop: it globs the roots and asks tmux for
+// the shell predicate results recorded by running sessions.
+func skippedWindows(envs []config.Environment) map[string]map[string]string {
+	out := map[string]map[string]string{}
+	for _, env := range envs {
+		if skipped := backend.SkippedWindows(env); len(skipped) > 0 {
+			out[env.Name] = skipped
+		}
+	}
+	return out
+}
+
+func detectTmuxCmd() tea.Cmd {
+	return func() tea.Msg {
+		if err := backend.CheckTmuxExists(); err != nil {
+			return tmuxCapabilitiesMsg{err: err}
+		}
+		caps, err := backend.DetectCapabilities()
+		return tmuxCapabilitiesMsg{caps: caps, err: err}
 	}
 }
 
@@ -145,7 +236,8 @@ func loadSessionsCmd() tea.Cmd {
 		// window, and every foreground pane command at once. Replaces the old
 		// list-sessions + N parallel list-windows fan-out and removes the need
 		// for per-window CurrentProcess calls in the polling path.
-		snap, err := tmux.ListSessionsSnapshot()
+		at := time.Now()
+		snap, err := backend.ListSessionsSnapshot()
 		if err != nil {
 			log.Printf("loadSessions: ERROR snapshotting tmux: %v", err)
 			return sessionsLoadedMsg{err: err}
@@ -155,6 +247,8 @@ func loadSessionsCmd() tea.Cmd {
 			names:    snap.Names,
 			windows:  snap.Windows,
 			commands: snap.Commands,
+			exits:    snap.Exits,
+			at:       at,
 		}
 	}
 }
@@ -219,9 +313,9 @@ func createEnvironmentCmd(name, root string, windows []config.WindowTemplate) te
 			return environmentCreatedMsg{err: err}
 		}
 
-		sessionErr := tmux.CheckTmuxExists()
+		sessionErr := backend.CheckTmuxExists()
 		if sessionErr == nil {
-			sessionErr = tmux.EnsureSession(newEnv)
+			sessionErr = backend.EnsureSession(newEnv)
 		}
 		if sessionErr != nil {
 			log.Printf("createEnvironment: ERROR ensuring session: %v", sessionErr)
@@ -273,6 +367,7 @@ func saveTemplateCmd(originalName, name string, windows []config.WindowTemplate)
 			template := config.Template{Name: name, Windows: cloneWindowTemplates(windows)}
 			edited = targetIdx >= 0
 			if edited {
+				template.Windows = config.MergeWindowSettings(template.Windows, data.Templates[targetIdx].Windows)
 				data.Templates[targetIdx] = template
 			} else {
 				data.Templates = append(data.Templates, template)
@@ -307,7 +402,7 @@ func saveEnvWindowsCmd(envName string, windows []config.WindowTemplate) tea.Cmd
 			if idx < 0 {
 				return fmt.Errorf("environment %q not found", envName)
 			}
-			data.Environments[idx].Windows = cloneWindowTemplates(windows)
+			data.Environments[idx].Windows = config.MergeWindowSettings(windows, data.Environments[idx].Windows)
 			savedName = data.Environments[idx].Name
 			return nil
 		}); err != nil {
@@ -340,37 +435,56 @@ func restartSessionCmd(envName string) tea.Cmd {
 			return sessionRestartedMsg{envName: envName, err: fmt.Errorf("environment %q not found", envName)}
 		}
 		session := tmux.SessionName(env.Name)
-		if err := tmux.CheckTmuxExists(); err != nil {
+		if err := backend.CheckTmuxExists(); err != nil {
 			return sessionRestartedMsg{envName: env.Name, session: session, err: err}
 		}
-		has, err := tmux.HasSession(session)
+		has, err := backend.HasSession(session)
 		if err != nil {
 			return sessionRestartedMsg{envName: env.Name, session: session, err: err}
 		}
+		var stopped tmux.StopResult
 		if has {
-			if err := tmux.KillSession(session); err != nil {
+			stopped, err = backend.StopSession(env)
+			if err != nil {
 				return sessionRestartedMsg{envName: env.Name, session: session, err: err}
 			}
 		}
-		sessionErr := tmux.EnsureSession(env)
-		return sessionRestartedMsg{envName: env.Name, session: session, sessionErr: sessionErr}
+		sessionErr := backend.EnsureSession(env)
+		return sessionRestartedMsg{envName: env.Name, session: session, orphans: len(stopped.Orphans), sessionErr: sessionErr}
+	}
+}
+
+func sessionProcessesCmd(session string) tea.Cmd {
+	return func() tea.Msg {
+		procs, err := backend.SessionProcesses(session)
+		return sessionProcessesMsg{session: session, procs: procs, err: err}
 	}
 }
 
-func killSessionCmd(session string) tea.Cmd {
+// killSessionCmd stops env's session gracefully (see tmux.StopSession) and
+// kills whatever is left once the stop timeout runs out.
+func killSessionCmd(env config.Environment) tea.Cmd {
 	return func() tea.Msg {
+		session := tmux.SessionName(env.Name)
 		log.Printf("killSession: session=%q", session)
-		if err := tmux.CheckTmuxExists(); err != nil {
+		if err := backend.CheckTmuxExists(); err != nil {
 			log.Printf("killSession: tmux not found: %v", err)
 			return sessionKilledMsg{session: session, err: err}
 		}
-		err := tmux.KillSession(session)
+		res, err := backend.StopSession(env)
 		if err != nil {
 			log.Printf("killSession: ERROR killing %q: %v", session, err)
 		} else {
-			log.Printf("killSession: killed %q", session)
+			log.Printf("killSession: killed %q (%d processes forced, %d orphaned)", session, len(res.Forced), len(res.Orphans))
 		}
-		return sessionKilledMsg{session: session, err: err}
+		return sessionKilledMsg{session: session, forced: len(res.Forced), orphans: res.Orphans, err: err}
+	}
+}
+
+func terminateOrphansCmd(session string) tea.Cmd {
+	return func() tea.Msg {
+		remaining, err := backend.TerminateOrphans(session, tmux.OrphanGrace)
+		return orphansTerminatedMsg{session: session, remaining: remaining, err: err}
 	}
 }
 
@@ -427,20 +541,23 @@ func deleteEnvironmentCmd(name string) tea.Cmd {
 
 		session := tmux.SessionName(removed.Name)
 		killed := false
-		if tmux.CheckTmuxExists() == nil {
-			has, hErr := tmux.HasSession(session)
+		orphans := 0
+		if backend.CheckTmuxExists() == nil {
+			has, hErr := backend.HasSession(session)
 			if hErr != nil {
 				return environmentDeletedMsg{err: hErr}
 			}
 			if has {
-				if err := tmux.KillSession(session); err != nil {
+				res, err := backend.StopSession(removed)
+				if err != nil {
 					return environmentDeletedMsg{err: err}
 				}
 				killed = true
+				orphans = len(res.Orphans)
 			}
 		}
 
-		return environmentDeletedMsg{environment: removed.Name, session: session, killed: killed}
+		return environmentDeletedMsg{environment: removed.Name, session: session, killed: killed, orphans: orphans}
 	}
 }
 
@@ -495,8 +612,11 @@ func moveWindowOrderCmd(envName, windowName string, direction int) tea.Cmd {
 
 		var sessionErr error
 		session := tmux.SessionName(resolvedEnvName)
-		if has, _ := tmux.HasSession(session); has {
-			if err := tmux.SwapWindow(session, sourceWindow, destinationWindow); err != nil {
+		// A lazy window that has not been created yet has nothing to swap;
+		// it is created in its new place when first attached to.
+		live, _ := backend.ListWindows(session)
+		if slices.Contains(live, sourceWindow) && slices.Contains(live, destinationWindow) {
+			if err := backend.SwapWindow(session, sourceWindow, destinationWindow); err != nil {
 				sessionErr = fmt.Errorf("swap live tmux windows: %w", err)
 			}
 		}
@@ -508,26 +628,30 @@ func moveWindowOrderCmd(envName, windowName string, direction int) tea.Cmd {
 func prepareAttachCmd(env config.Environment, windowName string) tea.Cmd {
 	return func() tea.Msg {
 		log.Printf("prepareAttach: env=%q window=%q", env.Name, windowName)
-		if err := tmux.CheckTmuxExists(); err != nil {
+		if err := backend.CheckTmuxExists(); err != nil {
 			log.Printf("prepareAttach: tmux not found: %v", err)
 			return attachReadyMsg{err: err}
 		}
-		if err := tmux.EnsureSession(env); err != nil {
+		if err := backend.EnsureSession(env); err != nil {
 			log.Printf("prepareAttach: ERROR ensuring session for %q: %v", env.Name, err)
 			return attachReadyMsg{err: err}
 		}
+		if err := backend.EnsureWindow(env, windowName); err != nil {
+			log.Printf("prepareAttach: ERROR creating lazy window %q: %v", windowName, err)
+			return attachReadyMsg{err: err}
+		}
 		session := tmux.SessionName(env.Name)
 		target := tmux.AttachTarget(env, windowName)
 		log.Printf("prepareAttach: session=%q target=%q", session, target)
 		if strings.TrimSpace(windowName) != "" {
-			hasWindow, err := tmux.HasWindow(session, windowName)
+			hasWindow, err := backend.HasWindow(session, windowName)
 			if err != nil {
 				log.Printf("prepareAttach: ERROR checking window %q: %v", windowName, err)
 				return attachReadyMsg{err: err}
 			}
 			log.Printf("prepareAttach: hasWindow(%q)=%v", windowName, hasWindow)
 			if hasWindow {
-				_ = tmux.SelectWindow(target)
+				_ = backend.SelectWindow(target)
 			} else {
 				log.Printf("prepareAttach: window %q not found, falling back to session root", windowName)
 				target = session
@@ -538,6 +662,54 @@ func prepareAttachCmd(env config.Environment, windowName string) tea.Cmd {
 	}
 }
 
+func respawnWindowCmd(env config.Environment, w config.WindowTemplate, key string) tea.Cmd {
+	return func() tea.Msg {
+		err := backend.RespawnWindow(env, w)
+		if err != nil {
+			log.Printf("respawnWindow: ERROR %s: %v", key, err)
+		}
+		return windowRespawnedMsg{key: key, err: err}
+	}
+}
+
+// sendKeysCmd types text into target literally, optionally followed by Enter.
+func sendKeysCmd(target, text string, enter bool) tea.Cmd {
+	return func() tea.Msg {
+		if text != "" {
+			if err := backend.SendKeys(target, true, text); err != nil {
+				return keysSentMsg{target: target, err: err}
+			}
+		}
+		if enter {
+			if err := backend.SendKeys(target, false, "Enter"); err != nil {
+				return keysSentMsg{target: target, err: err}
+			}
+		}
+		return keysSentMsg{target: target}
+	}
+}
+
+// sendAgentKeysCmd presses keys (tmux key names) in an agent's window to
+// answer its approval prompt; done is the status shown once they are sent.
+// The pane is captured again first: keys meant for a prompt that was
+// answered or taken down since the last sample would land in whatever the
+// agent shows now.
+func sendAgentKeysCmd(agents *agentstatus.Registry, agent agentTarget, target string, keys []string, done string) tea.Cmd {
+	return func() tea.Msg {
+		output, err := backend.CapturePane(agent.session, agent.window)
+		if err != nil {
+			return keysSentMsg{target: target, err: err}
+		}
+		if !agents.ShowsApproval(agent.tool, ansi.Strip(output)) {
+			return keysSentMsg{target: target, err: fmt.Errorf("%s no longer shows an approval prompt", target)}
+		}
+		if err := backend.SendKeys(target, false, keys...); err != nil {
+			return keysSentMsg{target: target, err: err}
+		}
+		return keysSentMsg{target: target, done: done}
+	}
+}
+
 func execAttachCmd(target string) tea.Cmd {
 	proc := exec.Command("tmux", "attach-session", "-t", target)
 	return tea.ExecProcess(proc, func(err error) tea.Msg {
@@ -547,12 +719,12 @@ func execAttachCmd(target string) tea.Cmd {
 
 func capturePaneCmd(session, window string) tea.Cmd {
 	return func() tea.Msg {
-		process := tmux.CurrentProcess(session, window)
-		cols, rows, err := tmux.PaneSize(session, window)
+		process := backend.CurrentProcess(session, window)
+		cols, rows, err := backend.PaneSize(session, window)
 		if err != nil || cols <= 0 || rows <= 0 {
 			return panePreviewMsg{session: session, window: window, process: process}
 		}
-		raw, err := tmux.CapturePane(session, window)
+		raw, err := backend.CapturePane(session, window)
 		if err != nil {
 			log.Printf("capturePane: %v", err)
 			return panePreviewMsg{session: session, window: window, process: process}
@@ -582,6 +754,7 @@ func (m Model) captureCurrentWindowCmd() tea.Cmd {
 	// makes a window eligible. The foreground command was captured by the
 	// previous loadSessionsCmd snapshot — read from the cache instead of
 	// spawning a tmux subprocess per window on every 500ms tick.
+	var agents []agentTarget
 	for _, e := range m.environments {
 		s := tmux.SessionName(e.Name)
 		if _, live := m.sessions[s]; !live {
@@ -589,21 +762,27 @@ func (m Model) captureCurrentWindowCmd() tea.Cmd {
 		}
 		ws := m.windowNamesForEnv(e)
 		for _, w := range ws {
+			if m.isPendingWindow(e, w) {
+				continue
+			}
 			cachedCmd := m.windowProcessInfo[windowKey(s, w)].Command
 			tmpl, hasTmpl := findWindowTemplate(e, w)
-			isAI := (hasTmpl && (HasTag(tmpl, "ai") || isAIToolProcess(tmpl.Cmd))) || isAIToolProcess(cachedCmd)
+			isAI := (hasTmpl && (HasTag(tmpl, "ai") || m.agents.IsAITool(tmpl.Cmd))) || m.agents.IsAITool(cachedCmd)
 			if isAI {
-				cmds = append(cmds, checkAgentStatusCmd(s, w, cachedCmd))
+				agents = append(agents, agentTarget{session: s, window: w, command: cachedCmd, tool: agentToolName(m.agents, tmpl, cachedCmd)})
 			}
 		}
 	}
+	if len(agents) > 0 {
+		cmds = append(cmds, checkAgentStatusesCmd(agents))
+	}
 
 	// Also capture the pane preview for the currently selected window
 	if env, ok := m.currentEnv(); ok {
 		session := tmux.SessionName(env.Name)
 		if _, live := m.sessions[session]; live {
 			windows := m.currentWindowNames()
-			if len(windows) > 0 && m.selectedWindow < len(windows) {
+			if len(windows) > 0 && m.selectedWindow < len(windows) && !m.isPendingWindow(env, windows[m.selectedWindow]) {
 				cmds = append(cmds, capturePaneCmd(session, windows[m.selectedWindow]))
 			}
 		}
@@ -615,26 +794,140 @@ func (m Model) captureCurrentWindowCmd() tea.Cmd {
 	return tea.Batch(cmds...)
 }
 
-// checkAgentStatusCmd creates a command to check agent status for a window.
-// The command (foreground process name) is supplied by the caller from the
-// cached snapshot rather than fetched here, to avoid an extra tmux subprocess
-// per window per tick.
-func checkAgentStatusCmd(session, window, command string) tea.Cmd {
+// agentTarget is an agent window to sample. The command (foreground
+// process name) comes from the cached snapshot rather than a tmux
+// subprocess per window per tick; tool picks the output patterns.
+type agentTarget struct {
+	session, window string
+	command, tool   string
+}
+
+// checkAgentStatusesCmd samples every agent window at once: one batched
+// process read for all of them (rather than a display-message and a
+// process-table read per window), then each pane's visible text for the
+// output patterns. The panes are captured concurrently, so a tick costs one
+// capture-pane round trip however many agents there are.
+func checkAgentStatusesCmd(targets []agentTarget) tea.Cmd {
 	return func() tea.Msg {
-		procInfo, err := tmux.GetPaneProcessInfo(session, window)
+		infos, err := backend.ProcessInfoByWindow()
 		if err != nil {
+			log.Printf("checkAgentStatuses: %v", err)
 			return nil
 		}
-		return agentStatusUpdateMsg{
-			session: session,
-			window:  window,
-			procInfo: ProcessInfo{
-				PID:       procInfo.PID,
-				CPU:       procInfo.CPU,
-				State:     procInfo.State,
-				Timestamp: time.Now(),
-			},
-			command: command,
+		now := time.Now()
+		updates := make([]*agentStatusUpdateMsg, len(targets))
+		var wg sync.WaitGroup
+		for i, t := range targets {
+			procInfo, ok := infos[t.session][tmux.SafeWindowName(t.window)]
+			if !ok {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				output, err := backend.CapturePane(t.session, t.window)
+				if err != nil {
+					output = ""
+				}
+				updates[i] = &agentStatusUpdateMsg{
+					report:  readAgentReport(t.session, t.window, procInfo.PID),
+					session: t.session,
+					window:  t.window,
+					procInfo: ProcessInfo{
+						PID:       procInfo.PID,
+						CPU:       procInfo.CPU,
+						State:     procInfo.State,
+						RSS:       procInfo.RSS,
+						Threads:   procInfo.Threads,
+						Timestamp: now,
+					},
+					command: t.command,
+					tool:    t.tool,
+					output:  ansi.Strip(output),
+				}
+			}()
+		}
+		wg.Wait()
+		var msg agentStatusesMsg
+		for _, u := range updates {
+			if u != nil {
+				msg.updates = append(msg.updates, *u)
+			}
 		}
+		return msg
+	}
+}
+
+// readAgentReport loads the window's last `ide agent report`; the zero
+// Report when there is none, or when it came from another pane than the
+// one whose process is panePID (a window of the same name in an earlier
+// session).
+func readAgentReport(session, window string, panePID int) agentstatus.Report {
+	var report agentstatus.Report
+	if err := state.ReadJSON(state.AgentReportName(windowKey(session, window)), &report); err != nil {
+		log.Printf("readAgentReport: %v", err)
+	}
+	if !report.From(panePID) {
+		return agentstatus.Report{}
+	}
+	return report
+}
+
+// agentToolName picks the agent whose pattern pack applies to a window: the
+// foreground process when it is a known agent, else the template command
+// (npm-installed agents often show up as "node").
+func agentToolName(agents *agentstatus.Registry, tmpl config.WindowTemplate, command string) string {
+	if tool := agents.Resolve(command); tool != "" {
+		return tool
+	}
+	return agents.Resolve(tmpl.Cmd)
+}
+
+// agentTimelinesLoadedMsg carries the recorded agent timelines, trimmed to
+// the last agentSparkSpan.
+type agentTimelinesLoadedMsg struct {
+	timelines map[string]agentstatus.Timeline
+}
+
+// loadAgentTimelinesCmd reads every recorded agent timeline, rewriting the
+// files that hold transitions older than agentstatus.TimelineRetention so
+// the history does not grow without bound.
+func loadAgentTimelinesCmd() tea.Cmd {
+	return func() tea.Msg {
+		keys, err := state.AgentTimelineKeys()
+		if err != nil {
+			log.Printf("loadAgentTimelines: %v", err)
+			return agentTimelinesLoadedMsg{}
+		}
+		now := time.Now()
+		out := make(map[string]agentstatus.Timeline, len(keys))
+		for _, key := range keys {
+			name := state.AgentTimelineName(key)
+			transitions, err := state.ReadJSONLines[agentstatus.Transition](name)
+			if err != nil {
+				log.Printf("loadAgentTimelines: %v", err)
+				continue
+			}
+			tl := agentstatus.NewTimeline(transitions)
+			if kept := tl.Since(now.Add(-agentstatus.TimelineRetention)); len(kept) < len(tl) {
+				if err := state.WriteJSONLines(name, kept); err != nil {
+					log.Printf("loadAgentTimelines: %v", err)
+				}
+			}
+			out[key] = tl.Since(now.Add(-agentSparkSpan))
+		}
+		return agentTimelinesLoadedMsg{timelines: out}
+	}
+}
+
+// recordTransitionCmd appends tr to the window's timeline in the state
+// directory. Appends of one window may run in any order; readers sort the
+// timeline (see agentstatus.NewTimeline).
+func recordTransitionCmd(key string, tr agentstatus.Transition) tea.Cmd {
+	return func() tea.Msg {
+		if err := state.AppendJSONLine(state.AgentTimelineName(key), tr); err != nil {
+			log.Printf("recordTransition %s: %v", key, err)
+		}
+		return nil
 	}
 }
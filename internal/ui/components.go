@@ -8,15 +8,20 @@ import (
 )
 
 // paneFocused reports whether the given pane is the active focus target.
-// Any open modal (create/template/envEdit/extract) suppresses pane focus so
-// pane titles render in their muted state.
+// Any open modal (create/template/envEdit/extract/send) suppresses pane
+// focus so pane titles render in their muted state.
 func (m Model) paneFocused(pane int) bool {
-	if m.createMode || m.templateMode || m.envEditMode || m.extractMode {
+	if m.modalOpen() {
 		return false
 	}
 	return m.focusPane == pane
 }
 
+// modalOpen reports whether one of the centered form modals is showing.
+func (m Model) modalOpen() bool {
+	return m.createMode || m.templateMode || m.envEditMode || m.extractMode || m.sendMode
+}
+
 // numPrefix returns "[N]" for the first 9 list entries (1-indexed) so the
 // number can be typed as a shortcut, or three spaces otherwise to keep
 // rows column-aligned.
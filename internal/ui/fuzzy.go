@@ -79,6 +79,12 @@ func (m *Model) rebuildFuzzyIndex() {
 				searchStr += " cooking"
 			case AgentStatusAwaitingInput:
 				searchStr += " awaiting input"
+			case AgentStatusApproval:
+				searchStr += " awaiting approval"
+			case AgentStatusError:
+				searchStr += " error"
+			case AgentStatusStalled:
+				searchStr += " stalled"
 			}
 
 			winEntries = append(winEntries, fuzzyWinCacheEntry{
@@ -90,6 +96,7 @@ func (m *Model) rebuildFuzzyIndex() {
 					Status:      status,
 					Tags:        tags,
 					Running:     running,
+					Lazy:        running && m.isPendingWindow(env, wName),
 				},
 				haystack: searchStr,
 			})
@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"testing"
 
+	"ide/internal/agentstatus"
 	"ide/internal/config"
 	"ide/internal/tmux"
 )
@@ -89,6 +90,7 @@ func TestMoveFuzzySearchCursorDoesNotLandOnHeader(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			m := &Model{
+				agents:             agentstatus.BuiltinRegistry(),
 				fuzzySearchResults: tc.results,
 				fuzzySearchCursor:  tc.startCur,
 			}
@@ -131,6 +133,7 @@ func TestRebuildFuzzyIndexAndCompute(t *testing.T) {
 	}
 
 	m := &Model{
+		agents:       agentstatus.BuiltinRegistry(),
 		environments: []config.Environment{envA, envB},
 		sessions: map[string]struct{}{
 			tmux.SessionName(envA.Name): {},
@@ -250,6 +253,7 @@ func TestFuzzySearchDoesNotSpanEnvWindowBoundary(t *testing.T) {
 	}
 
 	m := &Model{
+		agents:       agentstatus.BuiltinRegistry(),
 		environments: []config.Environment{platform, updateView},
 		sessions: map[string]struct{}{
 			tmux.SessionName(platform.Name):   {},
@@ -294,6 +298,7 @@ func TestFuzzySearchEnvNameMatchYieldsAllWindows(t *testing.T) {
 		},
 	}
 	m := &Model{
+		agents:       agentstatus.BuiltinRegistry(),
 		environments: []config.Environment{env},
 		sessions: map[string]struct{}{
 			tmux.SessionName(env.Name): {},
@@ -399,6 +404,7 @@ func TestOpenFuzzySearchCursorSkipsHeader(t *testing.T) {
 		Windows: []config.WindowTemplate{{Name: "shell"}},
 	}
 	m := &Model{
+		agents:       agentstatus.BuiltinRegistry(),
 		environments: []config.Environment{env},
 		sessions: map[string]struct{}{
 			tmux.SessionName(env.Name): {},
@@ -0,0 +1,275 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"ide/internal/agentstatus"
+	"ide/internal/config"
+	"ide/internal/state"
+	"ide/internal/tmux"
+)
+
+// inboxLoadedMsg carries the unread inbox entries a previous run saved.
+type inboxLoadedMsg struct {
+	entries []agentstatus.InboxEntry
+}
+
+func loadInboxCmd() tea.Cmd {
+	return func() tea.Msg {
+		var entries []agentstatus.InboxEntry
+		if err := state.ReadJSON(state.InboxName, &entries); err != nil {
+			log.Printf("loadInbox: %v", err)
+		}
+		return inboxLoadedMsg{entries: entries}
+	}
+}
+
+// inboxStore orders the inbox saves of one Model: they run concurrently,
+// and an older snapshot must not overwrite a newer one.
+type inboxStore struct {
+	mu    sync.Mutex
+	saved int // version of the last snapshot written
+}
+
+// saveInboxCmd writes the inbox to the state directory.
+func (m *Model) saveInboxCmd() tea.Cmd {
+	if m.inboxStore == nil {
+		m.inboxStore = &inboxStore{}
+	}
+	m.inboxVersion++
+	version, store := m.inboxVersion, m.inboxStore
+	entries := slices.Clone(m.inbox)
+	return func() tea.Msg {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		if version < store.saved {
+			return nil
+		}
+		store.saved = version
+		if err := state.WriteJSON(state.InboxName, entries); err != nil {
+			log.Printf("saveInbox: %v", err)
+		}
+		return nil
+	}
+}
+
+// mergeInbox adds the entries loaded at startup under the ones filed since,
+// which are newer.
+func (m *Model) mergeInbox(loaded []agentstatus.InboxEntry) tea.Cmd {
+	defer m.followInboxEntry(m.selectedInboxKey())
+	if len(m.inbox) == 0 {
+		m.inbox = loaded
+		return nil
+	}
+	var merged []agentstatus.InboxEntry
+	for _, e := range loaded {
+		if !slices.ContainsFunc(m.inbox, func(n agentstatus.InboxEntry) bool { return n.Key() == e.Key() }) {
+			merged = append(merged, e)
+		}
+	}
+	m.inbox = append(merged, m.inbox...)
+	return m.saveInboxCmd()
+}
+
+// addInboxEntry files what an agent left on screen when it finished
+// cooking. It replaces an unread entry of the same window: only the latest
+// turn is news.
+func (m *Model) addInboxEntry(session, window, tool, output string, at time.Time) tea.Cmd {
+	lines := m.agents.Summary(tool, output, agentstatus.InboxLines)
+	if len(lines) == 0 {
+		return nil
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+	defer m.followInboxEntry(m.selectedInboxKey())
+	key := windowKey(session, window)
+	m.inbox = slices.DeleteFunc(m.inbox, func(e agentstatus.InboxEntry) bool { return e.Key() == key })
+	m.inbox = append(m.inbox, agentstatus.InboxEntry{Session: session, Window: window, At: at, Lines: lines})
+	return m.saveInboxCmd()
+}
+
+// markInboxRead drops the entries of a window the user went to: selected
+// in the Windows pane, attached to, or jumped to from the inbox.
+func (m *Model) markInboxRead(session, window string) tea.Cmd {
+	key := windowKey(session, window)
+	return m.dropInboxEntries(func(e agentstatus.InboxEntry) bool { return e.Key() == key })
+}
+
+// markSelectedWindowRead is markInboxRead for the window under the cursor.
+func (m *Model) markSelectedWindowRead() tea.Cmd {
+	env, ok := m.currentEnv()
+	windows := m.currentWindowNames()
+	if !ok || m.selectedWindow < 0 || m.selectedWindow >= len(windows) {
+		return nil
+	}
+	return m.markInboxRead(tmux.SessionName(env.Name), windows[m.selectedWindow])
+}
+
+// pruneInbox drops the entries of sessions that are gone: there is no
+// window left to jump to.
+func (m *Model) pruneInbox() tea.Cmd {
+	return m.dropInboxEntries(func(e agentstatus.InboxEntry) bool {
+		_, running := m.sessions[e.Session]
+		return !running
+	})
+}
+
+// dropInboxEntries deletes the entries del matches and saves the inbox if
+// that changed it.
+func (m *Model) dropInboxEntries(del func(agentstatus.InboxEntry) bool) tea.Cmd {
+	defer m.followInboxEntry(m.selectedInboxKey())
+	n := len(m.inbox)
+	m.inbox = slices.DeleteFunc(m.inbox, del)
+	if len(m.inbox) == n {
+		return nil
+	}
+	return m.saveInboxCmd()
+}
+
+// selectedInboxKey is the key of the entry under the inbox cursor, "" when
+// the inbox is empty.
+func (m Model) selectedInboxKey() string {
+	entries := m.inboxNewestFirst()
+	if m.inboxCursor < 0 || m.inboxCursor >= len(entries) {
+		return ""
+	}
+	return entries[m.inboxCursor].Key()
+}
+
+// followInboxEntry puts the cursor back on the entry with key after the
+// inbox changed under it, so an agent finishing while the inbox is open
+// does not move what Enter jumps to. When that entry is gone the cursor
+// stays where it was, within bounds.
+func (m *Model) followInboxEntry(key string) {
+	if i := slices.IndexFunc(m.inboxNewestFirst(), func(e agentstatus.InboxEntry) bool { return e.Key() == key }); i >= 0 {
+		m.inboxCursor = i
+		return
+	}
+	m.inboxCursor = clampIndex(m.inboxCursor, len(m.inbox))
+}
+
+// inboxNewestFirst is the inbox in display order.
+func (m Model) inboxNewestFirst() []agentstatus.InboxEntry {
+	out := slices.Clone(m.inbox)
+	slices.Reverse(out)
+	return out
+}
+
+// inboxLabel names an entry's agent as "env/window".
+func (m Model) inboxLabel(e agentstatus.InboxEntry) string {
+	name := e.Session
+	if env, ok := m.envForSession(e.Session); ok {
+		name = env.Name
+	}
+	return name + "/" + e.Window
+}
+
+func (m *Model) openInbox() {
+	m.showInbox = true
+	m.showShortcuts = false
+	m.showThemePicker = false
+	m.terminalMode = false
+	m.inboxCursor = 0
+	if len(m.inbox) == 0 {
+		m.status = "Inbox empty: no agent finished since you last looked."
+		return
+	}
+	m.status = fmt.Sprintf("Inbox: %d unread. Enter jumps to the agent.", len(m.inbox))
+}
+
+func (m Model) updateInboxMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "i":
+		m.showInbox = false
+		m.status = "Inbox closed."
+		return m, nil
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "up", "k":
+		m.inboxCursor = clampSelection(m.inboxCursor, -1, len(m.inbox))
+		return m, nil
+	case "down", "j":
+		m.inboxCursor = clampSelection(m.inboxCursor, 1, len(m.inbox))
+		return m, nil
+	case "enter":
+		entries := m.inboxNewestFirst()
+		if m.inboxCursor < 0 || m.inboxCursor >= len(entries) {
+			return m, nil
+		}
+		return m.jumpToInboxEntry(entries[m.inboxCursor])
+	}
+	return m, nil
+}
+
+// jumpToInboxEntry marks the entry read and selects its window.
+func (m Model) jumpToInboxEntry(e agentstatus.InboxEntry) (tea.Model, tea.Cmd) {
+	m.showInbox = false
+	save := m.markInboxRead(e.Session, e.Window)
+	envIdx := slices.IndexFunc(m.environments, func(env config.Environment) bool { return tmux.SessionName(env.Name) == e.Session })
+	if _, running := m.sessions[e.Session]; envIdx < 0 || !running {
+		m.status = m.inboxLabel(e) + " is no longer running."
+		return m, save
+	}
+	m.selectedEnv = envIdx
+	m.selectedWindow = 0
+	for i, w := range m.currentWindowNames() {
+		if w == e.Window {
+			m.selectedWindow = i
+			break
+		}
+	}
+	m.focusPane = focusPaneWindows
+	m.status = "Jumped to " + m.inboxLabel(e)
+	return m, tea.Batch(save, m.captureCurrentWindowCmd())
+}
+
+func (m Model) renderInboxPane(width, height int) string {
+	theme := m.currentTheme()
+	contentWidth := modalContentWidth(width)
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Muted)).
+		Background(lipgloss.Color(theme.PaneBG))
+	borderTitle := "[i]-Inbox"
+
+	entries := m.inboxNewestFirst()
+	if len(entries) == 0 {
+		body := []string{"", "  No unread agent summaries.", "", "  An agent's last screen lands here when it finishes cooking."}
+		return renderModalWithBorderTitle(width, height, borderTitle, strings.Join(body, "\n"))
+	}
+
+	// The list takes up to a third of the popup; the selected entry's
+	// summary fills the rest.
+	visible := height - 4
+	listHeight := min(len(entries), max(visible/3, 1))
+	now := time.Now()
+	var list []string
+	for idx, e := range entries {
+		line := fmt.Sprintf("%s · %s ago", m.inboxLabel(e), agentstatus.FormatDuration(now.Sub(e.At)))
+		if idx == m.inboxCursor {
+			list = append(list, renderStyledPaneLine(selectedLineStyle, "▸ "+line, contentWidth))
+		} else {
+			list = append(list, padLineToWidth("  "+line, contentWidth))
+		}
+	}
+	rows := viewportSlice(list, m.inboxCursor, listHeight)
+	rows = append(rows, mutedStyle.Render(fitLineToWidth(strings.Repeat("─", contentWidth), contentWidth)))
+
+	lines := entries[m.inboxCursor].Lines
+	if room := visible - len(rows) - 1; len(lines) > room {
+		lines = lines[len(lines)-max(room, 0):]
+	}
+	for _, line := range lines {
+		rows = append(rows, fitLineToWidth("  "+line, contentWidth))
+	}
+	rows = append(rows, "", "Enter jumps to the agent, Esc closes")
+	return renderModalWithBorderTitle(width, height, borderTitle, strings.Join(rows, "\n"))
+}
@@ -10,7 +10,10 @@ import (
 
 	"ide/internal/agentstatus"
 	"ide/internal/config"
+	"ide/internal/notify"
+	"ide/internal/supervisor"
 	"ide/internal/theme"
+	"ide/internal/tmux"
 )
 
 var (
@@ -136,12 +139,16 @@ type (
 	AgentStatus       = agentstatus.Status
 	ProcessInfo       = agentstatus.ProcessInfo
 	WindowProcessInfo = agentstatus.WindowInfo
+	AgentSample       = agentstatus.Sample
 )
 
 const (
 	AgentStatusIdle          = agentstatus.StatusIdle
 	AgentStatusCooking       = agentstatus.StatusCooking
 	AgentStatusAwaitingInput = agentstatus.StatusAwaitingInput
+	AgentStatusApproval      = agentstatus.StatusAwaitingApproval
+	AgentStatusError         = agentstatus.StatusError
+	AgentStatusStalled       = agentstatus.StatusStalled
 )
 
 // fuzzySearchItem represents a single item in the fuzzy search results.
@@ -155,6 +162,7 @@ type fuzzySearchItem struct {
 	Tags        []string
 	Running     bool
 	IsHeader    bool
+	Lazy        bool // lazy window not created yet, rendered dimmed
 }
 
 // fuzzyWinCacheEntry is a precomputed window entry for fuzzy search.
@@ -216,6 +224,9 @@ type Model struct {
 	extractMode           bool
 	extractTarget         string
 	extractName           textinput.Model
+	sendMode              bool
+	sendTarget            string // tmux target ("session:window") the send prompt types into
+	sendInput             textinput.Model
 	restartConfirm        string
 	showShortcuts         bool
 	shortcutCursor        int
@@ -223,8 +234,9 @@ type Model struct {
 	themeQuery            textinput.Model
 	themePickerCursor     int
 	confirmMode           bool
-	confirmKind           string // "env_delete" | "session_kill" | "template_delete" | "create_folder"
+	confirmKind           string // "env_delete" | "session_kill" | "orphan_kill" | "template_delete" | "create_folder"
 	confirmTarget         string
+	confirmProcesses      []tmux.PaneProcess // session_kill: still running in the session; orphan_kill: survivors
 	pendingCreateName     string
 	pendingCreateRoot     string
 	pendingCreateWindows  []config.WindowTemplate
@@ -234,11 +246,24 @@ type Model struct {
 	themeIndex            int
 	status                string
 	statusKind            statusKind // categorises the status string for display logic
+	tmuxWarning           string     // startup message about a missing or too-old tmux; "" when fine
 	previewContent        string
 	previewSession        string
 	previewWindow         string
 	previewProcess        string
-	windowProcessInfo     map[string]WindowProcessInfo // key: session:window
+	windowProcessInfo     map[string]WindowProcessInfo    // key: session:window
+	windowExits           map[string]int                  // key: session:window; exit code of startup commands that have exited
+	windowRestarts        map[string]supervisor.State     // key: session:window; restart-policy bookkeeping
+	skippedWindows        map[string]map[string]string    // env name -> window -> why its when condition fails
+	notifier              notify.Notifier                 // nil until the config is loaded
+	agents                *agentstatus.Registry           // agent CLIs and thresholds; the built-in ones until the config is loaded
+	escapes               *escapeBuffer                   // bell and OSC output of notifier, see terminalEscapeMsg
+	agentTimelines        map[string]agentstatus.Timeline // key: session:window; the last agentSparkSpan
+	inbox                 []agentstatus.InboxEntry        // unread agent summaries, oldest first
+	inboxVersion          int                             // bumped by every saveInboxCmd
+	inboxStore            *inboxStore
+	showInbox             bool
+	inboxCursor           int // into inboxNewestFirst
 	showFuzzySearch       bool
 	fuzzySearchQuery      textinput.Model
 	fuzzySearchCursor     int
@@ -263,6 +288,12 @@ func NewModel() Model {
 		sessions:          map[string]struct{}{},
 		sessionWindows:    map[string][]string{},
 		windowProcessInfo: map[string]WindowProcessInfo{},
+		agentTimelines:    map[string]agentstatus.Timeline{},
+		agents:            agentstatus.BuiltinRegistry(),
+		inboxStore:        &inboxStore{},
+		escapes:           &escapeBuffer{},
+		windowExits:       map[string]int{},
+		windowRestarts:    map[string]supervisor.State{},
 		focusPane:         focusPaneEnvironments,
 		themes:            defaultThemes(),
 		status:            "Loading environments...",
@@ -282,6 +313,7 @@ func NewModel() Model {
 	m.templateSpec = newTextInput("Windows: ", "")
 	m.envEditSpec = newTextInput("Windows: ", "")
 	m.extractName = newTextInput("Name: ", "")
+	m.sendInput = newTextInput("> ", "text to type, Enter sends")
 	m.themeQuery = newTextInput("", "")
 	m.fuzzySearchQuery = newTextInput("/ ", "Search sessions and windows...")
 	m.applyCurrentTheme()
@@ -289,7 +321,7 @@ func NewModel() Model {
 }
 
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(loadConfigCmd(), loadSessionsCmd(), tea.Tick(500*time.Millisecond, func(time.Time) tea.Msg {
+	return tea.Batch(loadConfigCmd(), loadSessionsCmd(), detectTmuxCmd(), loadAgentTimelinesCmd(), loadInboxCmd(), tea.Tick(500*time.Millisecond, func(time.Time) tea.Msg {
 		return previewTickMsg{}
 	}))
 }
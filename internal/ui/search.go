@@ -2,14 +2,18 @@ package ui
 
 import (
 	"fmt"
+	"log"
 	"os/exec"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
 
+	"ide/internal/agentstatus"
 	"ide/internal/config"
 	"ide/internal/tmux"
 )
@@ -23,6 +27,7 @@ type searchItem struct {
 	tags    []string
 	running bool
 	header  bool
+	lazy    bool // lazy window not created yet; switching to it creates it
 	status  AgentStatus
 }
 
@@ -37,6 +42,8 @@ type SearchModel struct {
 	sessions       map[string]struct{}
 	sessionWindows map[string][]string
 	statuses       map[string]AgentStatus
+	skipped        map[string]map[string]string // env name -> window -> failed when condition
+	agents         *agentstatus.Registry
 	theme          uiTheme
 	// scopeSession, when non-empty, restricts results to the windows of a
 	// single tmux session (e.g. the one the popup was launched from) and
@@ -50,8 +57,10 @@ type searchSessionsLoadedMsg struct {
 }
 
 type searchConfigLoadedMsg struct {
-	envs  []config.Environment
-	theme string
+	envs    []config.Environment
+	theme   string
+	skipped map[string]map[string]string
+	agents  *agentstatus.Registry // nil when the "agents" section is broken
 }
 
 type searchStatusLoadedMsg struct {
@@ -70,6 +79,7 @@ func NewSearchModel() SearchModel {
 		sessions:       map[string]struct{}{},
 		sessionWindows: map[string][]string{},
 		statuses:       map[string]AgentStatus{},
+		agents:         agentstatus.BuiltinRegistry(),
 		theme:          themes[0],
 	}
 	return m
@@ -98,16 +108,16 @@ func (m SearchModel) loadConfig() tea.Cmd {
 		if err != nil {
 			return searchConfigLoadedMsg{}
 		}
-		return searchConfigLoadedMsg{envs: data.Environments, theme: data.Theme}
+		return searchConfigLoadedMsg{envs: data.Environments, theme: data.Theme, skipped: skippedWindows(data.Environments), agents: agentRegistry(data.Agents)}
 	}
 }
 
 func (m SearchModel) loadSessions() tea.Cmd {
 	return func() tea.Msg {
-		names, _ := tmux.ListSessions()
+		names, _ := backend.ListSessions()
 		windows := map[string][]string{}
 		for _, s := range names {
-			w, err := tmux.ListWindows(s)
+			w, err := backend.ListWindows(s)
 			if err == nil {
 				windows[s] = w
 			}
@@ -123,14 +133,19 @@ func (m SearchModel) loadSessions() tea.Cmd {
 //   - CPU > 5.0         → Cooking
 //   - otherwise         → AwaitingInput (the agent CLI is alive in an [ai] pane)
 //
-// If GetPaneProcessInfo errors (no live session, etc.) the window is omitted
+// If ProcessInfoByWindow has no entry (no live window, etc.) the window is omitted
 // from the result map and renders as Idle (no suffix).
 func (m SearchModel) loadStatuses() tea.Cmd {
 	envs := m.envs
 	sessions := m.sessions
 	sessionWindows := m.sessionWindows
+	agents := m.agents
 	return func() tea.Msg {
 		out := map[string]AgentStatus{}
+		// Read once, at the first agent window: the pane PIDs vet the
+		// agents' reports, and the CPU is the fallback.
+		var infos map[string]map[string]tmux.ProcessInfo
+		var err error
 		for _, env := range envs {
 			session := tmux.SessionName(env.Name)
 			if _, running := sessions[session]; !running {
@@ -142,16 +157,35 @@ func (m SearchModel) loadStatuses() tea.Cmd {
 			}
 			for _, wName := range windows {
 				tmpl, hasTmpl := findWindowTemplate(env, wName)
-				hasAI := hasTmpl && (HasTag(tmpl, "ai") || isAIToolProcess(tmpl.Cmd))
+				hasAI := hasTmpl && (HasTag(tmpl, "ai") || agents.IsAITool(tmpl.Cmd))
+				current := ""
 				if !hasAI {
-					if !isAIToolProcess(tmux.CurrentProcess(session, wName)) {
+					current = backend.CurrentProcess(session, wName)
+					if !agents.IsAITool(current) {
 						continue
 					}
 				}
-				info, err := tmux.GetPaneProcessInfo(session, wName)
-				if err != nil {
+				if infos == nil {
+					if infos, err = backend.ProcessInfoByWindow(); err != nil {
+						infos = map[string]map[string]tmux.ProcessInfo{}
+					}
+				}
+				info, ok := infos[session][tmux.SafeWindowName(wName)]
+				if !ok {
+					continue
+				}
+				// A single sample: the agent's own report, then the screen,
+				// with the CPU heuristic as the fallback.
+				if report := readAgentReport(session, wName, info.PID); report.Active(time.Now()) {
+					out[windowKey(session, wName)] = report.Status
 					continue
 				}
+				if output, err := backend.CapturePane(session, wName); err == nil {
+					if status, ok := agents.MatchOutput(agentToolName(agents, tmpl, current), ansi.Strip(output)); ok {
+						out[windowKey(session, wName)] = status
+						continue
+					}
+				}
 				status := AgentStatusAwaitingInput
 				if info.State == "R" || info.CPU > 5.0 {
 					status = AgentStatusCooking
@@ -172,6 +206,10 @@ func (m SearchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case searchConfigLoadedMsg:
 		m.envs = msg.envs
+		m.skipped = msg.skipped
+		if msg.agents != nil {
+			m.agents = msg.agents
+		}
 		// Apply theme
 		for _, t := range defaultThemes() {
 			if strings.EqualFold(t.Name, msg.theme) {
@@ -241,6 +279,13 @@ func (m SearchModel) switchTo(item searchItem) {
 	session := tmux.SessionName(item.env)
 	target := session + ":" + item.window
 
+	if item.lazy && item.envIdx >= 0 && item.envIdx < len(m.envs) {
+		if err := backend.EnsureWindow(m.envs[item.envIdx], item.window); err != nil {
+			log.Printf("search: %v", err)
+			return
+		}
+	}
+
 	// Check if we're already in the target session
 	current := currentTmuxSession()
 	if current == session {
@@ -278,8 +323,9 @@ func (m SearchModel) computeResults() []searchItem {
 		}
 
 		windows := tmux.WindowNames(env)
-		if sw, ok := m.sessionWindows[session]; ok && len(sw) > 0 {
-			windows = sw
+		live := m.sessionWindows[session]
+		if len(live) > 0 {
+			windows = tmux.WithLazyWindows(tmux.WithoutWindows(env, m.skipped[env.Name]), live)
 		}
 
 		// Env-name matching is kept separate from window matching so a query
@@ -324,6 +370,7 @@ func (m SearchModel) computeResults() []searchItem {
 				window:  wName,
 				tags:    tags,
 				running: running,
+				lazy:    tmux.IsLazyWindow(env, wName) && !slices.Contains(live, wName),
 				status:  status,
 			}
 			if query != "" && fuzzyMatch(query, strings.TrimSpace(aliasHaystack)) {
@@ -472,17 +519,10 @@ func (m SearchModel) View() string {
 			// Status indicator (rendered between window name and tags).
 			statusPlain := ""
 			statusStyled := ""
-			switch item.status {
-			case AgentStatusCooking:
-				statusPlain = " ●"
+			if glyph := agentStatusGlyph(item.status); glyph != "" {
+				statusPlain = " " + glyph
 				statusStyled = lipgloss.NewStyle().
-					Foreground(lipgloss.Color("#fbbf24")).
-					Bold(true).
-					Render(statusPlain)
-			case AgentStatusAwaitingInput:
-				statusPlain = " ◆"
-				statusStyled = lipgloss.NewStyle().
-					Foreground(lipgloss.Color("#22d3ee")).
+					Foreground(lipgloss.Color(agentStatusColor(item.status))).
 					Bold(true).
 					Render(statusPlain)
 			}
@@ -494,6 +534,9 @@ func (m SearchModel) View() string {
 			}
 
 			name := item.window + statusStyled + tagStr
+			if item.lazy {
+				name = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Inactive)).Render(item.window) + tagStr
+			}
 
 			if i == m.cursor {
 				plain := item.window + statusPlain
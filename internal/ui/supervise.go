@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"ide/internal/supervisor"
+	"ide/internal/tmux"
+)
+
+// superviseWindows applies each window's restart policy to the exits in the
+// latest session snapshot (taken at seenAt) and returns the restarts that
+// are due now. Windows without a policy are left alone.
+func (m *Model) superviseWindows(seenAt, now time.Time) []tea.Cmd {
+	var cmds []tea.Cmd
+	for _, env := range m.environments {
+		session := tmux.SessionName(env.Name)
+		for _, w := range m.sessionWindows[session] {
+			tmpl, ok := findWindowTemplate(env, w)
+			if !ok {
+				continue
+			}
+			policy, err := supervisor.PolicyFor(tmpl)
+			if err != nil || !policy.Enabled() {
+				continue
+			}
+			key := windowKey(session, w)
+			st := m.windowRestarts[key]
+			code, exited := m.windowExits[key]
+			if !exited {
+				st.Running()
+			} else if policy.Observe(&st, code, seenAt, now) {
+				m.status = fmt.Sprintf("Restarting %s after %s...", key, tmux.ExitLabel(code))
+				cmds = append(cmds, respawnWindowCmd(env, tmpl, key))
+			}
+			m.windowRestarts[key] = st
+		}
+	}
+	return cmds
+}
+
+// restartSummary renders a window's supervision record for the Windows
+// pane, e.g. "2 · last failure 14:02:05 (code 1) · next in 4s". Empty when
+// there is nothing worth showing.
+func restartSummary(st supervisor.State, now time.Time) string {
+	if st.Restarts == 0 && st.LastFailure.IsZero() && st.Due.IsZero() && !st.GaveUp {
+		return ""
+	}
+	parts := []string{fmt.Sprintf("%d", st.Restarts)}
+	if !st.LastFailure.IsZero() {
+		parts = append(parts, fmt.Sprintf("last failure %s (code %d)", st.LastFailure.Format("15:04:05"), st.LastCode))
+	}
+	switch {
+	case st.InFlight:
+		parts = append(parts, "restarting")
+	case !st.Due.IsZero():
+		parts = append(parts, "next in "+st.Due.Sub(now).Round(time.Second).String())
+	case st.GaveUp:
+		parts = append(parts, "gave up")
+	}
+	return strings.Join(parts, " · ")
+}
@@ -39,7 +39,8 @@ type ptyEOFMsg struct{ err error }
 // terminalSessionReadyMsg signals that a session has been ensured and
 // the terminal mode can now be activated.
 type terminalSessionReadyMsg struct {
-	err error
+	window string // the window to open, created if it was lazy
+	err    error
 }
 
 func newEmbeddedTerminal(cols, rows int) *EmbeddedTerminal {
@@ -241,16 +242,20 @@ func readPTYCmd(et *EmbeddedTerminal) tea.Cmd {
 	}
 }
 
-// ensureSessionForTerminalCmd ensures a tmux session exists, then signals readiness.
-func ensureSessionForTerminalCmd(env config.Environment) tea.Cmd {
+// ensureSessionForTerminalCmd ensures a tmux session and window exist, then
+// signals readiness.
+func ensureSessionForTerminalCmd(env config.Environment, window string) tea.Cmd {
 	return func() tea.Msg {
-		if err := tmux.CheckTmuxExists(); err != nil {
+		if err := backend.CheckTmuxExists(); err != nil {
 			return terminalSessionReadyMsg{err: err}
 		}
-		if err := tmux.EnsureSession(env); err != nil {
+		if err := backend.EnsureSession(env); err != nil {
 			return terminalSessionReadyMsg{err: err}
 		}
-		return terminalSessionReadyMsg{}
+		if err := backend.EnsureWindow(env, window); err != nil {
+			return terminalSessionReadyMsg{err: err}
+		}
+		return terminalSessionReadyMsg{window: window}
 	}
 }
 
@@ -262,17 +267,31 @@ func (m Model) enterTerminalMode() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 	session := tmux.SessionName(env.Name)
+	windows := m.currentWindowNames()
+	window := ""
+	if len(windows) > 0 && m.selectedWindow < len(windows) {
+		window = windows[m.selectedWindow]
+	}
 	if _, live := m.sessions[session]; !live {
 		m.status = "Starting session..."
-		return m, ensureSessionForTerminalCmd(env)
+		return m, ensureSessionForTerminalCmd(env, window)
 	}
-	windows := m.currentWindowNames()
-	if len(windows) == 0 || m.selectedWindow >= len(windows) {
+	if window == "" {
 		m.status = "No window available."
 		return m, nil
 	}
-	window := windows[m.selectedWindow]
+	if m.isPendingWindow(env, window) {
+		m.status = "Starting window..."
+		return m, ensureSessionForTerminalCmd(env, window)
+	}
+	read := m.markInboxRead(session, window)
+	model, cmd := m.openEmbeddedTerminal(session, window)
+	return model, tea.Batch(read, cmd)
+}
 
+// openEmbeddedTerminal attaches the embedded terminal to a window that is
+// known to exist.
+func (m Model) openEmbeddedTerminal(session, window string) (tea.Model, tea.Cmd) {
 	_, rightWidth := splitPaneWidths(m.width - 1)
 	et := newEmbeddedTerminal(paneContentWidth(rightWidth), layout.TerminalPreviewHeight(m.height))
 	if err := et.Attach(session, window); err != nil {
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -12,6 +13,7 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 
+	"ide/internal/agentstatus"
 	"ide/internal/config"
 	"ide/internal/layout"
 	"ide/internal/tmux"
@@ -95,6 +97,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateShortcutsMode(msg)
 		}
 
+		if m.showInbox {
+			return m.updateInboxMode(msg)
+		}
+
 		if m.confirmMode {
 			return m.updateConfirmMode(msg)
 		}
@@ -110,6 +116,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.extractMode {
 			return m.updateExtractMode(msg)
 		}
+		if m.sendMode {
+			return m.updateSendMode(msg)
+		}
 
 		if key != "r" {
 			m.restartConfirm = ""
@@ -174,6 +183,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "N":
 			m.jumpToNextCookingSession(-1)
 			return m, m.captureCurrentWindowCmd()
+		case "i":
+			m.openInbox()
+			return m, nil
 		}
 
 		if m.focusPane == focusPaneEnvironments {
@@ -187,6 +199,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m.updateTemplatesPanelKey(key)
 
+	case terminalEscapeMsg:
+		if _, err := io.WriteString(terminalOut, msg.seq); err != nil {
+			log.Printf("notify: write to terminal: %v", err)
+		}
+		return m, nil
+
 	case configLoadedMsg:
 		if msg.err != nil {
 			m.status = "Config error: " + msg.err.Error()
@@ -194,6 +212,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.environments = msg.envs
 		m.templates = msg.templates
+		m.skippedWindows = msg.skipped
+		if msg.agents != nil {
+			m.agents = msg.agents
+		}
+		if n, err := newNotifier(msg.notify, m.escapes); err != nil {
+			log.Printf("notify: %v", err)
+			m.notifier = nil
+		} else {
+			m.notifier = n
+		}
 		m.rebuildFuzzyIndex()
 		if idx, ok := m.themeIndexByName(msg.theme); ok {
 			if idx != m.themeIndex {
@@ -222,7 +250,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.pendingTemplateSelect = ""
 		}
 		m.normalizeSelection()
-		if len(m.environments) == 0 {
+		switch {
+		case m.tmuxWarning != "" && m.status == m.tmuxWarning:
+			// The tmux warning outranks the usual load hints.
+		case len(m.environments) == 0:
 			path, err := config.ConfigFilePath()
 			if err != nil {
 				m.status = "No environments configured."
@@ -230,15 +261,27 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.status = "No environments configured in " + path
 			}
 			m.statusKind = statusKindEmpty
-		} else if m.statusKind.replaceableOnLoad() ||
+		case m.statusKind.replaceableOnLoad() ||
 			strings.HasPrefix(m.status, "Loading") ||
 			strings.HasPrefix(m.status, "Refreshing") ||
-			strings.HasPrefix(m.status, "No environments configured") {
+			strings.HasPrefix(m.status, "No environments configured"):
 			m.status = "Ready. Enter attaches, Ctrl-b d detaches back here."
 			m.statusKind = statusKindReady
 		}
 		return m, nil
 
+	case tmuxCapabilitiesMsg:
+		if msg.err != nil {
+			m.tmuxWarning = msg.err.Error()
+		} else {
+			m.tmuxWarning = msg.caps.Warning()
+		}
+		if m.tmuxWarning != "" {
+			m.status = m.tmuxWarning
+			m.statusKind = statusKindGeneric
+		}
+		return m, nil
+
 	case sessionsLoadedMsg:
 		if msg.err != nil {
 			m.status = "Tmux error: " + msg.err.Error()
@@ -258,11 +301,22 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				liveKeys[windowKey(session, w)] = struct{}{}
 			}
 		}
+		cleanup := []tea.Cmd{m.pruneInbox()}
+		for k := range m.agentTimelines {
+			if _, ok := liveKeys[k]; !ok {
+				cleanup = append(cleanup, m.closeAgentTimeline(k))
+			}
+		}
 		for k := range m.windowProcessInfo {
 			if _, ok := liveKeys[k]; !ok {
 				delete(m.windowProcessInfo, k)
 			}
 		}
+		for k := range m.windowRestarts {
+			if _, ok := liveKeys[k]; !ok {
+				delete(m.windowRestarts, k)
+			}
+		}
 		// Cache the foreground command per window from the snapshot. This
 		// keeps captureCurrentWindowCmd off the tmux subprocess hot path —
 		// it can now read commands from windowProcessInfo instead of running
@@ -275,9 +329,28 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.windowProcessInfo[key] = info
 			}
 		}
+		m.windowExits = map[string]int{}
+		for session, byWindow := range msg.exits {
+			for w, code := range byWindow {
+				m.windowExits[windowKey(session, w)] = code
+			}
+		}
+		restarts := m.superviseWindows(msg.at, time.Now())
 		m.rebuildFuzzyIndex()
 		m.normalizeSelection()
-		return m, m.captureCurrentWindowCmd()
+		cmds := append(cleanup, restarts...)
+		return m, tea.Batch(append(cmds, m.captureCurrentWindowCmd())...)
+
+	case windowRespawnedMsg:
+		st := m.windowRestarts[msg.key]
+		st.Started(time.Now(), msg.err)
+		m.windowRestarts[msg.key] = st
+		if msg.err != nil {
+			m.status = "Restart failed: " + msg.err.Error()
+			return m, nil
+		}
+		m.status = fmt.Sprintf("Restarted %s (restart #%d).", msg.key, st.Restarts)
+		return m, loadSessionsCmd()
 
 	case panePreviewMsg:
 		m.previewContent = msg.content
@@ -286,16 +359,43 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.previewProcess = msg.process
 		return m, nil
 
-	case agentStatusUpdateMsg:
-		log.Printf("[Update] Received agentStatusUpdateMsg for session=%s window=%s", msg.session, msg.window)
-		// Update the window process info based on the message
-		m.updateWindowProcessInfoFromMsg(msg.session, msg.window, msg.procInfo, msg.command)
+	case inboxLoadedMsg:
+		return m, m.mergeInbox(msg.entries)
+
+	case agentTimelinesLoadedMsg:
+		now := time.Now()
+		for key, loaded := range msg.timelines {
+			if len(loaded) == 0 {
+				continue
+			}
+			// Transitions seen since startup come after the recorded ones.
+			var recent agentstatus.Timeline
+			last := loaded[len(loaded)-1].At
+			for _, tr := range m.agentTimelines[key] {
+				if tr.At.After(last) {
+					recent = append(recent, tr)
+				}
+			}
+			m.agentTimelines[key] = append(loaded, recent...).Since(now.Add(-agentSparkSpan))
+		}
+		return m, nil
+
+	case agentStatusesMsg:
+		log.Printf("[Update] Received agentStatusesMsg for %d windows", len(msg.updates))
+		// Update the window process info of every sampled window, then
+		// rebuild what depends on it once.
+		var cmds []tea.Cmd
+		for _, u := range msg.updates {
+			cmds = append(cmds, m.updateWindowProcessInfoFromMsg(u.session, u.window, AgentSample{
+				Process: u.procInfo, Tool: u.tool, Output: u.output, Report: u.report,
+			}, u.command))
+		}
 		m.rebuildFuzzyIndex()
 		// Refresh search results so status changes appear live
 		if m.showFuzzySearch {
 			m.fuzzySearchResults = m.computeFuzzySearchResults()
 		}
-		return m, nil
+		return m, tea.Batch(cmds...)
 
 	case ptyReadMsg:
 		// New PTY output was processed into the VT emulator; keep reading
@@ -331,11 +431,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// sessionWindows will populate asynchronously via the loadSessionsCmd
 		// batched below — avoids a synchronous tmux subprocess call in Update.
 		m.sessions[session] = struct{}{}
+		m.markWindowCreated(env, msg.window)
 		// The fuzzy cache snapshots `Running` per env; without this rebuild
 		// it would still report the freshly-started session as not-running
 		// until the next 500ms loadSessionsCmd tick.
 		m.rebuildFuzzyIndex()
-		model, enterCmd := m.enterTerminalMode()
+		var model tea.Model
+		var enterCmd tea.Cmd
+		if msg.window != "" {
+			model, enterCmd = m.openEmbeddedTerminal(session, msg.window)
+		} else {
+			model, enterCmd = m.enterTerminalMode()
+		}
 		return model, tea.Batch(enterCmd, loadSessionsCmd())
 
 	case previewTickMsg:
@@ -456,7 +563,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, loadSessionsCmd()
 		}
 		m.status = "Restarted session: " + msg.session
-		return m, loadSessionsCmd()
+		if msg.orphans > 0 {
+			m.status += fmt.Sprintf(" (%d old processes survived; see ide env gc)", msg.orphans)
+		}
+		// The rebuild re-ran the when.shell predicates; reload to pick up
+		// their outcome.
+		return m, tea.Batch(loadConfigCmd(), loadSessionsCmd())
 
 	case templateDeletedMsg:
 		if msg.err != nil {
@@ -466,20 +578,62 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.status = "Template deleted: " + msg.name
 		return m, loadConfigCmd()
 
+	case keysSentMsg:
+		if msg.err != nil {
+			m.status = "Send failed: " + msg.err.Error()
+			return m, nil
+		}
+		m.status = "Sent to " + msg.target
+		if msg.done != "" {
+			m.status = msg.done
+		}
+		return m, m.captureCurrentWindowCmd()
+
+	case sessionProcessesMsg:
+		if msg.err != nil {
+			log.Printf("sessionProcesses: %s: %v", msg.session, msg.err)
+		}
+		m.confirmMode = true
+		m.confirmKind = "session_kill"
+		m.confirmTarget = msg.session
+		m.confirmProcesses = msg.procs
+		m.status = ""
+		return m, nil
+
 	case sessionKilledMsg:
 		if msg.err != nil {
 			m.status = "Kill failed: " + msg.err.Error()
 			return m, nil
 		}
-		m.status = "Killed session: " + msg.session
+		if msg.forced > 0 {
+			m.status = fmt.Sprintf("Killed session: %s (%d processes did not stop in time)", msg.session, msg.forced)
+		} else {
+			m.status = "Killed session: " + msg.session
+		}
+		if len(msg.orphans) > 0 {
+			m.confirmMode = true
+			m.confirmKind = "orphan_kill"
+			m.confirmTarget = msg.session
+			m.confirmProcesses = msg.orphans
+		}
 		return m, loadSessionsCmd()
 
+	case orphansTerminatedMsg:
+		if msg.err != nil {
+			m.status = "Terminate failed: " + msg.err.Error()
+			return m, nil
+		}
+		m.status = "Terminated leftover processes of " + msg.session
+		return m, nil
+
 	case environmentDeletedMsg:
 		if msg.err != nil {
 			m.status = "Delete failed: " + msg.err.Error()
 			return m, nil
 		}
-		if msg.killed {
+		if msg.killed && msg.orphans > 0 {
+			m.status = fmt.Sprintf("Deleted environment %s and killed session %s (%d processes survived; see ide env gc)", msg.environment, msg.session, msg.orphans)
+		} else if msg.killed {
 			m.status = "Deleted environment " + msg.environment + " and killed session " + msg.session
 		} else {
 			m.status = "Deleted environment " + msg.environment
@@ -488,7 +642,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	// Route unhandled messages (e.g. cursor blink ticks) to the focused textinput.
-	if m.createMode || m.templateMode || m.envEditMode || m.extractMode || m.showThemePicker || m.showFuzzySearch {
+	if m.modalOpen() || m.showThemePicker || m.showFuzzySearch {
 		var cmd tea.Cmd
 		if m.showFuzzySearch {
 			m.fuzzySearchQuery, cmd = m.fuzzySearchQuery.Update(msg)
@@ -514,6 +668,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.envEditSpec, cmd = m.envEditSpec.Update(msg)
 		} else if m.extractMode {
 			m.extractName, cmd = m.extractName.Update(msg)
+		} else if m.sendMode {
+			m.sendInput, cmd = m.sendInput.Update(msg)
 		} else if m.showThemePicker {
 			m.themeQuery, cmd = m.themeQuery.Update(msg)
 		}
@@ -691,19 +847,96 @@ func (m Model) updateAgentsPanelKey(key string) (tea.Model, tea.Cmd) {
 			}
 		}
 		return m.enterTerminalMode()
+	case "y":
+		return m.answerSelectedAgent(true)
+	case "d":
+		return m.answerSelectedAgent(false)
+	case ">":
+		return m.openAgentSendMode()
 	default:
 		return m, nil
 	}
 }
 
+// selectedAgentItem returns the agent under the cursor in the Agents pane.
+func (m Model) selectedAgentItem() (agentItem, bool) {
+	items := m.agentItems()
+	if m.selectedAgent < 0 || m.selectedAgent >= len(items) {
+		return agentItem{}, false
+	}
+	return items[m.selectedAgent], true
+}
+
+// answerSelectedAgent approves or denies the selected agent's permission
+// prompt with its tool's keys, without attaching, and moves the cursor on
+// to the next agent awaiting approval.
+func (m Model) answerSelectedAgent(approve bool) (tea.Model, tea.Cmd) {
+	it, ok := m.selectedAgentItem()
+	if !ok {
+		m.status = "No agent selected."
+		return m, nil
+	}
+	label := it.envName + "/" + it.windowName
+	if it.status != AgentStatusApproval {
+		m.status = label + " is not asking for approval."
+		return m, nil
+	}
+	if !it.shown {
+		m.status = label + " reported an approval that is not on its screen; attach to answer it."
+		return m, nil
+	}
+	keys, deny := m.agents.Replies(it.tool)
+	done := "Approved " + label
+	if !approve {
+		keys, done = deny, "Denied "+label
+	}
+	target := tmux.AttachTarget(m.environments[it.envIdx], it.windowName)
+	m.selectNextApprovalAgent()
+	m.status = "Answering " + label + "..."
+	agent := agentTarget{session: tmux.SessionName(it.envName), window: it.windowName, tool: it.tool}
+	return m, sendAgentKeysCmd(m.agents, agent, target, keys, done)
+}
+
+// selectNextApprovalAgent moves the Agents pane cursor to the next agent
+// after it awaiting approval, if there is one.
+func (m *Model) selectNextApprovalAgent() {
+	items := m.agentItems()
+	for i := 1; i < len(items); i++ {
+		idx := (m.selectedAgent + i) % len(items)
+		if items[idx].status == AgentStatusApproval {
+			m.selectedAgent = idx
+			return
+		}
+	}
+}
+
+// openAgentSendMode opens the send prompt for the selected agent, to reply
+// to it in words without attaching.
+func (m Model) openAgentSendMode() (tea.Model, tea.Cmd) {
+	it, ok := m.selectedAgentItem()
+	if !ok {
+		m.status = "No agent selected."
+		return m, nil
+	}
+	m.sendMode = true
+	m.syncModalInputWidths()
+	m.sendTarget = tmux.AttachTarget(m.environments[it.envIdx], it.windowName)
+	m.sendInput.SetValue("")
+	m.sendInput.Focus()
+	m.status = "Reply — Enter types the text and presses Enter, Esc cancels."
+	return m, textinput.Blink
+}
+
 func (m Model) updateWindowPanelKey(key string) (tea.Model, tea.Cmd) {
 	switch key {
 	case "left", "h", "up", "k":
 		m.moveWindow(-1)
-		return m, m.captureCurrentWindowCmd()
+		read := m.markSelectedWindowRead()
+		return m, tea.Batch(read, m.captureCurrentWindowCmd())
 	case "right", "l", "down", "j":
 		m.moveWindow(1)
-		return m, m.captureCurrentWindowCmd()
+		read := m.markSelectedWindowRead()
+		return m, tea.Batch(read, m.captureCurrentWindowCmd())
 	case "x", "d":
 		m.status = "Switch to [1] Sessions panel for this action"
 		return m, nil
@@ -717,6 +950,8 @@ func (m Model) updateWindowPanelKey(key string) (tea.Model, tea.Cmd) {
 		return m.startMoveWindow(-1)
 	case "L":
 		return m.startMoveWindow(1)
+	case ">":
+		return m.openSendMode()
 	case "shift+enter", "alt+enter":
 		return m.enterTerminalMode()
 	case "enter":
@@ -726,6 +961,63 @@ func (m Model) updateWindowPanelKey(key string) (tea.Model, tea.Cmd) {
 	}
 }
 
+// openSendMode opens the one-line send prompt for the selected window. The
+// window must be live: send-keys has nothing to type into otherwise.
+func (m Model) openSendMode() (tea.Model, tea.Cmd) {
+	env, ok := m.currentEnv()
+	if !ok {
+		m.status = "No environment selected."
+		return m, nil
+	}
+	session := tmux.SessionName(env.Name)
+	if _, running := m.sessions[session]; !running {
+		m.status = "Session is not running: " + session
+		return m, nil
+	}
+	windows := m.currentWindowNames()
+	if m.selectedWindow < 0 || m.selectedWindow >= len(windows) {
+		m.status = "No window selected."
+		return m, nil
+	}
+	if window := windows[m.selectedWindow]; m.isPendingWindow(env, window) {
+		m.status = "Window " + window + " is not created yet; attach to it first."
+		return m, nil
+	}
+	m.sendMode = true
+	m.syncModalInputWidths()
+	m.sendTarget = tmux.AttachTarget(env, windows[m.selectedWindow])
+	m.sendInput.SetValue("")
+	m.sendInput.Focus()
+	m.status = "Send keys — Enter types the text and presses Enter, Esc cancels."
+	return m, textinput.Blink
+}
+
+func (m Model) updateSendMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.sendMode = false
+		m.sendTarget = ""
+		m.sendInput.SetValue("")
+		m.sendInput.Blur()
+		m.status = "Send canceled."
+		return m, nil
+	case "enter":
+		target := m.sendTarget
+		text := m.sendInput.Value()
+		m.sendMode = false
+		m.sendTarget = ""
+		m.sendInput.SetValue("")
+		m.sendInput.Blur()
+		m.status = "Sending..."
+		return m, sendKeysCmd(target, text, true)
+	}
+	var cmd tea.Cmd
+	m.sendInput, cmd = m.sendInput.Update(msg)
+	return m, cmd
+}
+
 func (m Model) updateTemplatesPanelKey(key string) (tea.Model, tea.Cmd) {
 	switch key {
 	case "up", "k":
@@ -828,8 +1120,9 @@ func (m Model) startAttachSelected() (tea.Model, tea.Cmd) {
 	if len(windows) > 0 && m.selectedWindow < len(windows) {
 		wName = windows[m.selectedWindow]
 	}
+	read := m.markSelectedWindowRead()
 	m.status = "Preparing tmux session..."
-	return m, prepareAttachCmd(env, wName)
+	return m, tea.Batch(read, prepareAttachCmd(env, wName))
 }
 
 func (m Model) startMoveWindow(direction int) (tea.Model, tea.Cmd) {
@@ -1019,11 +1312,8 @@ func (m Model) startKillSession() (tea.Model, tea.Cmd) {
 		m.status = "Session is not running: " + session
 		return m, nil
 	}
-	m.confirmMode = true
-	m.confirmKind = "session_kill"
-	m.confirmTarget = session
-	m.status = ""
-	return m, nil
+	m.status = "Checking running processes..."
+	return m, sessionProcessesCmd(session)
 }
 
 func (m Model) startDeleteEnvironment() (tea.Model, tea.Cmd) {
@@ -1049,7 +1339,9 @@ func (m Model) confirmPrompt() string {
 	case "env_delete":
 		return "Delete environment " + m.confirmTarget + "?"
 	case "session_kill":
-		return "Kill tmux session " + m.confirmTarget + "?"
+		return "Stop tmux session " + m.confirmTarget + "?"
+	case "orphan_kill":
+		return fmt.Sprintf("%d processes outlived %s. Terminate them?", len(m.confirmProcesses), m.confirmTarget)
 	case "template_delete":
 		return "Delete template " + m.confirmTarget + "?"
 	case "create_folder":
@@ -1065,6 +1357,7 @@ func (m Model) updateConfirmMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.confirmMode = false
 		m.confirmKind = ""
 		m.confirmTarget = ""
+		m.confirmProcesses = nil
 		if kind == "create_folder" {
 			m.pendingCreateName = ""
 			m.pendingCreateRoot = ""
@@ -1082,13 +1375,22 @@ func (m Model) updateConfirmMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.confirmMode = false
 		m.confirmKind = ""
 		m.confirmTarget = ""
+		m.confirmProcesses = nil
 		switch kind {
 		case "env_delete":
 			m.status = "Deleting environment..."
 			return m, deleteEnvironmentCmd(target)
 		case "session_kill":
-			m.status = "Killing session..."
-			return m, killSessionCmd(target)
+			env, ok := m.envForSession(target)
+			if !ok {
+				m.status = "No environment for session: " + target
+				return m, nil
+			}
+			m.status = fmt.Sprintf("Stopping session (up to %s)...", tmux.StopTimeout(env))
+			return m, killSessionCmd(env)
+		case "orphan_kill":
+			m.status = "Terminating leftover processes..."
+			return m, terminateOrphansCmd(target)
 		case "template_delete":
 			m.status = "Deleting template..."
 			return m, deleteTemplateCmd(target)
@@ -1235,16 +1537,64 @@ func (m Model) currentEnv() (config.Environment, bool) {
 	return m.environments[m.selectedEnv], true
 }
 
+// envForSession finds the configured environment whose tmux session is
+// session.
+func (m Model) envForSession(session string) (config.Environment, bool) {
+	for _, env := range m.environments {
+		if tmux.SessionName(env.Name) == session {
+			return env, true
+		}
+	}
+	return config.Environment{}, false
+}
+
 func (m Model) currentWindowNames() []string {
 	env, ok := m.currentEnv()
 	if !ok {
 		return []string{}
 	}
+	return m.windowNamesForEnv(env)
+}
+
+// enabledEnv is env without the windows whose when condition failed at the
+// last config load: the windows its session has or would be built with.
+func (m Model) enabledEnv(env config.Environment) config.Environment {
+	return tmux.WithoutWindows(env, m.skippedWindows[env.Name])
+}
+
+// isPendingWindow reports whether window is a lazy window of env that has
+// not been created in its session yet. Pending windows are listed dimmed
+// and have nothing to capture or poll until they are attached to.
+func (m Model) isPendingWindow(env config.Environment, window string) bool {
+	if !tmux.IsLazyWindow(env, window) {
+		return false
+	}
+	name := tmux.SafeWindowName(window)
+	for _, w := range m.sessionWindows[tmux.SessionName(env.Name)] {
+		if w == name {
+			return false
+		}
+	}
+	return true
+}
+
+// markWindowCreated records a lazy window created on demand in the cached
+// session windows, so it stops being pending before the next
+// loadSessionsCmd snapshot arrives.
+func (m *Model) markWindowCreated(env config.Environment, window string) {
 	session := tmux.SessionName(env.Name)
-	if windows, ok := m.sessionWindows[session]; ok && len(windows) > 0 {
-		return windows
+	live := m.sessionWindows[session]
+	name := tmux.SafeWindowName(window)
+	if len(live) == 0 || !m.isPendingWindow(env, name) {
+		return
+	}
+	var updated []string
+	for _, w := range tmux.WithLazyWindows(env, live) {
+		if w == name || !m.isPendingWindow(env, w) {
+			updated = append(updated, w)
+		}
 	}
-	return tmux.WindowNames(env)
+	m.sessionWindows[session] = updated
 }
 
 func (m *Model) focusCreateField() {
@@ -1676,6 +2026,8 @@ func (m Model) executeShortcutAction(action string) (tea.Model, tea.Cmd) {
 	case "next-ai":
 		m.jumpToNextCookingSession(1)
 		return m, m.captureCurrentWindowCmd()
+	case "inbox":
+		m.openInbox()
 	case "themes":
 		m.showThemePicker = true
 		m.themePickerCursor = 0
@@ -1716,6 +2068,7 @@ func shortcutsList() []shortcutItem {
 		{"tab", "cycle panels", false, "cycle-panels"},
 		{"ctrl+p", "search", false, "search"},
 		{"n/N", "next/prev ai window", false, "next-ai"},
+		{"i", "agent inbox", false, "inbox"},
 		{"ctrl+t", "theme picker", false, "themes"},
 		{"r", "refresh sessions", false, "refresh"},
 		{"q", "quit", false, "quit"},
@@ -1734,12 +2087,16 @@ func shortcutsList() []shortcutItem {
 		{"j/k", "select prev/next", false, ""},
 		{"enter", "attach to agent", false, ""},
 		{"shift+enter", "enter embedded terminal", false, ""},
+		{"y", "approve permission prompt", false, ""},
+		{"d", "deny permission prompt", false, ""},
+		{">", "reply to agent", false, ""},
 
 		{desc: "Windows", isHeader: true},
 		{"h/l", "select prev/next", false, ""},
 		{"enter", "attach to window", false, ""},
 		{"shift+enter", "enter embedded terminal", false, ""},
 		{"H/L", "reorder window", false, ""},
+		{">", "send keys to window", false, ""},
 		{"ctrl+q", "exit terminal mode", false, ""},
 		{"ctrl+b q", "exit terminal mode (tmux leader)", false, ""},
 
@@ -1890,19 +2247,13 @@ func parseWindowEntry(entry string) (config.WindowTemplate, error) {
 	return config.WindowTemplate{Name: name, Tags: tags}, nil
 }
 
-// HasTag checks if a window template has a specific tag
-// findWindowTemplate finds a config window template by name within an environment.
+// findWindowTemplate is a thin shim around tmux.FindWindowTemplate so the
+// CLI and the TUI resolve window names the same way.
 func findWindowTemplate(env config.Environment, windowName string) (config.WindowTemplate, bool) {
-	for _, w := range env.Windows {
-		// Live tmux windows carry the sanitized name (spaces become
-		// dashes), so match against both forms.
-		if w.Name == windowName || tmux.SafeWindowName(w.Name) == windowName {
-			return w, true
-		}
-	}
-	return config.WindowTemplate{}, false
+	return tmux.FindWindowTemplate(env, windowName)
 }
 
+// HasTag checks if a window template has a specific tag
 func HasTag(w config.WindowTemplate, tag string) bool {
 	for _, t := range w.Tags {
 		if strings.EqualFold(t, tag) {
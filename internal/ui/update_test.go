@@ -6,10 +6,15 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"ide/internal/agentstatus"
 	"ide/internal/config"
+	"ide/internal/notify"
+	"ide/internal/state"
+	"ide/internal/tmux"
 )
 
 func TestClampSelection(t *testing.T) {
@@ -445,3 +450,815 @@ func TestCreateRootTabAdvancesFieldWithoutSuggestions(t *testing.T) {
 		t.Fatalf("tab without suggestions should advance past root field")
 	}
 }
+
+func TestSuperviseWindowsRestartsAfterBackoff(t *testing.T) {
+	m := NewModel()
+	m.environments = []config.Environment{{
+		Name: "svc",
+		Windows: []config.WindowTemplate{
+			{Name: "dev server", Cmd: "npm run dev", Restart: "on-failure", RestartBackoff: "2s"},
+			{Name: "shell"},
+		},
+	}}
+	m.sessionWindows = map[string][]string{"ide-svc": {"dev-server", "shell"}}
+	m.windowExits = map[string]int{"ide-svc:dev-server": 1, "ide-svc:shell": 1}
+
+	now := time.Unix(1000, 0)
+	if cmds := m.superviseWindows(now, now); len(cmds) != 0 {
+		t.Fatalf("restart issued before backoff elapsed: %d cmds", len(cmds))
+	}
+	if _, tracked := m.windowRestarts["ide-svc:shell"]; tracked {
+		t.Error("window without a restart policy should not be supervised")
+	}
+	if cmds := m.superviseWindows(now, now.Add(2*time.Second)); len(cmds) != 1 {
+		t.Fatalf("expected one restart after backoff, got %d", len(cmds))
+	}
+	st := m.windowRestarts["ide-svc:dev-server"]
+	if !st.InFlight || st.LastCode != 1 {
+		t.Errorf("unexpected supervision state: %+v", st)
+	}
+	if got := restartSummary(st, now); !strings.Contains(got, "restarting") || !strings.Contains(got, "code 1") {
+		t.Errorf("restartSummary = %q", got)
+	}
+}
+
+func TestKillConfirmationListsRunningProcesses(t *testing.T) {
+	m := NewModel()
+	m.environments = []config.Environment{{Name: "svc"}}
+	procs := []tmux.PaneProcess{{Window: "db", PID: 42, Command: "postgres"}}
+	updated, _ := m.Update(sessionProcessesMsg{session: "ide-svc", procs: procs})
+	m = updated.(Model)
+	if !m.confirmMode || m.confirmKind != "session_kill" || m.confirmTarget != "ide-svc" {
+		t.Fatalf("confirmation not opened: mode=%v kind=%q target=%q", m.confirmMode, m.confirmKind, m.confirmTarget)
+	}
+	if pane := m.renderConfirmPane(); !strings.Contains(pane, "db: postgres (pid 42)") {
+		t.Errorf("confirmation does not list running process:\n%s", pane)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if m = updated.(Model); m.confirmMode || m.confirmProcesses != nil {
+		t.Error("cancel should close the confirmation and drop the process list")
+	}
+}
+
+func TestOldTmuxWarningSurvivesConfigLoad(t *testing.T) {
+	fake := useFakeTmux(t)
+	m := NewModel()
+	v, err := tmux.ParseVersion("tmux 2.8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.Caps = tmux.CapabilitiesFor(v)
+	updated, _ := m.Update(detectTmuxCmd()())
+	m = updated.(Model)
+	if !strings.Contains(m.status, "too old") {
+		t.Fatalf("status = %q, want a too-old warning", m.status)
+	}
+	updated, _ = m.Update(configLoadedMsg{envs: []config.Environment{{Name: "svc"}}})
+	if m = updated.(Model); !strings.Contains(m.status, "too old") {
+		t.Errorf("config load replaced the tmux warning with %q", m.status)
+	}
+}
+
+func TestSkippedWindowsReadsTheBackendsRecord(t *testing.T) {
+	fake := useFakeTmux(t)
+	env := config.Environment{Name: "svc", Windows: []config.WindowTemplate{
+		{Name: "editor"},
+		{Name: "db", When: config.Condition{Shell: "exit 1"}},
+	}}
+	if got := skippedWindows([]config.Environment{env}); len(got) != 0 {
+		t.Errorf("before the build: skipped = %v, want none (predicates run only at build time)", got)
+	}
+	if err := fake.EnsureSession(env); err != nil {
+		t.Fatal(err)
+	}
+	if got := skippedWindows([]config.Environment{env}); got["svc"]["db"] == "" || len(got["svc"]) != 1 {
+		t.Errorf("after the build: skipped = %v, want db with its reason", got)
+	}
+}
+
+// useFakeTmux swaps the package backend for an in-memory tmux for the
+// duration of the test.
+func useFakeTmux(t *testing.T) *tmux.Fake {
+	t.Helper()
+	fake := tmux.NewFake()
+	prev := backend
+	backend = fake
+	t.Cleanup(func() { backend = prev })
+	return fake
+}
+
+// pressKey feeds one key press through Update.
+func pressKey(t *testing.T, m Model, key string) (Model, tea.Cmd) {
+	t.Helper()
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	return updated.(Model), cmd
+}
+
+// deliver runs cmd and feeds its message back through Update.
+func deliver(t *testing.T, m Model, cmd tea.Cmd) (Model, tea.Cmd, tea.Msg) {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("expected a command, got nil")
+	}
+	msg := cmd()
+	updated, next := m.Update(msg)
+	return updated.(Model), next, msg
+}
+
+func TestAttachFlowCreatesSessionAndSelectsWindow(t *testing.T) {
+	fake := useFakeTmux(t)
+	m := NewModel()
+	m.environments = []config.Environment{{
+		Name:    "svc",
+		Windows: []config.WindowTemplate{{Name: "editor", Cmd: "nvim"}, {Name: "dev server", Cmd: "npm run dev"}},
+	}}
+	m.selectedWindow = 0
+
+	updated, cmd := m.startAttachSelected()
+	m = updated.(Model)
+	m, _, msg := deliver(t, m, cmd)
+	ready, ok := msg.(attachReadyMsg)
+	if !ok || ready.err != nil {
+		t.Fatalf("prepareAttach returned %#v", msg)
+	}
+	if ready.target != "ide-svc:editor" {
+		t.Errorf("attach target = %q, want ide-svc:editor", ready.target)
+	}
+	s := fake.Session("ide-svc")
+	if s == nil {
+		t.Fatal("attach did not create the session")
+	}
+	if s.Active != "editor" {
+		t.Errorf("active window = %q, want editor", s.Active)
+	}
+	if got, _ := fake.ListWindows("ide-svc"); !reflect.DeepEqual(got, []string{"editor", "dev-server"}) {
+		t.Errorf("windows = %v", got)
+	}
+	if !strings.HasPrefix(m.status, "Attached") {
+		t.Errorf("status = %q", m.status)
+	}
+
+	// A second attach reuses the running session.
+	updated, cmd = m.startAttachSelected()
+	deliver(t, updated.(Model), cmd)
+	if s.Builds != 1 || len(fake.Session("ide-svc").Windows) != 2 {
+		t.Errorf("second attach rebuilt the session: %v", fake.Calls)
+	}
+}
+
+func TestRestartFlowRebuildsSession(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	fake := useFakeTmux(t)
+	env := config.Environment{Name: "svc", Windows: []config.WindowTemplate{{Name: "agent", Cmd: "claude"}}}
+	if err := config.Save([]config.Environment{env}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.EnsureSession(env); err != nil {
+		t.Fatal(err)
+	}
+	fake.SetExit("ide-svc", "agent", 1)
+	fake.Survivors["ide-svc"] = []tmux.PaneProcess{{Window: "agent", PID: 7, Command: "node"}}
+
+	m := NewModel()
+	m.environments = []config.Environment{env}
+	m, cmd := pressKey(t, m, "r")
+	if cmd != nil || !strings.Contains(m.status, "Press r again") {
+		t.Fatalf("first r should ask for confirmation, status=%q", m.status)
+	}
+	m, cmd = pressKey(t, m, "r")
+	m, _, msg := deliver(t, m, cmd)
+	if res, ok := msg.(sessionRestartedMsg); !ok || res.err != nil || res.sessionErr != nil {
+		t.Fatalf("restart returned %#v", msg)
+	}
+	want := []string{"ensure ide-svc", "stop ide-svc", "ensure ide-svc"}
+	if !reflect.DeepEqual(fake.Calls, want) {
+		t.Errorf("calls = %v, want %v", fake.Calls, want)
+	}
+	if w := fake.Window("ide-svc", "agent"); w == nil || w.Exit != nil {
+		t.Errorf("restarted window should be running again: %+v", w)
+	}
+	if !strings.Contains(m.status, "Restarted session: ide-svc") || !strings.Contains(m.status, "1 old processes survived") {
+		t.Errorf("status = %q", m.status)
+	}
+}
+
+func TestKillFlowStopsSessionAndTerminatesOrphans(t *testing.T) {
+	fake := useFakeTmux(t)
+	env := config.Environment{Name: "svc", Windows: []config.WindowTemplate{{Name: "db", Cmd: "postgres"}}}
+	if err := fake.EnsureSession(env); err != nil {
+		t.Fatal(err)
+	}
+	fake.Window("ide-svc", "db").Processes = []tmux.PaneProcess{{Window: "db", PID: 42, Command: "postgres"}}
+	fake.Survivors["ide-svc"] = []tmux.PaneProcess{{Window: "db", PID: 43, Command: "postgres"}}
+
+	m := NewModel()
+	m.environments = []config.Environment{env}
+	m, _, _ = deliver(t, m, loadSessionsCmd())
+	if _, running := m.sessions["ide-svc"]; !running {
+		t.Fatalf("sessions not loaded from the backend: %v", m.sessions)
+	}
+
+	m, cmd := pressKey(t, m, "x")
+	m, _, _ = deliver(t, m, cmd)
+	if !m.confirmMode || m.confirmKind != "session_kill" || len(m.confirmProcesses) != 1 {
+		t.Fatalf("kill confirmation not opened with processes: kind=%q procs=%v", m.confirmKind, m.confirmProcesses)
+	}
+	m, cmd = pressKey(t, m, "y")
+	m, _, msg := deliver(t, m, cmd)
+	if killed, ok := msg.(sessionKilledMsg); !ok || killed.err != nil {
+		t.Fatalf("kill returned %#v", msg)
+	}
+	if has, _ := fake.HasSession("ide-svc"); has {
+		t.Error("session still running after kill")
+	}
+	if m.confirmKind != "orphan_kill" || m.confirmTarget != "ide-svc" {
+		t.Fatalf("orphan confirmation not opened: kind=%q", m.confirmKind)
+	}
+	m, cmd = pressKey(t, m, "y")
+	m, _, _ = deliver(t, m, cmd)
+	if got := fake.Calls[len(fake.Calls)-1]; got != "terminate ide-svc" {
+		t.Errorf("last call = %q, want terminate ide-svc", got)
+	}
+	if !strings.HasPrefix(m.status, "Terminated leftover processes") {
+		t.Errorf("status = %q", m.status)
+	}
+}
+
+func TestSendModeTargetsLiveWindowsOnly(t *testing.T) {
+	fake := useFakeTmux(t)
+	env := config.Environment{Name: "svc", Windows: []config.WindowTemplate{
+		{Name: "editor"}, {Name: "db", Cmd: "psql", Lazy: true},
+	}}
+	if err := fake.EnsureSession(env); err != nil {
+		t.Fatal(err)
+	}
+	m := NewModel()
+	m.environments = []config.Environment{env}
+	m, _, _ = deliver(t, m, loadSessionsCmd())
+
+	tests := []struct {
+		name       string
+		window     int
+		wantStatus string
+	}{
+		{"unknown window", 5, "No window selected."},
+		{"lazy window not created yet", 1, "Window db is not created yet; attach to it first."},
+	}
+	for _, tc := range tests {
+		m.selectedWindow = tc.window
+		updated, cmd := m.openSendMode()
+		got := updated.(Model)
+		if got.sendMode || cmd != nil || got.status != tc.wantStatus {
+			t.Errorf("%s: sendMode=%v status=%q, want the prompt refused with %q", tc.name, got.sendMode, got.status, tc.wantStatus)
+		}
+	}
+
+	m.selectedWindow = 0
+	updated, _ := m.openSendMode()
+	m = updated.(Model)
+	if !m.sendMode || m.sendTarget != "ide-svc:editor" {
+		t.Fatalf("sendMode=%v target=%q, want the prompt open on the editor", m.sendMode, m.sendTarget)
+	}
+	m.sendInput.SetValue("make test")
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, _, _ = deliver(t, updated.(Model), cmd)
+	want := []tmux.FakeKeys{
+		{Target: "ide-svc:editor", Literal: true, Keys: []string{"make test"}},
+		{Target: "ide-svc:editor", Keys: []string{"Enter"}},
+	}
+	if !reflect.DeepEqual(fake.Sent, want) || m.status != "Sent to ide-svc:editor" {
+		t.Errorf("sent %+v, status %q", fake.Sent, m.status)
+	}
+
+	// A window that went away between opening the prompt and sending.
+	m.sendMode, m.sendTarget = true, "ide-svc:gone"
+	m.sendInput.SetValue("x")
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, _, _ = deliver(t, updated.(Model), cmd)
+	if !strings.HasPrefix(m.status, "Send failed: send-keys ide-svc:gone") {
+		t.Errorf("status = %q, want the send to fail", m.status)
+	}
+}
+
+func TestAttachCreatesLazyWindowInPlace(t *testing.T) {
+	fake := useFakeTmux(t)
+	env := config.Environment{Name: "svc", Windows: []config.WindowTemplate{
+		{Name: "editor"}, {Name: "db", Cmd: "psql", Lazy: true}, {Name: "logs"},
+	}}
+	if err := fake.EnsureSession(env); err != nil {
+		t.Fatal(err)
+	}
+	m := NewModel()
+	m.environments = []config.Environment{env}
+	m, _, _ = deliver(t, m, loadSessionsCmd())
+
+	windows := m.currentWindowNames()
+	if !reflect.DeepEqual(windows, []string{"editor", "db", "logs"}) {
+		t.Fatalf("windows = %v, want the lazy window listed in place", windows)
+	}
+	if !m.isPendingWindow(env, "db") || m.isPendingWindow(env, "logs") {
+		t.Error("only the uncreated lazy window should be pending")
+	}
+	m.selectedWindow = 1
+	if cmd := m.captureCurrentWindowCmd(); cmd != nil {
+		t.Error("a pending window has nothing to capture")
+	}
+
+	updated, cmd := m.startAttachSelected()
+	m, _, msg := deliver(t, updated.(Model), cmd)
+	if ready, ok := msg.(attachReadyMsg); !ok || ready.err != nil || ready.target != "ide-svc:db" {
+		t.Fatalf("prepareAttach returned %#v", msg)
+	}
+	if got, _ := fake.ListWindows("ide-svc"); !reflect.DeepEqual(got, []string{"editor", "db", "logs"}) {
+		t.Errorf("live windows = %v, want db created between editor and logs", got)
+	}
+	m, _, _ = deliver(t, m, loadSessionsCmd())
+	if m.isPendingWindow(env, "db") {
+		t.Error("db still pending after it was created")
+	}
+}
+
+// svcAgent is the claude window of the "svc" environment the agent tests use.
+var svcAgent = []agentTarget{{session: "ide-svc", window: "agent", command: "claude", tool: "claude"}}
+
+func TestAgentStatusReadsPaneOutput(t *testing.T) {
+	fake := useFakeTmux(t)
+	env := config.Environment{Name: "svc", Windows: []config.WindowTemplate{{Name: "agent", Cmd: "claude"}}}
+	if err := fake.EnsureSession(env); err != nil {
+		t.Fatal(err)
+	}
+	fake.Window("ide-svc", "agent").Output = "Edit src/main.go\n\x1b[1mDo you want to make this edit?\x1b[0m\n❯ 1. Yes\n"
+
+	m := NewModel()
+	m.environments = []config.Environment{env}
+	m, _, _ = deliver(t, m, loadSessionsCmd())
+	m, _, msg := deliver(t, m, checkAgentStatusesCmd(svcAgent))
+	if msg, ok := msg.(agentStatusesMsg); !ok || len(msg.updates) != 1 {
+		t.Fatalf("status check returned %#v", msg)
+	}
+	if got := m.getWindowAgentStatus("ide-svc", "agent"); got != AgentStatusApproval {
+		t.Errorf("status = %s, want %s from the permission prompt", got, AgentStatusApproval)
+	}
+	if got := m.getSessionAgentStatus(env); got != AgentStatusApproval {
+		t.Errorf("session status = %s, want approval to win", got)
+	}
+}
+
+func TestCheckAgentStatusesKeepsTargetOrder(t *testing.T) {
+	fake := useFakeTmux(t)
+	env := config.Environment{Name: "svc", Windows: []config.WindowTemplate{
+		{Name: "a", Cmd: "claude"}, {Name: "b", Cmd: "claude"}, {Name: "c", Cmd: "claude"},
+	}}
+	if err := fake.EnsureSession(env); err != nil {
+		t.Fatal(err)
+	}
+	var targets []agentTarget
+	for _, w := range []string{"c", "gone", "a", "b"} {
+		if w != "gone" {
+			fake.Window("ide-svc", w).Output = "screen of " + w
+		}
+		targets = append(targets, agentTarget{session: "ide-svc", window: w, command: "claude", tool: "claude"})
+	}
+	msg, ok := checkAgentStatusesCmd(targets)().(agentStatusesMsg)
+	if !ok {
+		t.Fatal("status check returned no agentStatusesMsg")
+	}
+	var got []string
+	for _, u := range msg.updates {
+		got = append(got, u.window+"="+u.output)
+	}
+	if want := []string{"c=screen of c", "a=screen of a", "b=screen of b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("updates = %q, want %q", got, want)
+	}
+}
+
+func TestAgentsPaneAnswersApprovalPrompts(t *testing.T) {
+	fake := useFakeTmux(t)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	env := config.Environment{Name: "svc", Windows: []config.WindowTemplate{
+		{Name: "agent", Cmd: "claude"},
+		{Name: "helper", Cmd: "claude"},
+	}}
+	if err := fake.EnsureSession(env); err != nil {
+		t.Fatal(err)
+	}
+	prompt := "Bash command\n  rm -rf build\n  Remove the build directory\nDo you want to proceed?\n❯ 1. Yes\n  2. No\n"
+	fake.Window("ide-svc", "agent").Output = prompt
+	fake.Window("ide-svc", "helper").Output = prompt
+
+	m := NewModel()
+	m.environments = []config.Environment{env}
+	m, _, _ = deliver(t, m, loadSessionsCmd())
+	m, _, _ = deliver(t, m, checkAgentStatusesCmd([]agentTarget{
+		{session: "ide-svc", window: "agent", command: "claude", tool: "claude"},
+		{session: "ide-svc", window: "helper", command: "claude", tool: "claude"},
+	}))
+	items := m.agentItems()
+	if len(items) != 2 {
+		t.Fatalf("agent items = %+v", items)
+	}
+	if want := []string{"rm -rf build", "Remove the build directory", "Do you want to proceed?"}; !reflect.DeepEqual(items[0].prompt, want) {
+		t.Errorf("prompt = %q, want %q", items[0].prompt, want)
+	}
+	if got := agentPaneRows(items); got != 8 {
+		t.Errorf("agent pane rows = %d, want 2 agents and 3 prompt lines each", got)
+	}
+
+	m, _ = pressKey(t, m, "a")
+	m, cmd := pressKey(t, m, "y")
+	if m.selectedAgent != 1 {
+		t.Errorf("selected agent = %d after approving, want the next one awaiting approval", m.selectedAgent)
+	}
+	m, _, _ = deliver(t, m, cmd)
+	if m.status != "Approved svc/agent" {
+		t.Errorf("status = %q", m.status)
+	}
+	m, cmd = pressKey(t, m, "d")
+	runAll(cmd)
+	want := []tmux.FakeKeys{
+		{Target: "ide-svc:agent", Keys: []string{"Enter"}},
+		{Target: "ide-svc:helper", Keys: []string{"Escape"}},
+	}
+	if !reflect.DeepEqual(fake.Sent, want) {
+		t.Errorf("sent %+v, want %+v", fake.Sent, want)
+	}
+
+	fake.Window("ide-svc", "agent").Output = "> \n? for shortcuts\n"
+	m, _, _ = deliver(t, m, checkAgentStatusesCmd(svcAgent))
+	m.selectedAgent = 0
+	m, cmd = pressKey(t, m, "y")
+	if cmd != nil || m.status != "svc/agent is not asking for approval." {
+		t.Errorf("approving an idle agent: status %q, cmd %v", m.status, cmd != nil)
+	}
+
+	// The helper's prompt goes away between the sample and the keys.
+	m.selectedAgent = 1
+	m, cmd = pressKey(t, m, "y")
+	fake.Window("ide-svc", "helper").Output = "> \n? for shortcuts\n"
+	m, _, _ = deliver(t, m, cmd)
+	if m.status != "Send failed: ide-svc:helper no longer shows an approval prompt" {
+		t.Errorf("approving a prompt that went away: status %q", m.status)
+	}
+
+	// An approval only the agent reported is not answered blind.
+	now := time.Now()
+	report := agentstatus.Report{Status: AgentStatusApproval, At: now, Expires: now.Add(time.Minute)}
+	if err := state.WriteJSON(state.AgentReportName("ide-svc:agent"), report); err != nil {
+		t.Fatal(err)
+	}
+	m, _, _ = deliver(t, m, checkAgentStatusesCmd(svcAgent))
+	m.selectedAgent = 0
+	if m.getWindowAgentStatus("ide-svc", "agent") != AgentStatusApproval {
+		t.Fatal("reported approval not picked up")
+	}
+	m, cmd = pressKey(t, m, "y")
+	if cmd != nil || m.status != "svc/agent reported an approval that is not on its screen; attach to answer it." {
+		t.Errorf("approving a reported prompt: status %q, cmd %v", m.status, cmd != nil)
+	}
+	if !reflect.DeepEqual(fake.Sent, want) {
+		t.Errorf("sent %+v, want nothing more than %+v", fake.Sent, want)
+	}
+}
+
+func TestAgentReportOverridesDetection(t *testing.T) {
+	fake := useFakeTmux(t)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	env := config.Environment{Name: "svc", Windows: []config.WindowTemplate{{Name: "agent", Cmd: "claude"}}}
+	if err := fake.EnsureSession(env); err != nil {
+		t.Fatal(err)
+	}
+	fake.Window("ide-svc", "agent").Output = "✻ Cooking… (3s · esc to interrupt)\n"
+	now := time.Now()
+	report := agentstatus.Report{Status: AgentStatusError, Message: "tests failed", At: now, Expires: now.Add(time.Minute)}
+	if err := state.WriteJSON(state.AgentReportName("ide-svc:agent"), report); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewModel()
+	m.environments = []config.Environment{env}
+	m, _, _ = deliver(t, m, loadSessionsCmd())
+	m, _, _ = deliver(t, m, checkAgentStatusesCmd(svcAgent))
+	if got := m.getWindowAgentStatus("ide-svc", "agent"); got != AgentStatusError {
+		t.Errorf("status = %s, want the reported %s over the spinner", got, AgentStatusError)
+	}
+	if items := m.agentItems(); len(items) != 1 || items[0].message != "tests failed" {
+		t.Errorf("agent items = %+v, want the report message", items)
+	}
+
+	// The same window in a recreated session runs a new pane: the old
+	// report is not its.
+	fake.Window("ide-svc", "agent").Info.PID = 4242
+	report.PanePID = 4141
+	if err := state.WriteJSON(state.AgentReportName("ide-svc:agent"), report); err != nil {
+		t.Fatal(err)
+	}
+	m, _, _ = deliver(t, m, checkAgentStatusesCmd(svcAgent))
+	if got := m.getWindowAgentStatus("ide-svc", "agent"); got != AgentStatusCooking {
+		t.Errorf("status = %s, want the screen's cooking over another pane's report", got)
+	}
+}
+
+// runAll runs cmd and every command batched inside it, returning the other
+// messages they produce.
+func runAll(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		var out []tea.Msg
+		for _, c := range batch {
+			out = append(out, runAll(c)...)
+		}
+		return out
+	}
+	if msg == nil {
+		return nil
+	}
+	return []tea.Msg{msg}
+}
+
+func TestAgentNotifiesWhenItStopsCooking(t *testing.T) {
+	for _, mute := range []bool{false, true} {
+		fake := useFakeTmux(t)
+		t.Setenv("XDG_STATE_HOME", t.TempDir())
+		env := config.Environment{Name: "svc", Mute: mute, Windows: []config.WindowTemplate{{Name: "agent", Cmd: "claude"}}}
+		if err := fake.EnsureSession(env); err != nil {
+			t.Fatal(err)
+		}
+		rec := &notify.Recorder{}
+		m := NewModel()
+		m.environments = []config.Environment{env}
+		m.notifier = rec
+		m, _, _ = deliver(t, m, loadSessionsCmd())
+
+		fake.Window("ide-svc", "agent").Output = "✻ Cooking… (3s · esc to interrupt)\n"
+		m, cmd, _ := deliver(t, m, checkAgentStatusesCmd(svcAgent))
+		runAll(cmd)
+		if got := rec.Events(); len(got) != 0 {
+			t.Errorf("mute=%v: first sample notified %+v, want nothing", mute, got)
+		}
+		fake.Window("ide-svc", "agent").Output = "> \n? for shortcuts\n"
+		_, cmd, _ = deliver(t, m, checkAgentStatusesCmd(svcAgent))
+		runAll(cmd)
+		events := rec.Events()
+		if mute {
+			if len(events) != 0 {
+				t.Errorf("muted environment notified %+v", events)
+			}
+			continue
+		}
+		if len(events) != 1 {
+			t.Fatalf("events = %+v, want one", events)
+		}
+		if ev := events[0]; ev.Env != "svc" || ev.Window != "agent" || ev.From != AgentStatusCooking || ev.To != AgentStatusAwaitingInput {
+			t.Errorf("event = %+v", ev)
+		}
+	}
+}
+
+func TestBellReachesTheTerminalThroughUpdate(t *testing.T) {
+	fake := useFakeTmux(t)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	var term strings.Builder
+	prev := terminalOut
+	terminalOut = &term
+	t.Cleanup(func() { terminalOut = prev })
+	env := config.Environment{Name: "svc", Windows: []config.WindowTemplate{{Name: "agent", Cmd: "claude"}}}
+	if err := fake.EnsureSession(env); err != nil {
+		t.Fatal(err)
+	}
+	m := NewModel()
+	m.environments = []config.Environment{env}
+	n, err := newNotifier(config.Notify{Via: []string{"bell"}}, m.escapes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.notifier = n
+	m, _, _ = deliver(t, m, loadSessionsCmd())
+
+	fake.Window("ide-svc", "agent").Output = "✻ Cooking… (3s · esc to interrupt)\n"
+	m, _, _ = deliver(t, m, checkAgentStatusesCmd(svcAgent))
+	fake.Window("ide-svc", "agent").Output = "> \n? for shortcuts\n"
+	_, cmd, _ := deliver(t, m, checkAgentStatusesCmd(svcAgent))
+	var escapes []tea.Msg
+	for _, msg := range runAll(cmd) {
+		if _, ok := msg.(terminalEscapeMsg); ok {
+			escapes = append(escapes, msg)
+		}
+	}
+	if term.Len() != 0 {
+		t.Fatalf("the notifier wrote %q to the terminal itself", term.String())
+	}
+	if len(escapes) != 1 {
+		t.Fatalf("escape messages = %+v, want one", escapes)
+	}
+	m.Update(escapes[0])
+	if term.String() != "\a" {
+		t.Errorf("terminal got %q, want the bell", term.String())
+	}
+}
+
+func TestAgentInboxFilesFinishedTurns(t *testing.T) {
+	fake := useFakeTmux(t)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	env := config.Environment{Name: "svc", Windows: []config.WindowTemplate{
+		{Name: "editor", Cmd: "vim"},
+		{Name: "agent", Cmd: "claude"},
+	}}
+	if err := fake.EnsureSession(env); err != nil {
+		t.Fatal(err)
+	}
+	m := NewModel()
+	m.environments = []config.Environment{env}
+	m, _, _ = deliver(t, m, loadSessionsCmd())
+
+	for _, output := range []string{
+		"✻ Cooking… (3s · esc to interrupt)\n",
+		"⏺ Fixed the flaky test.\n\n  - wait for the server before dialing\n\n│ > │\n  ? for shortcuts\n",
+	} {
+		fake.Window("ide-svc", "agent").Output = output
+		var cmd tea.Cmd
+		m, cmd, _ = deliver(t, m, checkAgentStatusesCmd(svcAgent))
+		runAll(cmd)
+	}
+	want := []string{"⏺ Fixed the flaky test.", "  - wait for the server before dialing"}
+	if len(m.inbox) != 1 || m.inbox[0].Key() != "ide-svc:agent" || !reflect.DeepEqual(m.inbox[0].Lines, want) {
+		t.Fatalf("inbox = %+v, want the agent's summary", m.inbox)
+	}
+
+	// A restart finds the entry again.
+	loaded, _, _ := deliver(t, NewModel(), loadInboxCmd())
+	if len(loaded.inbox) != 1 || !reflect.DeepEqual(loaded.inbox[0].Lines, want) {
+		t.Errorf("reloaded inbox = %+v", loaded.inbox)
+	}
+
+	m, _ = pressKey(t, m, "i")
+	if !m.showInbox || !strings.Contains(m.renderInboxPane(80, 20), "Fixed the flaky test.") {
+		t.Fatalf("inbox overlay not showing the summary: %q", m.renderInboxPane(80, 20))
+	}
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	runAll(cmd)
+	if m.showInbox || m.focusPane != focusPaneWindows || m.currentWindowNames()[m.selectedWindow] != "agent" {
+		t.Errorf("enter: showInbox=%v focus=%d window=%d, want the agent window selected", m.showInbox, m.focusPane, m.selectedWindow)
+	}
+	if len(m.inbox) != 0 {
+		t.Errorf("inbox = %+v after viewing the agent, want it read", m.inbox)
+	}
+	if reloaded, _, _ := deliver(t, NewModel(), loadInboxCmd()); len(reloaded.inbox) != 0 {
+		t.Errorf("read entries still saved: %+v", reloaded.inbox)
+	}
+}
+
+func TestInboxEntriesStayUntilTheWindowIsVisited(t *testing.T) {
+	fake := useFakeTmux(t)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	svc := config.Environment{Name: "svc", Windows: []config.WindowTemplate{{Name: "editor", Cmd: "vim"}, {Name: "agent", Cmd: "claude"}}}
+	web := config.Environment{Name: "web", Windows: []config.WindowTemplate{{Name: "agent", Cmd: "claude"}}}
+	for _, env := range []config.Environment{svc, web} {
+		if err := fake.EnsureSession(env); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := NewModel()
+	m.environments = []config.Environment{svc, web}
+	m, _, _ = deliver(t, m, loadSessionsCmd())
+	at := time.Now()
+	m.addInboxEntry("ide-svc", "agent", "claude", "fixed the test\n", at)
+	m.addInboxEntry("ide-web", "agent", "claude", "bumped the deps\n", at.Add(time.Second))
+	keys := func() []string {
+		var out []string
+		for _, e := range m.inboxNewestFirst() {
+			out = append(out, e.Key())
+		}
+		return out
+	}
+
+	// The preview of a selected window is not a visit.
+	m, _, _ = deliver(t, m, capturePaneCmd("ide-svc", "agent"))
+	if got := keys(); len(got) != 2 {
+		t.Fatalf("inbox = %q after a preview, want both entries unread", got)
+	}
+
+	// An agent finishing while the inbox is open does not move the cursor.
+	m, _ = pressKey(t, m, "i")
+	m, _ = pressKey(t, m, "j")
+	m.addInboxEntry("ide-svc", "editor", "claude", "saved\n", at.Add(2*time.Second))
+	if got := m.selectedInboxKey(); got != "ide-svc:agent" {
+		t.Errorf("cursor on %q after a new entry, want it still on ide-svc:agent", got)
+	}
+	m, _ = pressKey(t, m, "esc")
+
+	// Going to the window in the Windows pane is.
+	m.focusPane, m.selectedEnv, m.selectedWindow = focusPaneWindows, 0, 0
+	m, cmd := pressKey(t, m, "j")
+	runAll(cmd)
+	if got, want := keys(), []string{"ide-svc:editor", "ide-web:agent"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("inbox = %q after selecting svc/agent, want %q", got, want)
+	}
+
+	// A session that is gone takes its entries with it.
+	if err := fake.KillSession("ide-web"); err != nil {
+		t.Fatal(err)
+	}
+	m, cmd, _ = deliver(t, m, loadSessionsCmd())
+	runAll(cmd)
+	if got, want := keys(), []string{"ide-svc:editor"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("inbox = %q after ide-web went away, want %q", got, want)
+	}
+}
+
+func TestAgentTimelineRecordsTransitions(t *testing.T) {
+	fake := useFakeTmux(t)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	env := config.Environment{Name: "svc", Windows: []config.WindowTemplate{{Name: "agent", Cmd: "claude"}}}
+	if err := fake.EnsureSession(env); err != nil {
+		t.Fatal(err)
+	}
+	m := NewModel()
+	m.environments = []config.Environment{env}
+	m, _, _ = deliver(t, m, loadSessionsCmd())
+
+	for _, output := range []string{
+		"✻ Cooking… (3s · esc to interrupt)\n",
+		"✻ Cooking… (4s · esc to interrupt)\n",
+		"> \n? for shortcuts\n",
+	} {
+		fake.Window("ide-svc", "agent").Output = output
+		var cmd tea.Cmd
+		m, cmd, _ = deliver(t, m, checkAgentStatusesCmd(svcAgent))
+		runAll(cmd)
+	}
+
+	recorded, err := state.ReadJSONLines[agentstatus.Transition](state.AgentTimelineName("ide-svc:agent"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []AgentStatus
+	for _, tr := range recorded {
+		got = append(got, tr.To)
+	}
+	if want := []AgentStatus{AgentStatusCooking, AgentStatusAwaitingInput}; !reflect.DeepEqual(got, want) {
+		t.Errorf("recorded %v, want %v", got, want)
+	}
+
+	// A restarted TUI picks the history up again.
+	m2 := NewModel()
+	m2, _, _ = deliver(t, m2, loadAgentTimelinesCmd())
+	if tl := m2.agentTimelines["ide-svc:agent"]; len(tl) != 2 {
+		t.Errorf("loaded timeline = %+v, want both transitions", tl)
+	}
+	items := m.agentItems()
+	if len(items) != 1 || !strings.Contains(agentWaitBadge(items[0].status, items[0].timeline, time.Now().Add(12*time.Minute)), "waiting 12m") {
+		t.Errorf("agent items = %+v, want a waiting badge", items)
+	}
+}
+
+func TestAgentTimelineClosesWhenTheWindowGoes(t *testing.T) {
+	fake := useFakeTmux(t)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	env := config.Environment{Name: "svc", Windows: []config.WindowTemplate{{Name: "agent", Cmd: "claude"}}}
+	if err := fake.EnsureSession(env); err != nil {
+		t.Fatal(err)
+	}
+	fake.Window("ide-svc", "agent").Output = "✻ Cooking… (3s · esc to interrupt)\n"
+	m := NewModel()
+	m.environments = []config.Environment{env}
+	m, _, _ = deliver(t, m, loadSessionsCmd())
+	cook := func() time.Time {
+		t.Helper()
+		var cmd tea.Cmd
+		m, cmd, _ = deliver(t, m, checkAgentStatusesCmd(svcAgent))
+		runAll(cmd)
+		return m.windowProcessInfo["ide-svc:agent"].Current.Timestamp
+	}
+	closedAt := func(n int, at time.Time) {
+		t.Helper()
+		recorded, err := state.ReadJSONLines[agentstatus.Transition](state.AgentTimelineName("ide-svc:agent"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(recorded) != n || recorded[n-1].To != AgentStatusIdle || !recorded[n-1].At.Equal(at) {
+			t.Fatalf("recorded %+v, want the cooking closed at the last sample %v", recorded, at)
+		}
+	}
+
+	// ide exits: the cooking stops counting at the last sample.
+	last := cook()
+	m.CloseAgentTimelines()
+	closedAt(2, last)
+
+	// The session is killed: the same, once ide notices.
+	last = cook()
+	if err := fake.KillSession("ide-svc"); err != nil {
+		t.Fatal(err)
+	}
+	var cmd tea.Cmd
+	m, cmd, _ = deliver(t, m, loadSessionsCmd())
+	runAll(cmd)
+	closedAt(4, last)
+	m, cmd, _ = deliver(t, m, loadSessionsCmd())
+	runAll(cmd)
+	closedAt(4, last)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
@@ -36,7 +37,7 @@ func (m Model) View() string {
 	theme := m.currentTheme()
 	gapBG := lipgloss.Color(theme.AppBG)
 
-	sessionsHeight, agentsHeight, templatesHeight := splitLeftPaneHeights(leftContentTotal, len(m.agentItems()), len(m.templates))
+	sessionsHeight, agentsHeight, templatesHeight := splitLeftPaneHeights(leftContentTotal, agentPaneRows(m.agentItems()), len(m.templates))
 	sessionsPane := m.renderEnvironmentPane(leftWidth, sessionsHeight)
 	agentsPane := m.renderAgentsPane(leftWidth, agentsHeight)
 	templatesPane := m.renderTemplatesPane(leftWidth, templatesHeight)
@@ -45,12 +46,14 @@ func (m Model) View() string {
 	rightPane := m.renderDetailsPane(rightWidth, rightPaneHeight)
 	horizontalGap := lipgloss.NewStyle().Width(1).Height(bodyHeight).Background(gapBG).Render("")
 	body := lipgloss.JoinHorizontal(lipgloss.Top, leftPane, horizontalGap, rightPane)
-	if m.createMode || m.templateMode || m.envEditMode || m.extractMode {
+	if m.modalOpen() {
 		bodyWidth := lipgloss.Width(body)
 		bodyHeight := lipgloss.Height(body)
 		popupWidth, popupHeight := modalPopupDimensions(bodyWidth, bodyHeight, rightWidth, rightPaneHeight)
 		var popup string
 		switch {
+		case m.sendMode:
+			popup = m.renderSendPane(popupWidth, popupHeight)
 		case m.envEditMode:
 			popup = m.renderEnvEditPane(popupWidth, popupHeight)
 		case m.extractMode:
@@ -80,6 +83,13 @@ func (m Model) View() string {
 		}
 		body = overlayCentered(body, popup)
 	}
+	if m.showInbox {
+		bw := lipgloss.Width(body)
+		bh := lipgloss.Height(body)
+		popupWidth := clampPopupWidth(bw-popupSearchMargin, bw, popupMaxWidthSearch)
+		popupHeight := clampPopupHeight(bh-popupVerticalMarginTight, bh, popupMaxHeightSearch)
+		body = overlayCentered(body, m.renderInboxPane(popupWidth, popupHeight))
+	}
 	if m.showFuzzySearch {
 		bw := lipgloss.Width(body)
 		bh := lipgloss.Height(body)
@@ -229,6 +239,7 @@ func (m *Model) syncModalInputWidths() {
 	m.templateSpec.Width = m.modalInputWidth(m.templateSpec.Prompt)
 	m.envEditSpec.Width = m.modalInputWidth(m.envEditSpec.Prompt)
 	m.extractName.Width = m.modalInputWidth(m.extractName.Prompt)
+	m.sendInput.Width = m.modalInputWidth(m.sendInput.Prompt)
 }
 
 func padLineToWidth(line string, width int) string {
@@ -317,6 +328,13 @@ func (m Model) contextShortcutHints() string {
 			m.shortcutHint("esc", "close"),
 		}, sep)
 	}
+	if m.showInbox {
+		return strings.Join([]string{
+			m.shortcutHint("↑↓", "navigate"),
+			m.shortcutHint("enter", "jump"),
+			m.shortcutHint("esc", "close"),
+		}, sep)
+	}
 	if m.showShortcuts {
 		return strings.Join([]string{
 			m.shortcutHint("?", "close"),
@@ -329,6 +347,12 @@ func (m Model) contextShortcutHints() string {
 			m.shortcutHint("n", "cancel"),
 		}, sep)
 	}
+	if m.sendMode {
+		return strings.Join([]string{
+			m.shortcutHint("enter", "send"),
+			m.shortcutHint("esc", "cancel"),
+		}, sep)
+	}
 	if m.createMode || m.templateMode || m.envEditMode || m.extractMode {
 		return strings.Join([]string{
 			m.shortcutHint("enter", "save"),
@@ -356,11 +380,14 @@ func (m Model) contextShortcutHints() string {
 		hints = append(hints,
 			m.shortcutHint("enter", "attach"),
 			m.shortcutHint("shift+enter", "terminal"),
+			m.shortcutHint("y/d", "approve/deny"),
+			m.shortcutHint(">", "reply"),
 		)
 	case focusPaneWindows:
 		hints = append(hints,
 			m.shortcutHint("enter", "attach"),
 			m.shortcutHint("shift+enter", "terminal"),
+			m.shortcutHint(">", "send"),
 			m.shortcutHint("H/L", "reorder"),
 		)
 	case focusPaneTemplates:
@@ -370,6 +397,9 @@ func (m Model) contextShortcutHints() string {
 			m.shortcutHint("d", "delete"),
 		)
 	}
+	if n := len(m.inbox); n > 0 {
+		hints = append(hints, m.shortcutHint("i", fmt.Sprintf("inbox (%d)", n)))
+	}
 	hints = append(hints,
 		m.shortcutHint("ctrl+p", "search"),
 		m.shortcutHint("?", "help"),
@@ -590,11 +620,16 @@ func (m Model) renderEnvironmentPane(width, height int) string {
 			sessionStatus = m.getSessionAgentStatus(env)
 		}
 		indicator := ""
-		switch sessionStatus {
-		case AgentStatusCooking:
-			indicator = " ●"
-		case AgentStatusAwaitingInput:
-			indicator = " ◆"
+		if glyph := agentStatusGlyph(sessionStatus); glyph != "" {
+			indicator = " " + glyph
+		}
+
+		exits, exitCode := "", 0
+		if running {
+			exits, exitCode = m.sessionExitSummary(env)
+		}
+		if exits != "" {
+			indicator += " " + exits
 		}
 
 		content := fmt.Sprintf("%s %-20s [%s]%s", numPrefix(idx), env.Name, state, indicator)
@@ -602,7 +637,16 @@ func (m Model) renderEnvironmentPane(width, height int) string {
 		selectedStyle := selectedLineStyle
 		var defaultStyle *lipgloss.Style
 
-		if sessionStatus != AgentStatusIdle {
+		if sessionStatus == AgentStatusIdle && exits != "" {
+			selectedStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color(exitedColor(exitCode, theme))).
+				Background(lipgloss.Color(theme.SelectionBG)).
+				Bold(true)
+			ds := lipgloss.NewStyle().
+				Foreground(lipgloss.Color(exitedColor(exitCode, theme))).
+				Background(lipgloss.Color(theme.PaneBG))
+			defaultStyle = &ds
+		} else if sessionStatus != AgentStatusIdle {
 			statusColor := m.getWindowStatusColor(sessionStatus)
 			selectedStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color(statusColor)).
@@ -644,7 +688,7 @@ func (m Model) renderTemplatesPane(width, height int) string {
 }
 
 func (m Model) renderDetailsPane(width, height int) string {
-	focused := !m.createMode && !m.templateMode && !m.envEditMode && !m.extractMode && m.focusPane == focusPaneWindows
+	focused := !m.modalOpen() && m.focusPane == focusPaneWindows
 	theme := m.currentTheme()
 	env, ok := m.currentEnv()
 
@@ -715,6 +759,20 @@ func (m Model) renderDetailsPane(width, height int) string {
 	if hasCmd {
 		topRows = append(topRows, infoLine("Cmd:", selectedWindowCmd))
 	}
+	if code, exited := m.windowExitCode(session, selectedWindowName); exited {
+		exitStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color(exitedColor(code, theme))).
+			Background(lipgloss.Color(theme.PaneBG)).
+			Bold(true)
+		topRows = append(topRows, renderStyledPaneLine(
+			lipgloss.NewStyle().Background(lipgloss.Color(theme.PaneBG)),
+			labelStyle.Render("Status: ")+exitStyle.Render(tmux.ExitLabel(code)),
+			contentWidth,
+		))
+	}
+	if summary := restartSummary(m.windowRestarts[windowKey(session, selectedWindowName)], time.Now()); summary != "" {
+		topRows = append(topRows, infoLine("Restarts:", summary))
+	}
 
 	topVisualHeight := len(topRows)
 	contentHeight := height - 1
@@ -723,7 +781,8 @@ func (m Model) renderDetailsPane(width, height int) string {
 		previewHeight = 0
 	}
 
-	previewRows := m.renderPreviewRows(session, selectedWindowName, usingLiveWindows, contentWidth, previewHeight, theme)
+	pending := usingLiveWindows && m.isPendingWindow(env, selectedWindowName)
+	previewRows := m.renderPreviewRows(session, selectedWindowName, usingLiveWindows, pending, contentWidth, previewHeight, theme)
 
 	return assembleWindowsPane(width, height, title, topRows, previewRows, focused)
 }
@@ -836,12 +895,49 @@ func (m Model) renderTerminalPane(width, height int, title string, env config.En
 // renderWindowTabs builds the tab bar string for window tabs.
 func (m Model) renderWindowTabs(windows []string, session string, contentWidth int, theme uiTheme) string {
 	tabs := make([]string, 0, len(windows))
+	env, hasEnv := m.envForSession(session)
 	for i, w := range windows {
 		status := m.getWindowAgentStatus(session, w)
 		label := m.formatWindowLabel(w, status)
 		if i < 9 {
 			label = fmt.Sprintf("%d %s", i+1, label)
 		}
+		if hasEnv && m.isPendingWindow(env, w) {
+			// Lazy window not created yet: dimmed until it is attached to.
+			style := lipgloss.NewStyle().
+				Foreground(lipgloss.Color(theme.Inactive)).
+				Background(lipgloss.Color(theme.PaneBG)).
+				Padding(0, 1)
+			if i == m.selectedWindow {
+				style = style.
+					Foreground(lipgloss.Color(theme.PaneBG)).
+					Background(lipgloss.Color(theme.Inactive)).
+					Bold(true)
+			}
+			tabs = append(tabs, style.Render(label))
+			continue
+		}
+		code, exited := m.windowExitCode(session, w)
+		if exited && status == AgentStatusIdle {
+			color := exitedColor(code, theme)
+			if code == 0 {
+				label += " ✓"
+			} else {
+				label += " ✗"
+			}
+			style := lipgloss.NewStyle().
+				Foreground(lipgloss.Color(color)).
+				Background(lipgloss.Color(theme.PaneBG)).
+				Padding(0, 1)
+			if i == m.selectedWindow {
+				style = style.
+					Foreground(lipgloss.Color(theme.PaneBG)).
+					Background(lipgloss.Color(color)).
+					Bold(true)
+			}
+			tabs = append(tabs, style.Render(label))
+			continue
+		}
 
 		if i == m.selectedWindow {
 			if status != AgentStatusIdle {
@@ -962,7 +1058,7 @@ func (m Model) renderWindowTabs(windows []string, session string, contentWidth i
 // renderPreviewRows renders the vt-rendered tmux pane capture as display rows.
 // Cropping is bottom-aligned vertically and left-aligned horizontally; unused
 // cells emit no BG escape so the host terminal background shows through.
-func (m Model) renderPreviewRows(session, windowName string, usingLiveWindows bool, contentWidth, previewHeight int, theme uiTheme) []string {
+func (m Model) renderPreviewRows(session, windowName string, usingLiveWindows, pending bool, contentWidth, previewHeight int, theme uiTheme) []string {
 	previewRows := make([]string, 0, previewHeight)
 	hasPreview := usingLiveWindows &&
 		m.previewSession == session &&
@@ -984,6 +1080,9 @@ func (m Model) renderPreviewRows(session, windowName string, usingLiveWindows bo
 	} else if !usingLiveWindows && previewHeight > 0 {
 		placeholder := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Inactive)).Render("No active session — Enter to start")
 		previewRows = append(previewRows, placeholder)
+	} else if pending && previewHeight > 0 {
+		placeholder := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Inactive)).Render("Lazy window, not started yet — Enter to start")
+		previewRows = append(previewRows, placeholder)
 	}
 
 	for len(previewRows) < previewHeight {
@@ -1070,17 +1169,50 @@ func (m Model) renderExtractPane(width, height int) string {
 	return renderModalWithBorderTitle(width, height, "Save as Template", strings.Join(rows, "\n"))
 }
 
+func (m Model) renderSendPane(width, height int) string {
+	// sendInput width is set on the persisted input by syncModalInputWidths.
+	rows := []string{
+		"Send to: " + m.sendTarget,
+		m.sendInput.View(),
+		"",
+		"Types the text into the window, then presses Enter.",
+		"Enter sends · Esc cancels",
+	}
+	return renderModalWithBorderTitle(width, height, "Send Keys", strings.Join(rows, "\n"))
+}
+
+// maxConfirmProcesses caps the process list in the kill confirmation.
+const maxConfirmProcesses = 8
+
 func (m Model) renderConfirmPane() string {
-	prompt := m.confirmPrompt()
-	hint := "[y] confirm   [n] cancel"
-	innerW := lipgloss.Width(prompt)
-	if hw := lipgloss.Width(hint); hw > innerW {
-		innerW = hw
+	lines := []string{m.confirmPrompt()}
+	heading := ""
+	switch m.confirmKind {
+	case "session_kill":
+		heading = "Still running (C-c first, killed after the stop timeout):"
+	case "orphan_kill":
+		heading = "Still alive after the session was killed (SIGTERM, then SIGKILL):"
+	}
+	if heading != "" && len(m.confirmProcesses) > 0 {
+		lines = append(lines, "", heading)
+		for i, p := range m.confirmProcesses {
+			if i == maxConfirmProcesses {
+				lines = append(lines, fmt.Sprintf("  …and %d more", len(m.confirmProcesses)-i))
+				break
+			}
+			lines = append(lines, fmt.Sprintf("  %s: %s (pid %d)", p.Window, p.Command, p.PID))
+		}
+	}
+	lines = append(lines, "", "[y] confirm   [n] cancel")
+	innerW := 0
+	for _, line := range lines {
+		if w := lipgloss.Width(line); w > innerW {
+			innerW = w
+		}
 	}
-	body := strings.Join([]string{prompt, "", hint}, "\n")
 	width := innerW + 6
-	height := 5
-	return renderModalWithBorderTitle(width, height, "Confirm", body)
+	height := len(lines) + 2
+	return renderModalWithBorderTitle(width, height, "Confirm", strings.Join(lines, "\n"))
 }
 
 func (m Model) renderThemePickerPane(width, height int) string {
@@ -1146,6 +1278,9 @@ func (m Model) renderFuzzySearchPane(width, height int) string {
 	tagStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color(theme.Accent)).
 		Background(lipgloss.Color(theme.PaneBG))
+	lazyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Inactive)).
+		Background(lipgloss.Color(theme.PaneBG))
 
 	rows := []string{
 		m.fuzzySearchQuery.View(),
@@ -1193,11 +1328,8 @@ func (m Model) renderFuzzySearchPane(width, height int) string {
 					runIndicator = "●"
 				}
 				statusStr := ""
-				switch item.Status {
-				case AgentStatusCooking:
-					statusStr = "  ●"
-				case AgentStatusAwaitingInput:
-					statusStr = "  ◆"
+				if glyph := agentStatusGlyph(item.Status); glyph != "" {
+					statusStr = "  " + glyph
 				}
 
 				headerText := fmt.Sprintf("  %s %s%s", runIndicator, item.EnvName, statusStr)
@@ -1216,11 +1348,8 @@ func (m Model) renderFuzzySearchPane(width, height int) string {
 
 			// Window row (indented under session)
 			statusStr := ""
-			switch item.Status {
-			case AgentStatusCooking:
-				statusStr = "  ●"
-			case AgentStatusAwaitingInput:
-				statusStr = "  ◆"
+			if glyph := agentStatusGlyph(item.Status); glyph != "" {
+				statusStr = "  " + glyph
 			}
 
 			// Tags rendered inline
@@ -1260,6 +1389,12 @@ func (m Model) renderFuzzySearchPane(width, height int) string {
 				}
 				plainText += statusStr
 				rows = append(rows, stStyle.Render(fitLineToWidth("      "+plainText, contentWidth)))
+			} else if item.Lazy {
+				plainText := item.WindowName
+				for _, t := range item.Tags {
+					plainText += " [" + t + "]"
+				}
+				rows = append(rows, lazyStyle.Render(fitLineToWidth("      "+plainText, contentWidth)))
 			} else {
 				rows = append(rows, mutedStyle.Render("      "+windowText))
 			}
@@ -1297,10 +1432,25 @@ func (m Model) renderShortcutsPane(width, height int) string {
 		Foreground(lipgloss.Color(m.getWindowStatusColor(AgentStatusAwaitingInput))).
 		Bold(true).
 		Render("◆")
+	approvalGlyph := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.getWindowStatusColor(AgentStatusApproval))).
+		Bold(true).
+		Render("▲")
+	errorGlyph := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.getWindowStatusColor(AgentStatusError))).
+		Bold(true).
+		Render("✖")
+	stalledGlyph := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.getWindowStatusColor(AgentStatusStalled))).
+		Bold(true).
+		Render("◌")
 	legend := []string{
 		headingStyle.Render("  Legend"),
 		"    " + cookingGlyph + descStyle.Render("  Cooking — agent is working"),
 		"    " + awaitingGlyph + descStyle.Render("  Awaiting — agent needs input"),
+		"    " + approvalGlyph + descStyle.Render("  Approval — agent is asking permission"),
+		"    " + errorGlyph + descStyle.Render("  Error — agent reported an error"),
+		"    " + stalledGlyph + descStyle.Render("  Stalled — cooking, but the screen stopped changing"),
 		"",
 	}
 
@@ -83,3 +83,17 @@ func TestEnvEditSpecScrollsToEnd(t *testing.T) {
 		t.Errorf("expected the end of a long spec to be visible after CursorEnd, got:\n%s", view)
 	}
 }
+
+func TestExitedColorSeparatesCleanExitsFromFailures(t *testing.T) {
+	m := NewModel()
+	theme := m.currentTheme()
+	if got := exitedColor(0, theme); got != theme.Muted {
+		t.Errorf("exitedColor(0) = %q, want the muted %q", got, theme.Muted)
+	}
+	approval := m.getWindowStatusColor(AgentStatusApproval)
+	for _, code := range []int{1, 130} {
+		if got := exitedColor(code, theme); got == theme.Muted || got == approval {
+			t.Errorf("exitedColor(%d) = %q, want a failure color distinct from muted and approval", code, got)
+		}
+	}
+}
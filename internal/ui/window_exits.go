@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"fmt"
+
+	"ide/internal/config"
+	"ide/internal/tmux"
+)
+
+// failedColor marks windows whose startup command exited with a non-zero
+// status. It is a deeper red than the approval one, which asks for action.
+const failedColor = "#e11d48"
+
+// exitedColor is the color of a window whose startup command exited with
+// code: failedColor, or the theme's muted color for a clean exit.
+func exitedColor(code int, theme uiTheme) string {
+	if code == 0 {
+		return theme.Muted
+	}
+	return failedColor
+}
+
+// windowExitCode reports the exit code of a window's startup command, if it
+// has exited since the window was created.
+func (m Model) windowExitCode(session, window string) (int, bool) {
+	code, ok := m.windowExits[windowKey(session, window)]
+	return code, ok
+}
+
+// sessionExitSummary describes exited commands in env's running session for
+// the Sessions list: the code when one window exited, a count otherwise.
+// worst is the first non-zero code, or 0 when every command succeeded.
+func (m Model) sessionExitSummary(env config.Environment) (summary string, worst int) {
+	session := tmux.SessionName(env.Name)
+	var codes []int
+	for _, w := range m.sessionWindows[session] {
+		if code, ok := m.windowExitCode(session, w); ok {
+			codes = append(codes, code)
+			if worst == 0 {
+				worst = code
+			}
+		}
+	}
+	switch len(codes) {
+	case 0:
+		return "", 0
+	case 1:
+		return tmux.ExitLabel(codes[0]), worst
+	default:
+		return fmt.Sprintf("%d exited", len(codes)), worst
+	}
+}
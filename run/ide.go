@@ -21,7 +21,11 @@ func Main() int {
 	}
 
 	p := tea.NewProgram(ui.NewModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	final, err := p.Run()
+	if m, ok := final.(ui.Model); ok {
+		m.CloseAgentTimelines()
+	}
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return 1
 	}